@@ -29,10 +29,18 @@ import (
 )
 
 type Rule struct {
-	Name       string     `yaml:"name"`
-	Predicates Predicates `yaml:"if"`
-	Options    Options    `yaml:"options"`
-	Requires   Requires   `yaml:"requires"`
+	Name        string     `yaml:"name"`
+	Description string     `yaml:"description"`
+	Predicates  Predicates `yaml:"if"`
+	Options     Options    `yaml:"options"`
+	Requires    Requires   `yaml:"requires"`
+
+	// pool contains the requirements of the other rules in the approval pool
+	// of this rule; it is set when the policy is parsed
+	pool []*Requires
+
+	// now returns the current time; time.Now is used if nil
+	now func() time.Time
 }
 
 type Options struct {
@@ -40,20 +48,69 @@ type Options struct {
 	AllowContributor   bool `yaml:"allow_contributor"`
 	InvalidateOnPush   bool `yaml:"invalidate_on_push"`
 	IgnoreUpdateMerges bool `yaml:"ignore_update_merges"`
+	IgnoreStaleReviews bool `yaml:"ignore_stale_reviews"`
+
+	// PushGracePeriod coalesces pushes within this long of the first push of
+	// a burst, so approvals submitted during the burst are not invalidated by
+	// its later pushes.
+	PushGracePeriod time.Duration `yaml:"push_grace_period"`
+
+	// IgnoreWhitespaceChanges keeps approvals that would be invalidated by a
+	// push if the push only changed whitespace.
+	IgnoreWhitespaceChanges bool `yaml:"ignore_whitespace_changes"`
+
+	// IgnoreBaseUpdates keeps approvals that would be invalidated by a push if
+	// the push only merged or rebased on the target branch without changing
+	// the changes of the pull request.
+	IgnoreBaseUpdates bool `yaml:"ignore_base_updates"`
+
+	// RequireHeadApproval only counts reviews submitted on the current head
+	// commit. Comments are not associated with a commit and never count.
+	RequireHeadApproval bool `yaml:"require_head_approval"`
+
+	// InvalidateOnForcePush ignores approvals submitted before the most
+	// recent force-push to the head branch and reviews of the commit that it
+	// replaced.
+	InvalidateOnForcePush bool `yaml:"invalidate_on_force_push"`
+
+	// BlockOnChangesRequested keeps the rule pending while any user's most
+	// recent review requests changes, even if it has enough approvals.
+	BlockOnChangesRequested bool `yaml:"block_on_changes_requested"`
+
+	// ApprovalPool links all rules with the same pool name: a user who may
+	// approve any rule in the pool may approve every rule in the pool.
+	ApprovalPool string `yaml:"approval_pool"`
+
+	// MinApproverAccountAge is the minimum age of an approver's account at
+	// the time of their approval for the approval to count.
+	MinApproverAccountAge time.Duration `yaml:"min_approver_account_age"`
+
+	// SelfDismissalWindow ignores review approvals submitted less than this
+	// long after the approver dismissed their own review, which can be used
+	// to reset time-based requirements.
+	SelfDismissalWindow time.Duration `yaml:"self_dismissal_window"`
+
+	// OverdueAfter is how long after the creation of the pull request a
+	// pending rule becomes overdue. Overdue rules are still pending, but
+	// report a distinct reason.
+	OverdueAfter time.Duration `yaml:"overdue_after"`
+
+	// PredicateCommits sets the commits that predicates use: "all" commits
+	// of the pull request, a preview of the "squash" commit, or "auto" to use
+	// the squash commit if the repository only allows squash merging.
+	PredicateCommits string `yaml:"predicate_commits"`
 
 	Methods *common.Methods `yaml:"methods"`
+
+	// ActorMethods replaces Methods for specific users, organizations, or
+	// teams. The first entry that contains a user applies to them.
+	ActorMethods []ActorMethods `yaml:"actor_methods"`
 }
 
 func (opts *Options) GetMethods() *common.Methods {
 	methods := opts.Methods
 	if methods == nil {
-		methods = &common.Methods{
-			Comments: []string{
-				":+1:",
-				"👍",
-			},
-			GithubReview: true,
-		}
+		methods = common.DefaultApprovalMethods()
 	}
 
 	methods.GithubReviewState = pull.ReviewApproved
@@ -63,25 +120,130 @@ func (opts *Options) GetMethods() *common.Methods {
 type Requires struct {
 	Count int `yaml:"count"`
 
+	// SizeTiers replace Count with the count of the first tier that contains
+	// the pull request, or of the last tier if it is larger than all tiers.
+	SizeTiers []SizeTier `yaml:"size_tiers"`
+
+	// MaxWeightPerUser is the maximum number of approvals a single user can
+	// contribute with a weighted comment. If zero, weights are not limited.
+	MaxWeightPerUser int `yaml:"max_weight_per_user"`
+
+	// ApproverWeights give approvals from some users a weight other than
+	// one. The largest weight of the entries that contain a user is used.
+	ApproverWeights []ApproverWeight `yaml:"approver_weights"`
+
+	// ExternalChecks are the names of external checks the pull request
+	// author must pass before the rule can be approved.
+	ExternalChecks []string `yaml:"external_checks"`
+
+	// Statuses are the commit status contexts or check run names that must
+	// be successful on the head commit before the rule can be approved.
+	Statuses []string `yaml:"statuses"`
+
+	// Workflows are the GitHub Actions workflows that must succeed on the
+	// head commit before the rule can be approved.
+	Workflows *Workflows `yaml:"workflows"`
+
+	// CheckRuns are the commit status contexts or check run names that must
+	// be successful in addition to the approvals. Unlike Statuses, they are
+	// evaluated alongside the approvals and each has its own result.
+	CheckRuns []string `yaml:"check_runs"`
+
+	// OutsideDepartment requires at least one approver who does not share a
+	// department with the author. Departments are the top-level teams of the
+	// organization that owns the repository.
+	OutsideDepartment bool `yaml:"outside_department"`
+
+	// FileCoverage requires that every changed file has a review comment from
+	// at least one approver.
+	FileCoverage bool `yaml:"file_coverage"`
+
+	// InlineComments only counts approvals from users who left at least one
+	// review comment on the diff of the pull request.
+	InlineComments bool `yaml:"inline_comments"`
+
+	// RequestedReviewers only counts approvals from users who were requested
+	// to review the pull request at any time, directly or as a member of a
+	// requested team.
+	RequestedReviewers bool `yaml:"requested_reviewers"`
+
+	// Components require approvals from the owners of each component with
+	// changed files, in addition to the approvals required by Count.
+	Components []Component `yaml:"components"`
+
+	// LastAuthors requires an approval from the last user to change each
+	// modified file on the base branch, unless they are the author.
+	LastAuthors bool `yaml:"last_authors"`
+
+	// Quorum requires approvals from a number of the named users, in addition
+	// to the approvals required by Count.
+	Quorum *Quorum `yaml:"quorum"`
+
+	// MinApproverContributions is the minimum number of pull requests an
+	// approver must have merged into the repository for their approval to
+	// count.
+	MinApproverContributions int `yaml:"min_approver_contributions"`
+
+	// CoolingOffPeriod is the minimum time between the creation of the pull
+	// request and an approval for the approval to count.
+	CoolingOffPeriod time.Duration `yaml:"cooling_off_period"`
+
+	// TemplateSections requires that the pull request description contains
+	// every section of the repository's pull request template and that each
+	// section has content other than the template's placeholder text.
+	TemplateSections bool `yaml:"template_sections"`
+
+	// IndependentApprovers ignores approvals from users who have recently
+	// only approved, and only been approved by, the author or another
+	// approver of the pull request.
+	IndependentApprovers *IndependentApprovers `yaml:"independent_approvers"`
+
 	common.Actors `yaml:",inline"`
 }
 
+func (req *Requires) validate() error {
+	if req.Quorum != nil {
+		if err := req.Quorum.validate(); err != nil {
+			return err
+		}
+	}
+	if len(req.SizeTiers) > 0 {
+		if req.Count != 0 {
+			return errors.New("count and size_tiers cannot both be set")
+		}
+		if err := validateSizeTiers(req.SizeTiers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *Rule) Evaluate(ctx context.Context, prctx pull.Context) (res common.Result) {
 	log := zerolog.Ctx(ctx)
 
 	res.Name = r.Name
+	res.RuleDescription = r.Description
 	res.Status = common.StatusSkipped
 
+	predctx, err := r.predicateContext(prctx)
+	if err != nil {
+		res.Error = errors.Wrap(err, "failed to evaluate predicate")
+		res.Reason = common.ReasonError
+		return
+	}
+
 	for _, p := range r.Predicates.Predicates() {
-		satisfied, desc, err := p.Evaluate(ctx, prctx)
+		satisfied, desc, err := p.Evaluate(ctx, predctx)
 		if err != nil {
 			res.Error = errors.Wrap(err, "failed to evaluate predicate")
+			res.Reason = common.ReasonError
 			return
 		}
 
 		if !satisfied {
 			log.Debug().Msgf("skipping rule, predicate of type %T was not satisfied", p)
 
+			res.Reason = common.ReasonPredicatesNotSatisfied
 			res.Description = desc
 			if desc == "" {
 				res.Description = "The preconditions of this rule are not satisfied"
@@ -91,50 +253,200 @@ func (r *Rule) Evaluate(ctx context.Context, prctx pull.Context) (res common.Res
 		}
 	}
 
-	approved, msg, err := r.IsApproved(ctx, prctx)
+	approved, msg, reason, children, err := r.isApproved(ctx, prctx)
 	if err != nil {
 		res.Error = errors.Wrap(err, "failed to compute approval status")
+		res.Reason = common.ReasonError
 		return
 	}
 
 	res.Description = msg
+	res.Reason = reason
+	res.Children = children
 	if approved {
 		res.Status = common.StatusApproved
-	} else {
-		res.Status = common.StatusPending
+		return
+	}
+
+	res.Status = common.StatusPending
+	if r.Options.OverdueAfter > 0 {
+		if deadline := prctx.CreatedAt().Add(r.Options.OverdueAfter); !r.currentTime().Before(deadline) {
+			log.Debug().Msgf("rule is overdue since %s", deadline.Format(time.RFC3339))
+
+			res.Overdue = true
+			res.Description = fmt.Sprintf("%s. Overdue since %s", msg, deadline.UTC().Format("2006-01-02 15:04 MST"))
+		}
 	}
 	return
 }
 
-func (r *Rule) IsApproved(ctx context.Context, prctx pull.Context) (bool, string, error) {
+func (r *Rule) IsApproved(ctx context.Context, prctx pull.Context) (bool, string, common.Reason, error) {
+	approved, msg, reason, _, err := r.isApproved(ctx, prctx)
+	return approved, msg, reason, err
+}
+
+// isApproved is like IsApproved, but also returns a result for each component
+// of the rule that has changed files, or for the approvals and each check run
+// if the rule requires check runs
+func (r *Rule) isApproved(ctx context.Context, prctx pull.Context) (bool, string, common.Reason, []*common.Result, error) {
+	approved, msg, reason, children, err := r.evaluateApprovals(ctx, prctx)
+	if err != nil || len(r.Requires.CheckRuns) == 0 {
+		return approved, msg, reason, children, err
+	}
+	return r.Requires.withCheckRuns(ctx, prctx, approved, msg, reason, children)
+}
+
+func (r *Rule) evaluateApprovals(ctx context.Context, prctx pull.Context) (bool, string, common.Reason, []*common.Result, error) {
 	log := zerolog.Ctx(ctx)
 
-	if r.Requires.Count <= 0 {
+	author := prctx.Author()
+	for _, name := range r.Requires.ExternalChecks {
+		passed, err := prctx.ExternalCheck(name, author)
+		if err != nil {
+			return false, "", "", nil, errors.Wrapf(err, "failed to run external check %q", name)
+		}
+		if !passed {
+			log.Debug().Msgf("author %s did not pass external check %q", author, name)
+			return false, fmt.Sprintf("Waiting for %s to pass external check %q", author, name), common.ReasonPendingExternalCheck, nil, nil
+		}
+	}
+
+	if len(r.Requires.Statuses) > 0 {
+		statuses, err := prctx.Statuses()
+		if err != nil {
+			return false, "", "", nil, errors.Wrap(err, "failed to get commit statuses")
+		}
+		for _, name := range r.Requires.Statuses {
+			if state := statuses[name]; state != "success" {
+				log.Debug().Msgf("status %q is %q", name, state)
+				return false, fmt.Sprintf("Waiting for status %q to succeed", name), common.ReasonPendingStatus, nil, nil
+			}
+		}
+	}
+
+	if r.Requires.Workflows != nil {
+		msg, err := r.Requires.Workflows.pending(prctx)
+		if err != nil {
+			return false, "", "", nil, err
+		}
+		if msg != "" {
+			log.Debug().Msg(msg)
+			return false, msg, common.ReasonPendingWorkflow, nil, nil
+		}
+	}
+
+	if r.Requires.TemplateSections {
+		incomplete, err := incompleteTemplateSections(prctx)
+		if err != nil {
+			return false, "", "", nil, err
+		}
+		if len(incomplete) > 0 {
+			log.Debug().Msgf("found %d incomplete template sections", len(incomplete))
+			msg := fmt.Sprintf("Waiting for the description to complete template sections: %s", strings.Join(incomplete, ", "))
+			return false, msg, common.ReasonRequiresTemplateSections, nil, nil
+		}
+	}
+
+	if r.Options.BlockOnChangesRequested {
+		requesters, err := changeRequesters(ctx, prctx)
+		if err != nil {
+			return false, "", "", nil, err
+		}
+		if len(requesters) > 0 {
+			msg := fmt.Sprintf("Waiting for %s to approve or dismiss requested changes", strings.Join(requesters, ", "))
+			return false, msg, common.ReasonChangesRequested, nil, nil
+		}
+	}
+
+	count := r.Requires.Count
+	var sizeDesc string
+	if len(r.Requires.SizeTiers) > 0 {
+		size, err := findSizeTier(prctx, r.Requires.SizeTiers)
+		if err != nil {
+			return false, "", "", nil, err
+		}
+		log.Debug().Msgf("pull request is in size tier %q with %d modified lines and %d files", size.Tier.Name, size.Lines, size.Files)
+
+		count = size.Tier.Count
+		sizeDesc = " for " + size.String()
+	}
+
+	if count <= 0 && len(r.Requires.Components) == 0 && r.Requires.Quorum == nil {
 		log.Debug().Msg("rule requires no approvals")
-		return true, "No approval required", nil
+		if len(r.Requires.Statuses) > 0 {
+			return true, "All required statuses succeeded", "", nil, nil
+		}
+		if r.Requires.Workflows != nil {
+			return true, "All required workflows succeeded", "", nil, nil
+		}
+		return true, "No approval required" + sizeDesc, "", nil, nil
 	}
 
-	candidates, err := r.Options.GetMethods().Candidates(ctx, prctx)
+	candidates, err := r.candidates(ctx, prctx)
 	if err != nil {
-		return false, "", errors.Wrap(err, "failed to get approval candidates")
+		return false, "", "", nil, errors.Wrap(err, "failed to get approval candidates")
 	}
+
+	if r.Options.IgnoreStaleReviews {
+		candidates, err = r.filterStaleReviews(ctx, prctx, candidates)
+		if err != nil {
+			return false, "", "", nil, err
+		}
+	}
+
+	if r.Options.RequireHeadApproval {
+		candidates = filterHeadReviews(ctx, prctx, candidates)
+	}
+
+	var forcePush *pull.ForcePush
+	var forcePushIgnored int
+	if r.Options.InvalidateOnForcePush {
+		var allowed []*common.Candidate
+		forcePush, allowed, err = filterForcePushedApprovals(ctx, prctx, candidates)
+		if err != nil {
+			return false, "", "", nil, err
+		}
+		forcePushIgnored = len(candidates) - len(allowed)
+		candidates = allowed
+	}
+
+	candidates = common.DeduplicateCandidates(candidates)
 	sort.Stable(common.CandidatesByCreationTime(candidates))
 
 	if r.Options.InvalidateOnPush {
 		commits, err := r.filteredCommits(prctx)
 		if err != nil {
-			return false, "", err
+			return false, "", "", nil, err
 		}
 
 		last := findLastPushed(commits)
 		if last == nil {
-			return false, "", errors.New("no commit contained a push date")
+			return false, "", "", nil, errors.New("no commit contained a push date")
+		}
+
+		invalidatedAt := *last.PushedAt
+		if r.Options.PushGracePeriod > 0 {
+			invalidatedAt = findPushBurstStart(commits, r.Options.PushGracePeriod)
+			log.Debug().Msgf("last push at %s is in a burst of pushes that started at %s",
+				last.PushedAt.Format(time.RFC3339),
+				invalidatedAt.Format(time.RFC3339))
 		}
 
 		var allowedCandidates []*common.Candidate
 		for _, candidate := range candidates {
-			if candidate.CreatedAt.After(*last.PushedAt) {
+			if candidate.CreatedAt.After(invalidatedAt) {
 				allowedCandidates = append(allowedCandidates, candidate)
+				continue
+			}
+
+			if r.Options.IgnoreWhitespaceChanges || r.Options.IgnoreBaseUpdates {
+				keep, err := r.keepsApproval(ctx, prctx, commits, candidate)
+				if err != nil {
+					return false, "", "", nil, err
+				}
+				if keep {
+					allowedCandidates = append(allowedCandidates, candidate)
+				}
 			}
 		}
 
@@ -148,70 +460,371 @@ func (r *Rule) IsApproved(ctx context.Context, prctx pull.Context) (bool, string
 
 	log.Debug().Msgf("found %d candidates for approval", len(candidates))
 
-	// collect users "banned" by approval options
-	banned := make(map[string]bool)
-
-	// "author" is the user who opened the PR
-	// if contributors are allowed, the author counts as a contributor
-	author := prctx.Author()
-	if !r.Options.AllowAuthor && !r.Options.AllowContributor {
-		banned[author] = true
-	}
-
-	// "contributor" is any user who added a commit to the PR
+	// collect users "banned" by approval options: "author" is the user who
+	// opened the PR and "contributor" is any user who added a commit to the PR
+	var contributions []*pull.Commit
 	if !r.Options.AllowContributor {
-		commits, err := r.filteredCommits(prctx)
+		contributions, err = r.filteredCommits(prctx)
 		if err != nil {
-			return false, "", err
-		}
-
-		for _, c := range commits {
-			for _, u := range c.Users() {
-				if u != author {
-					banned[u] = true
-				}
-			}
+			return false, "", "", nil, err
 		}
 	}
+	banned := common.IneligibleApprovers(author, contributions, r.Options.AllowAuthor, r.Options.AllowContributor)
 
 	// filter real approvers using banned status and required membership
+	var eligible []string
 	var approvers []string
+	var approvals int
+	var history coReviewers
+	var dismissals []*pull.ReviewDismissal
+	var commenters map[string]bool
+	var requests *pull.ReviewRequests
+	coolingOffEnd := prctx.CreatedAt().Add(r.Requires.CoolingOffPeriod)
 	for _, c := range candidates {
 		if banned[c.User] {
 			log.Debug().Str("user", c.User).Msg("rejecting approval by banned user")
 			continue
 		}
 
-		isApprover, err := r.Requires.IsActor(ctx, prctx, c.User)
+		if window := r.Options.SelfDismissalWindow; window > 0 && c.Type == common.ReviewCandidate {
+			if dismissals == nil {
+				if dismissals, err = prctx.ReviewDismissals(); err != nil {
+					return false, "", "", nil, errors.Wrap(err, "failed to get review dismissals")
+				}
+			}
+			if d := selfDismissal(dismissals, c, window); d != nil {
+				log.Info().Str("user", c.User).Msgf("ignoring suspect approval submitted %s after the user dismissed their own review at %s",
+					c.CreatedAt.Sub(d.CreatedAt), d.CreatedAt.Format(time.RFC3339))
+				continue
+			}
+		}
+
+		if r.Requires.CoolingOffPeriod > 0 && c.CreatedAt.Before(coolingOffEnd) {
+			log.Debug().Str("user", c.User).Msgf("ignoring approval submitted before the cooling-off period ended at %s", coolingOffEnd.Format(time.RFC3339))
+			continue
+		}
+
+		if r.Options.MinApproverAccountAge > 0 {
+			createdAt, err := prctx.UserCreatedAt(c.User)
+			if err != nil {
+				return false, "", "", nil, errors.Wrap(err, "failed to get candidate account age")
+			}
+			if c.CreatedAt.Sub(createdAt) < r.Options.MinApproverAccountAge {
+				log.Debug().Str("user", c.User).Msgf("ignoring approval by account created at %s", createdAt.Format(time.RFC3339))
+				continue
+			}
+		}
+		if min := r.Requires.MinApproverContributions; min > 0 {
+			merged, err := prctx.MergedPullCount(c.User)
+			if err != nil {
+				return false, "", "", nil, errors.Wrap(err, "failed to get candidate contributions")
+			}
+			if merged < min {
+				log.Debug().Str("user", c.User).Msgf("ignoring approval by user with %d merged pull requests", merged)
+				continue
+			}
+		}
+		if r.Requires.InlineComments {
+			if commenters == nil {
+				if commenters, err = inlineCommenters(prctx); err != nil {
+					return false, "", "", nil, err
+				}
+			}
+			if !commenters[c.User] {
+				log.Debug().Str("user", c.User).Msg("ignoring approval by user without inline comments")
+				continue
+			}
+		}
+		if r.Requires.RequestedReviewers {
+			if requests == nil {
+				if requests, err = prctx.ReviewRequestHistory(); err != nil {
+					return false, "", "", nil, errors.Wrap(err, "failed to get review request history")
+				}
+			}
+			requested, err := wasRequested(prctx, requests, c.User)
+			if err != nil {
+				return false, "", "", nil, err
+			}
+			if !requested {
+				log.Debug().Str("user", c.User).Msg("ignoring approval by user who was not requested to review")
+				continue
+			}
+		}
+		eligible = append(eligible, c.User)
+
+		isApprover, err := r.isApprover(ctx, prctx, c.User)
 		if err != nil {
-			return false, "", errors.Wrap(err, "failed to check candidate status")
+			return false, "", "", nil, errors.Wrap(err, "failed to check candidate status")
 		}
 		if !isApprover {
 			log.Debug().Str("user", c.User).Msg("ignoring approval by non-whitelisted user")
 			continue
 		}
 
+		if ia := r.Requires.IndependentApprovers; ia != nil {
+			if history == nil {
+				if history, err = ia.coReviewers(prctx); err != nil {
+					return false, "", "", nil, err
+				}
+			}
+			if partner := history.partner(c.User, append([]string{author}, approvers...)); partner != "" {
+				log.Debug().Str("user", c.User).Msgf("ignoring approval by user who only approves and is only approved by %s", partner)
+				continue
+			}
+		}
+
+		weight, err := r.Requires.weight(ctx, prctx, c)
+		if err != nil {
+			return false, "", "", nil, err
+		}
+
 		approvers = append(approvers, c.User)
+		approvals += weight
+	}
+
+	log.Debug().Msgf("found %d/%d required approvals from %d approvers", approvals, count, len(approvers))
+	remaining := count - approvals
+
+	children, pending, err := r.componentResults(ctx, prctx, eligible)
+	if err != nil {
+		return false, "", "", nil, err
+	}
+	if remaining <= 0 && len(pending) > 0 {
+		msg := fmt.Sprintf("Waiting for approval from owners of %s", strings.Join(pending, ", "))
+		return false, msg, common.ReasonRequiresComponentApproval, children, nil
 	}
 
-	log.Debug().Msgf("found %d/%d required approvers", len(approvers), r.Requires.Count)
-	remaining := r.Requires.Count - len(approvers)
+	var quorumApprovers []string
+	if q := r.Requires.Quorum; q != nil {
+		results, approved, waiting := q.results(eligible)
+		children = append(children, results...)
+		quorumApprovers = approved
+
+		log.Debug().Msgf("found %d/%d required quorum approvals", len(approved), q.Count)
+		if remaining <= 0 && len(approved) < q.Count {
+			return false, q.pendingDescription(approved, waiting), common.ReasonRequiresQuorum, children, nil
+		}
+	}
+
+	if remaining <= 0 && r.Requires.OutsideDepartment {
+		outside, err := hasOutsideApprover(ctx, prctx, author, approvers)
+		if err != nil {
+			return false, "", "", nil, err
+		}
+		if !outside {
+			msg := fmt.Sprintf("%d/%d approvals required. Waiting for an approval from outside the author's department", approvals, count)
+			return false, msg, common.ReasonRequiresOutsideDepartment, children, nil
+		}
+	}
+
+	if remaining <= 0 && r.Requires.FileCoverage {
+		uncovered, err := uncoveredFiles(prctx, approvers)
+		if err != nil {
+			return false, "", "", nil, err
+		}
+		if len(uncovered) > 0 {
+			log.Debug().Msgf("files without comments from approvers: %s", strings.Join(uncovered, ", "))
+			msg := fmt.Sprintf("Waiting for approvers to comment on %s", numberOfChangedFiles(len(uncovered)))
+			return false, msg, common.ReasonRequiresFileCoverage, children, nil
+		}
+	}
+
+	if remaining <= 0 && r.Requires.LastAuthors {
+		missing, err := r.missingLastAuthors(ctx, prctx, banned, approvers)
+		if err != nil {
+			return false, "", "", nil, err
+		}
+		if len(missing) > 0 {
+			msg := fmt.Sprintf("Waiting for approval from last authors: %s", strings.Join(missing, ", "))
+			return false, msg, common.ReasonRequiresLastAuthors, children, nil
+		}
+	}
+
+	if remaining <= 0 && count <= 0 {
+		if len(quorumApprovers) > 0 {
+			msg := fmt.Sprintf("Approved by quorum: %s", strings.Join(quorumApprovers, ", "))
+			return true, msg, "", children, nil
+		}
+		if len(children) == 0 {
+			return true, "No approval required" + sizeDesc, "", children, nil
+		}
+		return true, "Approved by the owners of all changed components", "", children, nil
+	}
 
 	if remaining <= 0 {
-		msg := fmt.Sprintf("Approved by %s", strings.Join(approvers, ", "))
-		return true, msg, nil
+		msg := fmt.Sprintf("Approved by %s%s", strings.Join(approvers, ", "), sizeDesc)
+		return true, msg, "", children, nil
+	}
+
+	required := fmt.Sprintf("%d/%d approvals required%s", approvals, count, sizeDesc)
+	if r.Requires.CoolingOffPeriod > 0 && r.currentTime().Before(coolingOffEnd) {
+		msg := fmt.Sprintf("%s. Approvals count after the cooling-off period ends at %s",
+			required,
+			coolingOffEnd.UTC().Format("15:04 MST"))
+		return false, msg, common.ReasonInsufficientApprovals, children, nil
+	}
+
+	if forcePushIgnored > 0 {
+		msg := fmt.Sprintf("%s. Ignored %s submitted before the force-push of %.7s by %s at %s",
+			required,
+			numberOfApprovals(forcePushIgnored),
+			forcePush.AfterSHA,
+			forcePush.Actor,
+			forcePush.CreatedAt.UTC().Format("15:04 MST"))
+		return false, msg, common.ReasonForcePushed, children, nil
 	}
 
 	if len(candidates) > 0 && len(approvers) == 0 {
-		msg := fmt.Sprintf("%d/%d approvals required. Ignored %s from disqualified users",
-			len(approvers),
-			r.Requires.Count,
+		msg := fmt.Sprintf("%s. Ignored %s from disqualified users",
+			required,
 			numberOfApprovals(len(candidates)))
-		return false, msg, nil
+		return false, msg, common.ReasonDisqualifiedApprovals, children, nil
+	}
+
+	return false, required, common.ReasonInsufficientApprovals, children, nil
+}
+
+func (r *Rule) currentTime() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// isApprover returns true if the user satisfies the actor requirements of
+// this rule or of any other rule in its approval pool
+func (r *Rule) isApprover(ctx context.Context, prctx pull.Context, user string) (bool, error) {
+	isApprover, err := r.Requires.IsActor(ctx, prctx, user)
+	if err != nil || isApprover {
+		return isApprover, err
+	}
+
+	for _, req := range r.pool {
+		isApprover, err := req.IsActor(ctx, prctx, user)
+		if err != nil || isApprover {
+			return isApprover, err
+		}
+	}
+	return false, nil
+}
+
+// ApproverWeight is the number of approvals that an approval from any of the
+// actors counts as.
+type ApproverWeight struct {
+	common.Actors `yaml:",inline"`
+	Weight        int `yaml:"weight"`
+}
+
+// weight returns the number of approvals a candidate counts as. This is the
+// larger of the weight of the candidate's approval and the weight of the
+// candidate's user.
+func (r *Requires) weight(ctx context.Context, prctx pull.Context, c *common.Candidate) (int, error) {
+	weight := c.Weight
+	for _, aw := range r.ApproverWeights {
+		if aw.Weight <= weight {
+			continue
+		}
+		isActor, err := aw.IsActor(ctx, prctx, c.User)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to check approver weight")
+		}
+		if isActor {
+			weight = aw.Weight
+		}
+	}
+
+	if weight < 1 {
+		weight = 1
+	}
+	if r.MaxWeightPerUser > 0 && weight > r.MaxWeightPerUser {
+		weight = r.MaxWeightPerUser
+	}
+	return weight, nil
+}
+
+// filterStaleReviews removes review candidates that were submitted before the
+// most recent push, matching the behavior of branch protection rules that
+// dismiss stale reviews. GitHub dismisses reviews asynchronously, so reviews
+// may still appear approved when evaluating the event for a push. Comments are
+// never dismissed by GitHub and are not affected.
+func (r *Rule) filterStaleReviews(ctx context.Context, prctx pull.Context, candidates []*common.Candidate) ([]*common.Candidate, error) {
+	log := zerolog.Ctx(ctx)
+
+	// GitHub dismisses reviews for all pushes, including update merges, so
+	// this uses the unfiltered commits
+	commits, err := prctx.Commits()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list commits")
+	}
+
+	last := findLastPushed(commits)
+	if last == nil {
+		return nil, errors.New("no commit contained a push date")
 	}
 
-	msg := fmt.Sprintf("%d/%d approvals required", len(approvers), r.Requires.Count)
-	return false, msg, nil
+	var allowed []*common.Candidate
+	for _, c := range candidates {
+		if c.Type == common.ReviewCandidate && !c.CreatedAt.After(*last.PushedAt) {
+			log.Debug().Str("user", c.User).Msgf("ignoring review dismissed by push of %.10s", last.SHA)
+			continue
+		}
+		allowed = append(allowed, c)
+	}
+	return allowed, nil
+}
+
+// filterHeadReviews removes candidates that were not reviews of the current
+// head commit. Unlike push invalidation, this does not depend on commit or
+// review times, which may be misleading after history is rewritten.
+func filterHeadReviews(ctx context.Context, prctx pull.Context, candidates []*common.Candidate) []*common.Candidate {
+	log := zerolog.Ctx(ctx)
+
+	head := prctx.HeadSHA()
+
+	var allowed []*common.Candidate
+	for _, c := range candidates {
+		if c.Type != common.ReviewCandidate || c.SHA != head {
+			log.Debug().Str("user", c.User).Msgf("ignoring approval that is not a review of %.10s", head)
+			continue
+		}
+		allowed = append(allowed, c)
+	}
+	return allowed
+}
+
+// filterForcePushedApprovals removes candidates that were submitted before the
+// most recent force-push and reviews of the commit that it replaced, which may
+// be submitted later from a page loaded before the force-push. It returns the
+// most recent force-push, or nil if the head branch was never force-pushed.
+func filterForcePushedApprovals(ctx context.Context, prctx pull.Context, candidates []*common.Candidate) (*pull.ForcePush, []*common.Candidate, error) {
+	log := zerolog.Ctx(ctx)
+
+	pushes, err := prctx.ForcePushes()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list force-pushes")
+	}
+	if len(pushes) == 0 {
+		return nil, candidates, nil
+	}
+	last := pushes[len(pushes)-1]
+
+	var allowed []*common.Candidate
+	for _, c := range candidates {
+		if !c.CreatedAt.After(last.CreatedAt) || (c.Type == common.ReviewCandidate && last.BeforeSHA != "" && c.SHA == last.BeforeSHA) {
+			continue
+		}
+		allowed = append(allowed, c)
+	}
+
+	if ignored := len(candidates) - len(allowed); ignored > 0 {
+		log.Info().Msgf("ignored %d candidates invalidated by force-push of %.10s (replacing %.10s) by %s at %s",
+			ignored,
+			last.AfterSHA,
+			last.BeforeSHA,
+			last.Actor,
+			last.CreatedAt.Format(time.RFC3339))
+	}
+	return last, allowed, nil
 }
 
 func (r *Rule) filteredCommits(prctx pull.Context) ([]*pull.Commit, error) {
@@ -267,9 +880,38 @@ func findLastPushed(commits []*pull.Commit) *pull.Commit {
 	return last
 }
 
+// findPushBurstStart returns the time of the first push in the burst that
+// contains the last push. A burst starts with a push and includes all pushes
+// within the grace period after that push, so a series of pushes that each
+// follow the previous one quickly still starts a new burst after the period.
+func findPushBurstStart(commits []*pull.Commit, grace time.Duration) time.Time {
+	var pushes []time.Time
+	for _, c := range commits {
+		if c.PushedAt != nil {
+			pushes = append(pushes, *c.PushedAt)
+		}
+	}
+	sort.Slice(pushes, func(i, j int) bool { return pushes[i].Before(pushes[j]) })
+
+	var start time.Time
+	for i, t := range pushes {
+		if i == 0 || t.Sub(start) > grace {
+			start = t
+		}
+	}
+	return start
+}
+
 func numberOfApprovals(count int) string {
 	if count == 1 {
 		return "1 approval"
 	}
 	return fmt.Sprintf("%d approvals", count)
 }
+
+func numberOfChangedFiles(count int) string {
+	if count == 1 {
+		return "1 changed file"
+	}
+	return fmt.Sprintf("%d changed files", count)
+}
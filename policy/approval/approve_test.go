@@ -16,6 +16,8 @@ package approval
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -25,6 +27,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/policy/predicate"
 	"github.com/palantir/policy-bot/pull"
 	"github.com/palantir/policy-bot/pull/pulltest"
 )
@@ -114,7 +117,7 @@ func TestIsApproved(t *testing.T) {
 	}
 
 	assertApproved := func(t *testing.T, prctx pull.Context, r *Rule, expected string) {
-		approved, msg, err := r.IsApproved(ctx, prctx)
+		approved, msg, _, err := r.IsApproved(ctx, prctx)
 		require.NoError(t, err)
 
 		if assert.True(t, approved, "pull request was not approved") {
@@ -123,7 +126,7 @@ func TestIsApproved(t *testing.T) {
 	}
 
 	assertPending := func(t *testing.T, prctx pull.Context, r *Rule, expected string) {
-		approved, msg, err := r.IsApproved(ctx, prctx)
+		approved, msg, _, err := r.IsApproved(ctx, prctx)
 		require.NoError(t, err)
 
 		if assert.False(t, approved, "pull request was incorrectly approved") {
@@ -280,29 +283,1284 @@ func TestIsApproved(t *testing.T) {
 		assertPending(t, prctx, r, "0/1 approvals required. Ignored 4 approvals from disqualified users")
 	})
 
+	t.Run("pushGracePeriod", func(t *testing.T) {
+		prctx := basePullContext()
+		pushes := func(offsets ...time.Duration) []*pull.Commit {
+			var commits []*pull.Commit
+			for i, offset := range offsets {
+				commits = append(commits, &pull.Commit{
+					PushedAt:  newTime(now.Add(offset)),
+					SHA:       fmt.Sprintf("%040d", i),
+					Author:    "mhaypenny",
+					Committer: "mhaypenny",
+				})
+			}
+			return commits
+		}
+
+		r := &Rule{
+			Options: Options{
+				InvalidateOnPush: true,
+			},
+			Requires: Requires{
+				Count: 1,
+				Actors: common.Actors{
+					Users: []string{"comment-approver"},
+				},
+			},
+		}
+
+		// the approval at 20s is between pushes of a burst
+		prctx.CommitsValue = pushes(15*time.Second, 25*time.Second, 35*time.Second)
+		assertPending(t, prctx, r, "0/1 approvals required. Ignored 4 approvals from disqualified users")
+
+		r.Options.PushGracePeriod = 30 * time.Second
+		assertApproved(t, prctx, r, "Approved by comment-approver")
+
+		// bursts are measured from their first push, not the previous push
+		r.Options.PushGracePeriod = 15 * time.Second
+		assertPending(t, prctx, r, "0/1 approvals required. Ignored 4 approvals from disqualified users")
+
+		// the whole burst invalidates an approval submitted before it
+		r.Options.PushGracePeriod = 30 * time.Second
+		prctx.CommitsValue = pushes(25*time.Second, 30*time.Second, 35*time.Second)
+		assertPending(t, prctx, r, "0/1 approvals required. Ignored 4 approvals from disqualified users")
+	})
+
 	t.Run("invalidateReviewOnPush", func(t *testing.T) {
 		prctx := basePullContext()
-		prctx.CommitsValue = []*pull.Commit{
+		prctx.CommitsValue = []*pull.Commit{
+			{
+				PushedAt:  newTime(now.Add(85 * time.Second)),
+				SHA:       "c6ade256ecfc755d8bc877ef22cc9e01745d46bb",
+				Author:    "mhaypenny",
+				Committer: "mhaypenny",
+			},
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Count: 1,
+				Actors: common.Actors{
+					Users: []string{"review-approver"},
+				},
+			},
+		}
+		assertApproved(t, prctx, r, "Approved by review-approver")
+
+		r.Options.InvalidateOnPush = true
+		assertPending(t, prctx, r, "0/1 approvals required")
+	})
+
+	t.Run("ignoreWhitespaceChanges", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.HeadSHAValue = "97d5ea26da319a987d80f6db0b7ef759f2f2e441"
+		prctx.CommitsValue = []*pull.Commit{
+			{
+				PushedAt:  newTime(now.Add(5 * time.Second)),
+				SHA:       "c6ade256ecfc755d8bc877ef22cc9e01745d46bb",
+				Author:    "mhaypenny",
+				Committer: "mhaypenny",
+			},
+			{
+				PushedAt:  newTime(now.Add(25 * time.Second)),
+				SHA:       "97d5ea26da319a987d80f6db0b7ef759f2f2e441",
+				Author:    "mhaypenny",
+				Committer: "mhaypenny",
+			},
+		}
+		prctx.CompareFilesValue = map[string][]*pull.File{
+			"c6ade256ecfc755d8bc877ef22cc9e01745d46bb...97d5ea26da319a987d80f6db0b7ef759f2f2e441": {
+				{
+					Filename: "app.go",
+					Status:   pull.FileModified,
+					Patch:    "@@ -1,3 +1,3 @@\n func main() {\n-\tfmt.Println(\"hello\")  \n+\tfmt.Println(\"hello\")\n }",
+				},
+			},
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Count: 1,
+				Actors: common.Actors{
+					Users: []string{"comment-approver"},
+				},
+			},
+			Options: Options{
+				InvalidateOnPush: true,
+			},
+		}
+		assertPending(t, prctx, r, "0/1 approvals required. Ignored 4 approvals from disqualified users")
+
+		r.Options.IgnoreWhitespaceChanges = true
+		assertApproved(t, prctx, r, "Approved by comment-approver")
+
+		prctx.CompareFilesValue["c6ade256ecfc755d8bc877ef22cc9e01745d46bb...97d5ea26da319a987d80f6db0b7ef759f2f2e441"][0].Patch = "@@ -1,3 +1,3 @@\n func main() {\n-\tfmt.Println(\"hello\")\n+\tfmt.Println(\"goodbye\")\n }"
+		assertPending(t, prctx, r, "0/1 approvals required. Ignored 4 approvals from disqualified users")
+	})
+
+	t.Run("ignoreBaseUpdates", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.BranchBaseName = "develop"
+		prctx.ReviewsValue[1].SHA = "c6ade256ecfc755d8bc877ef22cc9e01745d46bb"
+
+		// the branch was rebased after the review, replacing its commit
+		prctx.CommitsValue = []*pull.Commit{
+			{
+				PushedAt:  newTime(now.Add(85 * time.Second)),
+				SHA:       "97d5ea26da319a987d80f6db0b7ef759f2f2e441",
+				Author:    "mhaypenny",
+				Committer: "mhaypenny",
+			},
+		}
+		prctx.CompareFilesValue = map[string][]*pull.File{
+			"develop...c6ade256ecfc755d8bc877ef22cc9e01745d46bb": {
+				{
+					Filename: "app.go",
+					Status:   pull.FileModified,
+					Patch:    "@@ -1,2 +1,3 @@\n a\n+b\n c",
+				},
+			},
+		}
+		prctx.ChangedFilesValue = []*pull.File{
+			{
+				Filename: "app.go",
+				Status:   pull.FileModified,
+				Patch:    "@@ -10,2 +10,3 @@\n a\n+b\n c",
+			},
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Count: 1,
+				Actors: common.Actors{
+					Users: []string{"review-approver"},
+				},
+			},
+			Options: Options{
+				InvalidateOnPush: true,
+			},
+		}
+		assertPending(t, prctx, r, "0/1 approvals required")
+
+		r.Options.IgnoreBaseUpdates = true
+		assertApproved(t, prctx, r, "Approved by review-approver")
+
+		// the author changed the pull request while rebasing
+		prctx.ChangedFilesValue[0].Patch = "@@ -10,2 +10,4 @@\n a\n+b\n+d\n c"
+		assertPending(t, prctx, r, "0/1 approvals required")
+
+		// the author added a file
+		prctx.ChangedFilesValue = append(prctx.CompareFilesValue["develop...c6ade256ecfc755d8bc877ef22cc9e01745d46bb"], &pull.File{
+			Filename: "new.go",
+			Status:   pull.FileAdded,
+			Patch:    "@@ -0,0 +1 @@\n+package main",
+		})
+		assertPending(t, prctx, r, "0/1 approvals required")
+
+		// the reviewed commit no longer exists
+		prctx.ChangedFilesValue = prctx.CompareFilesValue["develop...c6ade256ecfc755d8bc877ef22cc9e01745d46bb"]
+		prctx.ReviewsValue[1].SHA = "674832587eaaf416371b30f5bc5a47e377f534ec"
+		assertPending(t, prctx, r, "0/1 approvals required")
+	})
+
+	t.Run("ignoreStaleReviews", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.CommitsValue = []*pull.Commit{
+			{
+				PushedAt:  newTime(now.Add(85 * time.Second)),
+				SHA:       "c6ade256ecfc755d8bc877ef22cc9e01745d46bb",
+				Author:    "mhaypenny",
+				Committer: "mhaypenny",
+			},
+		}
+		prctx.ReviewsValue = append(prctx.ReviewsValue, &pull.Review{
+			CreatedAt: now.Add(82 * time.Second),
+			Author:    "comment-approver",
+			State:     pull.ReviewApproved,
+		})
+
+		r := &Rule{
+			Requires: Requires{
+				Count: 2,
+				Actors: common.Actors{
+					Users: []string{"comment-approver", "review-approver"},
+				},
+			},
+		}
+		assertApproved(t, prctx, r, "Approved by review-approver, comment-approver")
+
+		// the stale review by comment-approver is ignored, but not the earlier comment
+		r.Options.IgnoreStaleReviews = true
+		assertPending(t, prctx, r, "1/2 approvals required")
+	})
+
+	t.Run("requireHeadApproval", func(t *testing.T) {
+		prctx := basePullContext()
+
+		// a force-push replaced the reviewed commit, but the new commit
+		// reports a push time before the review
+		prctx.HeadSHAValue = "1c4b6e2b1e1bd8a5c7d2b3a5f0e0c4a9c3b7d218"
+		prctx.CommitsValue = []*pull.Commit{
+			{
+				PushedAt:  newTime(now.Add(5 * time.Second)),
+				SHA:       "1c4b6e2b1e1bd8a5c7d2b3a5f0e0c4a9c3b7d218",
+				Author:    "mhaypenny",
+				Committer: "mhaypenny",
+			},
+		}
+		prctx.ReviewsValue[1].SHA = "97d5ea26da319a987d80f6db0b7ef759f2f2e441"
+
+		r := &Rule{
+			Options: Options{
+				InvalidateOnPush: true,
+			},
+			Requires: Requires{
+				Count: 1,
+				Actors: common.Actors{
+					Users: []string{"comment-approver", "review-approver"},
+				},
+			},
+		}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		// neither the comment nor the review of the replaced commit count
+		r.Options.RequireHeadApproval = true
+		assertPending(t, prctx, r, "0/1 approvals required")
+
+		prctx.ReviewsValue = append(prctx.ReviewsValue, &pull.Review{
+			CreatedAt: now.Add(90 * time.Second),
+			Author:    "review-approver",
+			State:     pull.ReviewApproved,
+			SHA:       "1c4b6e2b1e1bd8a5c7d2b3a5f0e0c4a9c3b7d218",
+		})
+		assertApproved(t, prctx, r, "Approved by review-approver")
+	})
+
+	t.Run("invalidateOnForcePush", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.ReviewsValue[1].SHA = "97d5ea26da319a987d80f6db0b7ef759f2f2e441"
+
+		r := &Rule{
+			Options: Options{
+				InvalidateOnForcePush: true,
+			},
+			Requires: Requires{
+				Count: 1,
+				Actors: common.Actors{
+					Users: []string{"comment-approver", "review-approver"},
+				},
+			},
+		}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		pushedAt := now.Add(100 * time.Second)
+		prctx.HeadSHAValue = "1c4b6e2b1e1bd8a5c7d2b3a5f0e0c4a9c3b7d218"
+		prctx.ForcePushesValue = []*pull.ForcePush{
+			{
+				CreatedAt: pushedAt,
+				Actor:     "mhaypenny",
+				BeforeSHA: "97d5ea26da319a987d80f6db0b7ef759f2f2e441",
+				AfterSHA:  "1c4b6e2b1e1bd8a5c7d2b3a5f0e0c4a9c3b7d218",
+			},
+		}
+
+		approved, msg, reason, err := r.IsApproved(ctx, prctx)
+		require.NoError(t, err)
+		assert.False(t, approved, "pull request was incorrectly approved")
+		assert.Equal(t, common.ReasonForcePushed, reason)
+		assert.Equal(t, "0/1 approvals required. Ignored 5 approvals submitted before the force-push of 1c4b6e2 by mhaypenny at "+pushedAt.UTC().Format("15:04 MST"), msg)
+
+		// a review of the replaced commit does not count, even if it was
+		// submitted after the force-push
+		prctx.ReviewsValue = append(prctx.ReviewsValue, &pull.Review{
+			CreatedAt: now.Add(110 * time.Second),
+			Author:    "review-approver",
+			State:     pull.ReviewApproved,
+			SHA:       "97d5ea26da319a987d80f6db0b7ef759f2f2e441",
+		})
+		approved, _, reason, err = r.IsApproved(ctx, prctx)
+		require.NoError(t, err)
+		assert.False(t, approved, "pull request was incorrectly approved")
+		assert.Equal(t, common.ReasonForcePushed, reason)
+
+		prctx.ReviewsValue = append(prctx.ReviewsValue, &pull.Review{
+			CreatedAt: now.Add(120 * time.Second),
+			Author:    "review-approver",
+			State:     pull.ReviewApproved,
+			SHA:       "1c4b6e2b1e1bd8a5c7d2b3a5f0e0c4a9c3b7d218",
+		})
+		assertApproved(t, prctx, r, "Approved by review-approver")
+	})
+
+	t.Run("minApproverAccountAge", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.UserCreatedAtValues = map[string]time.Time{
+			"comment-approver": now.Add(-2 * time.Hour),
+			"review-approver":  now.Add(-365 * 24 * time.Hour),
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Count: 2,
+				Actors: common.Actors{
+					Users: []string{"comment-approver", "review-approver"},
+				},
+			},
+		}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		r.Options.MinApproverAccountAge = 30 * 24 * time.Hour
+		assertPending(t, prctx, r, "1/2 approvals required")
+	})
+
+	t.Run("externalChecks", func(t *testing.T) {
+		prctx := basePullContext()
+
+		r := &Rule{
+			Requires: Requires{
+				Count:          1,
+				ExternalChecks: []string{"cla"},
+				Actors: common.Actors{
+					Users: []string{"comment-approver"},
+				},
+			},
+		}
+		assertPending(t, prctx, r, "Waiting for mhaypenny to pass external check \"cla\"")
+
+		prctx.ExternalChecksPassed = map[string][]string{
+			"mhaypenny": {"cla"},
+		}
+		assertApproved(t, prctx, r, "Approved by comment-approver")
+
+		r.Requires.Count = 0
+		prctx.ExternalChecksPassed = nil
+		assertPending(t, prctx, r, "Waiting for mhaypenny to pass external check \"cla\"")
+	})
+
+	t.Run("outsideDepartment", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.OwnerValue = "testorg"
+
+		// engineering and finance are departments; payments is nested under
+		// engineering through platform
+		prctx.TeamParentsValue = map[string]string{
+			"testorg/engineering": "",
+			"testorg/platform":    "testorg/engineering",
+			"testorg/payments":    "testorg/platform",
+			"testorg/finance":     "",
+		}
+		prctx.TeamMemberships = map[string][]string{
+			"mhaypenny":        {"testorg/payments"},
+			"comment-approver": {"testorg/engineering"},
+			"review-approver":  {"testorg/platform", "testorg/finance"},
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Count:             1,
+				OutsideDepartment: true,
+				Actors: common.Actors{
+					Users: []string{"comment-approver"},
+				},
+			},
+		}
+		assertPending(t, prctx, r, "1/1 approvals required. Waiting for an approval from outside the author's department")
+
+		// review-approver shares engineering through platform, only finance
+		// is outside the author's department
+		r.Requires.Users = []string{"review-approver"}
+		assertPending(t, prctx, r, "1/1 approvals required. Waiting for an approval from outside the author's department")
+
+		prctx.TeamMemberships["review-approver"] = []string{"testorg/finance"}
+		assertApproved(t, prctx, r, "Approved by review-approver")
+
+		r.Requires.Count = 2
+		r.Requires.Users = []string{"comment-approver", "review-approver"}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+	})
+
+	t.Run("fileCoverage", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.ChangedFilesValue = []*pull.File{
+			{Filename: "app/server.go", Status: pull.FileModified, Additions: 10, Patch: "@@ -1,1 +1,10 @@"},
+			{Filename: "app/client.go", Status: pull.FileModified, Additions: 2, Patch: "@@ -5,1 +5,2 @@"},
+			{Filename: "app/logo.png", Status: pull.FileModified},
+		}
+		prctx.ReviewCommentsValue = []*pull.ReviewComment{
+			{Author: "comment-approver", Path: "app/server.go"},
+			{Author: "other-user", Path: "app/client.go"},
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Count:        1,
+				FileCoverage: true,
+				Actors: common.Actors{
+					Users: []string{"comment-approver", "review-approver"},
+				},
+			},
+		}
+		assertPending(t, prctx, r, "Waiting for approvers to comment on 1 changed file")
+
+		// the binary file has no diff and is covered by any approval
+		prctx.ReviewCommentsValue = append(prctx.ReviewCommentsValue, &pull.ReviewComment{
+			Author: "review-approver",
+			Path:   "app/client.go",
+		})
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+	})
+
+	t.Run("lastAuthors", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.ChangedFilesValue = []*pull.File{
+			{Filename: "app/server.go", Status: pull.FileModified},
+			{Filename: "app/client.go", Status: pull.FileDeleted},
+			{Filename: "app/new.go", Status: pull.FileAdded},
+			{Filename: "app/owned.go", Status: pull.FileModified},
+			{Filename: "app/former.go", Status: pull.FileModified},
+		}
+		prctx.LastAuthorsValue = map[string]string{
+			"app/server.go": "comment-approver",
+			"app/client.go": "review-approver",
+			"app/new.go":    "other-user",
+			"app/owned.go":  "mhaypenny",
+			"app/former.go": "former-employee",
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Count:       1,
+				LastAuthors: true,
+				Actors: common.Actors{
+					Users: []string{"comment-approver", "review-approver", "other-user"},
+				},
+			},
+		}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		prctx.LastAuthorsValue["app/server.go"] = "other-user"
+		assertPending(t, prctx, r, "Waiting for approval from last authors: other-user")
+	})
+
+	t.Run("actorMethods", func(t *testing.T) {
+		prctx := basePullContext()
+
+		r := &Rule{
+			Options: Options{
+				Methods: &common.Methods{
+					Comments: []string{":+1:"},
+				},
+				ActorMethods: []ActorMethods{
+					{
+						Actors: common.Actors{
+							Users: []string{"review-approver"},
+						},
+						Methods: &common.Methods{
+							GithubReview: true,
+						},
+					},
+				},
+			},
+			Requires: Requires{
+				Count: 2,
+				Actors: common.Actors{
+					Users: []string{"comment-approver", "review-approver"},
+				},
+			},
+		}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		// the rule methods no longer apply to the commenter
+		r.Options.ActorMethods[0].Users = []string{"comment-approver", "review-approver"}
+		assertPending(t, prctx, r, "1/2 approvals required")
+
+		// the commenter uses the rule methods, but the reviewer does not
+		r.Options.ActorMethods[0].Users = nil
+		assertPending(t, prctx, r, "1/2 approvals required")
+
+		// comments only match the methods that count for their author
+		r.Options.ActorMethods[0].Users = []string{"review-approver"}
+		matches, err := r.CommentMatches(ctx, prctx, "comment-approver", ":+1:")
+		require.NoError(t, err)
+		assert.True(t, matches, "comment did not match the rule methods")
+
+		matches, err = r.CommentMatches(ctx, prctx, "review-approver", ":+1:")
+		require.NoError(t, err)
+		assert.False(t, matches, "comment matched methods that do not apply to the user")
+	})
+
+	t.Run("components", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.ChangedFilesValue = []*pull.File{
+			{Filename: "api/server.go", Status: pull.FileModified},
+			{Filename: "web/index.js", Status: pull.FileModified},
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Components: []Component{
+					{
+						Name:   "api",
+						Paths:  []string{"api/**"},
+						Actors: common.Actors{Users: []string{"comment-approver"}},
+					},
+					{
+						Name:   "web",
+						Paths:  []string{"web/**"},
+						Actors: common.Actors{Users: []string{"other-user"}},
+					},
+					{
+						Name:   "docs",
+						Paths:  []string{"*.md"},
+						Actors: common.Actors{Users: []string{"other-user"}},
+					},
+				},
+			},
+		}
+		assertPending(t, prctx, r, "Waiting for approval from owners of web")
+
+		res := r.Evaluate(ctx, prctx)
+		assert.Equal(t, common.ReasonRequiresComponentApproval, res.Reason)
+		if assert.Len(t, res.Children, 2, "untouched components should not have results") {
+			assert.Equal(t, "api", res.Children[0].Name)
+			assert.Equal(t, common.StatusApproved, res.Children[0].Status)
+			assert.Equal(t, "Approved by comment-approver", res.Children[0].Description)
+
+			assert.Equal(t, "web", res.Children[1].Name)
+			assert.Equal(t, common.StatusPending, res.Children[1].Status)
+			assert.Equal(t, "0/1 approvals required from owners", res.Children[1].Description)
+		}
+
+		r.Requires.Components[1].Users = []string{"review-approver"}
+		assertApproved(t, prctx, r, "Approved by the owners of all changed components")
+
+		// components are required in addition to the rule count
+		r.Requires.Count = 1
+		r.Requires.Actors = common.Actors{Users: []string{"review-approver"}}
+		r.Requires.Components[0].Count = 2
+		assertPending(t, prctx, r, "Waiting for approval from owners of api")
+	})
+
+	t.Run("quorum", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.CommentsValue = append(prctx.CommentsValue, &pull.Comment{
+			CreatedAt: now.Add(90 * time.Second),
+			Author:    "outsider",
+			Body:      ":+1:",
+		})
+
+		r := &Rule{
+			Requires: Requires{
+				Quorum: &Quorum{
+					Users: []string{"comment-approver", "review-approver", "other-user", "ttest"},
+					Count: 3,
+				},
+			},
+		}
+		assertPending(t, prctx, r, "2/3 quorum approvals from comment-approver, review-approver. 1 more required from other-user, ttest")
+
+		res := r.Evaluate(ctx, prctx)
+		assert.Equal(t, common.ReasonRequiresQuorum, res.Reason)
+		if assert.Len(t, res.Children, 4, "approvals from outside the quorum should not have results") {
+			assert.Equal(t, "comment-approver", res.Children[0].Name)
+			assert.Equal(t, common.StatusApproved, res.Children[0].Status)
+
+			assert.Equal(t, "ttest", res.Children[3].Name)
+			assert.Equal(t, common.StatusPending, res.Children[3].Status)
+			assert.Equal(t, "Not yet approved", res.Children[3].Description)
+		}
+
+		prctx.CommentsValue = append(prctx.CommentsValue, &pull.Comment{
+			CreatedAt: now.Add(100 * time.Second),
+			Author:    "other-user",
+			Body:      ":+1:",
+		})
+		assertApproved(t, prctx, r, "Approved by quorum: comment-approver, review-approver, other-user")
+
+		// the quorum is required in addition to the rule count
+		r.Requires.Count = 1
+		r.Requires.Actors = common.Actors{Users: []string{"review-approver"}}
+		r.Requires.Quorum.Count = 4
+		assertPending(t, prctx, r, "3/4 quorum approvals from comment-approver, review-approver, other-user. 1 more required from ttest")
+	})
+
+	t.Run("blockOnChangesRequested", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.CollaboratorMemberships = map[string][]string{
+			"disapprover": {"write"},
+		}
+
+		r := &Rule{
+			Options: Options{
+				BlockOnChangesRequested: true,
+			},
+			Requires: Requires{
+				Count: 1,
+				Actors: common.Actors{
+					Users: []string{"comment-approver", "review-approver"},
+				},
+			},
+		}
+		assertPending(t, prctx, r, "Waiting for disapprover to approve or dismiss requested changes")
+
+		// a later approval by the same user addresses the request
+		prctx.ReviewsValue = append(prctx.ReviewsValue, &pull.Review{
+			CreatedAt: now.Add(90 * time.Second),
+			Author:    "disapprover",
+			State:     pull.ReviewApproved,
+		})
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		// requests from users without write access are ignored
+		prctx = basePullContext()
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+	})
+
+	t.Run("approvalPool", func(t *testing.T) {
+		prctx := basePullContext()
+
+		newRule := func(name, pool string, users ...string) *Rule {
+			return &Rule{
+				Name: name,
+				Options: Options{
+					ApprovalPool: pool,
+				},
+				Requires: Requires{
+					Count: 2,
+					Actors: common.Actors{
+						Users: users,
+					},
+				},
+			}
+		}
+
+		backend := newRule("backend", "core", "comment-approver")
+		frontend := newRule("frontend", "core", "review-approver")
+		docs := newRule("docs", "", "review-approver")
+		linkApprovalPools(map[string]*Rule{
+			"backend":  backend,
+			"frontend": frontend,
+			"docs":     docs,
+		})
+
+		// each approval counts once toward every rule in the pool
+		assertApproved(t, prctx, backend, "Approved by comment-approver, review-approver")
+		assertApproved(t, prctx, frontend, "Approved by comment-approver, review-approver")
+		assertPending(t, prctx, docs, "1/2 approvals required")
+	})
+
+	t.Run("statuses", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.StatusesValue = map[string]string{
+			"security-scan": "failure",
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Statuses: []string{"security-scan"},
+			},
+		}
+		assertPending(t, prctx, r, "Waiting for status \"security-scan\" to succeed")
+
+		prctx.StatusesValue["security-scan"] = "success"
+		assertApproved(t, prctx, r, "All required statuses succeeded")
+
+		r.Requires.Statuses = append(r.Requires.Statuses, "ci/build")
+		assertPending(t, prctx, r, "Waiting for status \"ci/build\" to succeed")
+	})
+
+	t.Run("workflows", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.WorkflowRunsValue = []*pull.WorkflowRun{
+			{Workflow: "build", CreatedAt: now, Status: "completed", Conclusion: "success"},
+			{Workflow: "test", CreatedAt: now, Status: "completed", Conclusion: "failure"},
+			{Workflow: "lint", CreatedAt: now, Status: "completed", Conclusion: "skipped"},
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Workflows: &Workflows{
+					Names: []string{"build", "test", "lint"},
+				},
+			},
+		}
+		assertPending(t, prctx, r, "Waiting for workflow \"test\" to succeed, the latest run is failure")
+
+		// a re-run replaces the earlier run of the same workflow
+		prctx.WorkflowRunsValue = append(prctx.WorkflowRunsValue, &pull.WorkflowRun{
+			Workflow: "test", CreatedAt: now.Add(time.Minute), Status: "in_progress",
+		})
+		assertPending(t, prctx, r, "Waiting for workflow \"test\" to complete")
+
+		prctx.WorkflowRunsValue[3].Status = "completed"
+		prctx.WorkflowRunsValue[3].Conclusion = "success"
+		assertPending(t, prctx, r, "Waiting for workflow \"lint\" to succeed, the latest run is skipped")
+
+		r.Requires.Workflows.AllowSkipped = true
+		assertApproved(t, prctx, r, "All required workflows succeeded")
+
+		// a failed re-run replaces an earlier success
+		prctx.WorkflowRunsValue = append(prctx.WorkflowRunsValue, &pull.WorkflowRun{
+			Workflow: "build", CreatedAt: now.Add(2 * time.Minute), Status: "completed", Conclusion: "cancelled",
+		})
+		assertPending(t, prctx, r, "Waiting for workflow \"build\" to succeed, the latest run is cancelled")
+
+		r.Requires.Workflows.Names = []string{"deploy"}
+		assertPending(t, prctx, r, "Waiting for workflow \"deploy\" to run")
+
+		prctx.WorkflowRunsError = errors.New("runs failed")
+		_, _, _, err := r.IsApproved(ctx, prctx)
+		assert.EqualError(t, err, "failed to get workflow runs: runs failed")
+	})
+
+	t.Run("checkRuns", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.StatusesValue = map[string]string{
+			"policy-scan": "failure",
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Count:     1,
+				CheckRuns: []string{"policy-scan", "license-scan"},
+				Actors: common.Actors{
+					Users: []string{"nobody"},
+				},
+			},
+		}
+		assertPending(t, prctx, r, "0/1 approvals required. Ignored 5 approvals from disqualified users. Waiting for checks to succeed: policy-scan, license-scan")
+
+		res := r.Evaluate(ctx, prctx)
+		require.NoError(t, res.Error)
+		assert.Equal(t, common.StatusPending, res.Status)
+		assert.Equal(t, common.ReasonDisqualifiedApprovals, res.Reason)
+		if assert.Len(t, res.Children, 3, "incorrect number of child results") {
+			assert.Equal(t, "Approvals", res.Children[0].Name)
+			assert.Equal(t, common.StatusPending, res.Children[0].Status)
+			assert.Equal(t, "0/1 approvals required. Ignored 5 approvals from disqualified users", res.Children[0].Description)
+
+			assert.Equal(t, "policy-scan", res.Children[1].Name)
+			assert.Equal(t, common.StatusPending, res.Children[1].Status)
+			assert.Equal(t, common.ReasonPendingStatus, res.Children[1].Reason)
+			assert.Equal(t, "Waiting for the check to succeed, the current state is failure", res.Children[1].Description)
+
+			assert.Equal(t, "license-scan", res.Children[2].Name)
+			assert.Equal(t, "Waiting for the check to run", res.Children[2].Description)
+		}
+
+		r.Requires.Users = []string{"comment-approver"}
+		assertPending(t, prctx, r, "Waiting for checks to succeed: policy-scan, license-scan")
+
+		res = r.Evaluate(ctx, prctx)
+		assert.Equal(t, common.ReasonPendingStatus, res.Reason)
+		if assert.Len(t, res.Children, 3, "incorrect number of child results") {
+			assert.Equal(t, common.StatusApproved, res.Children[0].Status)
+			assert.Equal(t, "Approved by comment-approver", res.Children[0].Description)
+		}
+
+		prctx.StatusesValue["policy-scan"] = "success"
+		prctx.StatusesValue["license-scan"] = "success"
+		assertApproved(t, prctx, r, "Approved by comment-approver. All required checks succeeded")
+
+		res = r.Evaluate(ctx, prctx)
+		assert.Equal(t, common.StatusApproved, res.Status)
+		if assert.Len(t, res.Children, 3, "incorrect number of child results") {
+			for _, c := range res.Children {
+				assert.Equal(t, common.StatusApproved, c.Status, "child %s was not approved", c.Name)
+			}
+			assert.Equal(t, "Succeeded", res.Children[1].Description)
+		}
+
+		// approvals keep their own children, like components
+		r.Requires.Components = []Component{
+			{Name: "app", Paths: []string{"app/**"}, Actors: common.Actors{Users: []string{"review-approver"}}},
+		}
+		prctx.ChangedFilesValue = []*pull.File{{Filename: "app/main.go"}}
+		res = r.Evaluate(ctx, prctx)
+		if assert.Len(t, res.Children, 3, "incorrect number of child results") {
+			if assert.Len(t, res.Children[0].Children, 1, "incorrect number of component results") {
+				assert.Equal(t, "app", res.Children[0].Children[0].Name)
+			}
+		}
+
+		prctx.StatusesError = errors.New("statuses failed")
+		_, _, _, err := r.IsApproved(ctx, prctx)
+		assert.EqualError(t, err, "failed to get commit statuses: statuses failed")
+	})
+
+	t.Run("reasons", func(t *testing.T) {
+		assertReason := func(t *testing.T, prctx pull.Context, r *Rule, expected common.Reason) {
+			_, _, reason, err := r.IsApproved(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, expected, reason)
+		}
+
+		prctx := basePullContext()
+
+		r := &Rule{
+			Requires: Requires{
+				Count: 1,
+				Actors: common.Actors{
+					Users: []string{"comment-approver"},
+				},
+			},
+		}
+		assertReason(t, prctx, r, "")
+
+		r.Requires.Count = 2
+		assertReason(t, prctx, r, common.ReasonInsufficientApprovals)
+
+		r.Requires.Users = []string{"nobody"}
+		assertReason(t, prctx, r, common.ReasonDisqualifiedApprovals)
+
+		r.Requires.Statuses = []string{"ci/build"}
+		assertReason(t, prctx, r, common.ReasonPendingStatus)
+
+		r.Requires.ExternalChecks = []string{"cla"}
+		assertReason(t, prctx, r, common.ReasonPendingExternalCheck)
+
+		r.Requires.ExternalChecks = nil
+		r.Requires.Statuses = nil
+		r.Requires.Workflows = &Workflows{Names: []string{"ci"}}
+		assertReason(t, prctx, r, common.ReasonPendingWorkflow)
+
+		r.Requires.Workflows = nil
+		r.Requires.TemplateSections = true
+		prctx.PullRequestTemplateValue = "## Testing\n"
+		assertReason(t, prctx, r, common.ReasonRequiresTemplateSections)
+	})
+
+	t.Run("weightedComments", func(t *testing.T) {
+		prctx := basePullContext()
+
+		r := &Rule{
+			Options: Options{
+				Methods: &common.Methods{
+					Comments: []string{":+1:"},
+					WeightedComments: []common.WeightedComment{
+						{Pattern: ":shipit:", Weight: 3},
+					},
+					GithubReview: true,
+				},
+			},
+			Requires: Requires{
+				Count: 4,
+				Actors: common.Actors{
+					Users: []string{"comment-approver", "review-approver"},
+				},
+			},
+		}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		r.Requires.MaxWeightPerUser = 2
+		assertPending(t, prctx, r, "3/4 approvals required")
+
+		// repeated weighted comments by the same user do not stack
+		prctx.CommentsValue = append(prctx.CommentsValue, &pull.Comment{
+			CreatedAt: now.Add(25 * time.Second),
+			Author:    "comment-approver",
+			Body:      ":shipit: :shipit:",
+		})
+		assertPending(t, prctx, r, "3/4 approvals required")
+
+		r.Requires.Count = 3
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+	})
+
+	t.Run("approverWeights", func(t *testing.T) {
+		prctx := basePullContext()
+
+		r := &Rule{
+			Requires: Requires{
+				Count: 2,
+				Actors: common.Actors{
+					Users: []string{"review-approver"},
+				},
+			},
+		}
+		assertPending(t, prctx, r, "1/2 approvals required")
+
+		r.Requires.ApproverWeights = []ApproverWeight{
 			{
-				PushedAt:  newTime(now.Add(85 * time.Second)),
-				SHA:       "c6ade256ecfc755d8bc877ef22cc9e01745d46bb",
-				Author:    "mhaypenny",
-				Committer: "mhaypenny",
+				Actors: common.Actors{Users: []string{"comment-approver"}},
+				Weight: 3,
+			},
+			{
+				Actors: common.Actors{Organizations: []string{"even-cooler-org"}},
+				Weight: 2,
+			},
+		}
+		assertApproved(t, prctx, r, "Approved by review-approver")
+
+		r.Requires.MaxWeightPerUser = 1
+		assertPending(t, prctx, r, "1/2 approvals required")
+	})
+
+	t.Run("minApproverContributions", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.MergedPullCountValues = map[string]int{
+			"comment-approver": 3,
+			"review-approver":  0,
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Count:                    2,
+				MinApproverContributions: 3,
+				Actors: common.Actors{
+					Organizations: []string{"everyone"},
+				},
 			},
 		}
 
+		// the first-time contributor's approval does not count
+		assertPending(t, prctx, r, "1/2 approvals required")
+
+		prctx.MergedPullCountValues["review-approver"] = 3
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		// the minimum also applies to quorum members
+		prctx.MergedPullCountValues["review-approver"] = 0
+		r.Requires.Count = 0
+		r.Requires.Quorum = &Quorum{
+			Users: []string{"comment-approver", "review-approver"},
+			Count: 2,
+		}
+		assertPending(t, prctx, r, "1/2 quorum approvals from comment-approver. 1 more required from review-approver")
+	})
+
+	t.Run("inlineComments", func(t *testing.T) {
+		prctx := basePullContext()
+
 		r := &Rule{
 			Requires: Requires{
 				Count: 1,
 				Actors: common.Actors{
-					Users: []string{"review-approver"},
+					Organizations: []string{"everyone"},
 				},
+				InlineComments: true,
 			},
 		}
+
+		// approvals without inline comments do not count
+		assertPending(t, prctx, r, "0/1 approvals required. Ignored 5 approvals from disqualified users")
+
+		prctx.ReviewCommentsValue = []*pull.ReviewComment{
+			{Author: "review-approver", Path: "app.go", Outdated: true},
+			{Author: "disapprover", Path: "app.go"},
+		}
 		assertApproved(t, prctx, r, "Approved by review-approver")
 
-		r.Options.InvalidateOnPush = true
-		assertPending(t, prctx, r, "0/1 approvals required")
+		r.Requires.Count = 2
+		assertPending(t, prctx, r, "1/2 approvals required")
+
+		// approvals without inline comments do not satisfy the quorum
+		r.Requires.Count = 0
+		r.Requires.Quorum = &Quorum{
+			Users: []string{"comment-approver", "review-approver"},
+			Count: 2,
+		}
+		assertPending(t, prctx, r, "1/2 quorum approvals from review-approver. 1 more required from comment-approver")
+
+		prctx.ReviewCommentsError = errors.New("comments failed")
+		_, _, _, err := r.IsApproved(ctx, prctx)
+		assert.EqualError(t, err, "failed to list review comments: comments failed")
+	})
+
+	t.Run("overdueAfter", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.CreatedAtValue = now
+
+		r := &Rule{
+			Options: Options{
+				OverdueAfter: 48 * time.Hour,
+			},
+			Requires: Requires{
+				Count: 3,
+				Actors: common.Actors{
+					Organizations: []string{"everyone"},
+				},
+			},
+			now: func() time.Time { return now.Add(47 * time.Hour) },
+		}
+
+		res := r.Evaluate(ctx, prctx)
+		require.NoError(t, res.Error)
+		assert.Equal(t, common.StatusPending, res.Status)
+		assert.Equal(t, common.ReasonInsufficientApprovals, res.Reason)
+
+		r.now = func() time.Time { return now.Add(48 * time.Hour) }
+		deadline := now.Add(48 * time.Hour).UTC().Format("2006-01-02 15:04 MST")
+
+		res = r.Evaluate(ctx, prctx)
+		require.NoError(t, res.Error)
+		assert.Equal(t, common.StatusPending, res.Status)
+		assert.Equal(t, common.ReasonInsufficientApprovals, res.Reason)
+		assert.True(t, res.Overdue, "rule was not overdue")
+		assert.Equal(t, "2/3 approvals required. Overdue since "+deadline, res.Description)
+
+		// approved rules are never overdue
+		r.Requires.Count = 2
+		res = r.Evaluate(ctx, prctx)
+		require.NoError(t, res.Error)
+		assert.Equal(t, common.StatusApproved, res.Status)
+		assert.Equal(t, common.Reason(""), res.Reason)
+	})
+
+	t.Run("selfDismissalWindow", func(t *testing.T) {
+		prctx := basePullContext()
+
+		r := &Rule{
+			Options: Options{
+				SelfDismissalWindow: 10 * time.Minute,
+			},
+			Requires: Requires{
+				Count: 2,
+				Actors: common.Actors{
+					Organizations: []string{"everyone"},
+				},
+			},
+		}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		// the approver dismissed their own review and immediately approved again
+		prctx.ReviewDismissalsValue = []*pull.ReviewDismissal{
+			{CreatedAt: now.Add(60 * time.Second), Actor: "review-approver", Reviewer: "review-approver"},
+		}
+		assertPending(t, prctx, r, "1/2 approvals required")
+
+		// dismissals by other users and old dismissals are not suspect
+		prctx.ReviewDismissalsValue = []*pull.ReviewDismissal{
+			{CreatedAt: now.Add(60 * time.Second), Actor: "repo-admin", Reviewer: "review-approver"},
+			{CreatedAt: now.Add(-20 * time.Minute), Actor: "review-approver", Reviewer: "review-approver"},
+			{CreatedAt: now.Add(90 * time.Second), Actor: "review-approver", Reviewer: "review-approver"},
+		}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		// comment approvals are never suspect
+		prctx.ReviewDismissalsValue = []*pull.ReviewDismissal{
+			{CreatedAt: now, Actor: "comment-approver", Reviewer: "comment-approver"},
+		}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		prctx.ReviewDismissalsError = errors.New("dismissals failed")
+		_, _, _, err := r.IsApproved(ctx, prctx)
+		assert.EqualError(t, err, "failed to get review dismissals: dismissals failed")
+	})
+
+	t.Run("coolingOffPeriod", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.CreatedAtValue = now
+
+		r := &Rule{
+			Requires: Requires{
+				Count:            1,
+				CoolingOffPeriod: 80 * time.Second,
+				Actors: common.Actors{
+					Organizations: []string{"everyone"},
+				},
+			},
+		}
+
+		// the review is submitted exactly when the period ends
+		assertApproved(t, prctx, r, "Approved by review-approver")
+
+		r.Requires.CoolingOffPeriod = 81 * time.Second
+		r.now = func() time.Time { return now.Add(80 * time.Second) }
+		end := now.Add(81 * time.Second).UTC().Format("15:04 MST")
+		assertPending(t, prctx, r, "0/1 approvals required. Approvals count after the cooling-off period ends at "+end)
+
+		// approvals submitted during the period still do not count
+		r.now = func() time.Time { return now.Add(81 * time.Second) }
+		assertPending(t, prctx, r, "0/1 approvals required. Ignored 5 approvals from disqualified users")
+
+		prctx.CommentsValue = append(prctx.CommentsValue, &pull.Comment{
+			CreatedAt: now.Add(90 * time.Second),
+			Author:    "comment-approver",
+			Body:      ":+1: still good",
+		})
+		assertApproved(t, prctx, r, "Approved by comment-approver")
+	})
+
+	t.Run("independentApprovers", func(t *testing.T) {
+		prctx := basePullContext()
+
+		r := &Rule{
+			Requires: Requires{
+				Count:                2,
+				IndependentApprovers: &IndependentApprovers{},
+				Actors: common.Actors{
+					Organizations: []string{"everyone"},
+				},
+			},
+		}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		// the author and comment-approver only approve each other
+		prctx.RecentApprovalsValue = []*pull.PullApprovals{
+			{Number: 1, Author: "mhaypenny", Approvers: []string{"comment-approver", "mhaypenny"}},
+			{Number: 2, Author: "comment-approver", Approvers: []string{"mhaypenny"}},
+		}
+		assertPending(t, prctx, r, "1/2 approvals required")
+
+		prctx.RecentApprovalsValue = append(prctx.RecentApprovalsValue, &pull.PullApprovals{
+			Number: 3, Author: "comment-approver", Approvers: []string{"other-user"},
+		})
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		// the two approvers only approve each other, so only the first counts
+		prctx.RecentApprovalsValue = []*pull.PullApprovals{
+			{Number: 1, Author: "review-approver", Approvers: []string{"comment-approver"}},
+			{Number: 2, Author: "comment-approver", Approvers: []string{"review-approver"}},
+			{Number: 3, Author: "comment-approver", Approvers: []string{"review-approver"}},
+		}
+		assertPending(t, prctx, r, "1/2 approvals required")
+
+		prctx.RecentApprovalsError = errors.New("history failed")
+		_, _, _, err := r.IsApproved(ctx, prctx)
+		assert.EqualError(t, err, "failed to get recent approvals: history failed")
+	})
+
+	t.Run("sizeTiers", func(t *testing.T) {
+		prctx := basePullContext()
+
+		r := &Rule{
+			Requires: Requires{
+				SizeTiers: []SizeTier{
+					{Name: "trivial", Lines: 10, Files: 1, Count: 0},
+					{Name: "small", Lines: 100, Count: 1},
+					{Name: "medium", Lines: 500, Files: 10, Count: 2},
+					{Name: "large", Count: 3},
+				},
+				Actors: common.Actors{
+					Organizations: []string{"everyone"},
+				},
+			},
+		}
+
+		files := func(n, lines int) []*pull.File {
+			var fs []*pull.File
+			for i := 0; i < n; i++ {
+				fs = append(fs, &pull.File{Filename: fmt.Sprintf("file%d.go", i), Additions: lines / n})
+			}
+			return fs
+		}
+
+		prctx.ChangedFilesValue = files(1, 10)
+		assertApproved(t, prctx, r, "No approval required for the trivial size tier (10 modified lines, 1 changed file)")
+
+		prctx.ChangedFilesValue = files(1, 11)
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver for the small size tier (11 modified lines, 1 changed file)")
+
+		prctx.ChangedFilesValue = files(2, 10)
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver for the small size tier (10 modified lines, 2 changed files)")
+
+		prctx.ChangedFilesValue = files(4, 100)
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver for the small size tier (100 modified lines, 4 changed files)")
+
+		prctx.ChangedFilesValue = append(files(4, 100), &pull.File{Filename: "removed.go", Deletions: 1})
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver for the medium size tier (101 modified lines, 5 changed files)")
+
+		prctx.ChangedFilesValue = files(10, 500)
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver for the medium size tier (500 modified lines, 10 changed files)")
+
+		prctx.ChangedFilesValue = files(11, 110)
+		assertPending(t, prctx, r, "2/3 approvals required for the large size tier (110 modified lines, 11 changed files)")
+
+		prctx.ChangedFilesValue = files(5, 505)
+		assertPending(t, prctx, r, "2/3 approvals required for the large size tier (505 modified lines, 5 changed files)")
+
+		// pull requests larger than every tier use the last tier
+		r.Requires.SizeTiers = r.Requires.SizeTiers[:2]
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver for the small size tier (505 modified lines, 5 changed files)")
+
+		prctx.ChangedFilesError = errors.New("files failed")
+		_, _, _, err := r.IsApproved(ctx, prctx)
+		assert.EqualError(t, err, "failed to list changed files: files failed")
+	})
+
+	t.Run("requestedReviewers", func(t *testing.T) {
+		prctx := basePullContext()
+
+		// the history includes requests that were removed after the review
+		prctx.ReviewRequestHistoryValue = &pull.ReviewRequests{
+			Users: []string{"comment-approver"},
+		}
+
+		r := &Rule{
+			Requires: Requires{
+				Count:              2,
+				RequestedReviewers: true,
+				Actors: common.Actors{
+					Organizations: []string{"everyone"},
+				},
+			},
+		}
+
+		// review-approver was never requested
+		assertPending(t, prctx, r, "1/2 approvals required")
+
+		// members of requested teams were requested
+		prctx.ReviewRequestHistoryValue.Teams = []string{"testorg/reviewers"}
+		prctx.AddTeamMember("testorg/reviewers", "review-approver")
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		r.Requires.RequestedReviewers = false
+		prctx.ReviewRequestHistoryValue = &pull.ReviewRequests{}
+		assertApproved(t, prctx, r, "Approved by comment-approver, review-approver")
+
+		r.Requires.RequestedReviewers = true
+		assertPending(t, prctx, r, "0/2 approvals required. Ignored 5 approvals from disqualified users")
+
+		// quorum members who were not requested do not count
+		prctx.ReviewRequestHistoryValue = &pull.ReviewRequests{
+			Users: []string{"review-approver"},
+		}
+		r.Requires.Count = 0
+		r.Requires.Quorum = &Quorum{
+			Users: []string{"comment-approver", "review-approver"},
+			Count: 2,
+		}
+		assertPending(t, prctx, r, "1/2 quorum approvals from review-approver. 1 more required from comment-approver")
+
+		prctx.ReviewRequestHistoryValue.Users = append(prctx.ReviewRequestHistoryValue.Users, "comment-approver")
+		assertApproved(t, prctx, r, "Approved by quorum: comment-approver, review-approver")
+
+		prctx.ReviewRequestHistoryError = errors.New("history failed")
+		_, _, _, err := r.IsApproved(ctx, prctx)
+		assert.EqualError(t, err, "failed to get review request history: history failed")
+	})
+
+	t.Run("templateSections", func(t *testing.T) {
+		prctx := basePullContext()
+
+		r := &Rule{
+			Requires: Requires{
+				Count: 1,
+				Actors: common.Actors{
+					Users: []string{"comment-approver"},
+				},
+				TemplateSections: true,
+			},
+		}
+
+		// repositories without a template have no sections to complete
+		assertApproved(t, prctx, r, "Approved by comment-approver")
+
+		prctx.PullRequestTemplateValue = "## Summary\n\n<!-- What does this change? -->\n\n## Risks\n\n- None\n\n## Testing\n"
+		prctx.BodyValue = "Fixes a bug"
+		assertPending(t, prctx, r, "Waiting for the description to complete template sections: Summary, Risks, Testing")
+
+		prctx.BodyValue = "## Summary\n\nFixes a bug\n\n## Risks\n\n- None\n\n## Testing\n\n<!-- TODO -->\n"
+		assertPending(t, prctx, r, "Waiting for the description to complete template sections: Risks, Testing")
+
+		prctx.BodyValue = "## Summary\r\nFixes a bug\r\n## Risks\r\nNone\r\n### Testing ###\r\nAdded unit tests\r\n"
+		assertApproved(t, prctx, r, "Approved by comment-approver")
+
+		prctx.PullRequestTemplateError = errors.New("template failed")
+		_, _, _, err := r.IsApproved(ctx, prctx)
+		assert.EqualError(t, err, "failed to get pull request template: template failed")
 	})
 
 	t.Run("ignoreUpdateMergeAfterReview", func(t *testing.T) {
@@ -371,3 +1629,142 @@ func TestIsApproved(t *testing.T) {
 func newTime(t time.Time) *time.Time {
 	return &t
 }
+
+func TestIsWhitespaceOnly(t *testing.T) {
+	tests := map[string]struct {
+		File     *pull.File
+		Expected bool
+	}{
+		"trailingWhitespace": {
+			&pull.File{Status: pull.FileModified, Patch: "@@ -1 +1 @@\n-a = 1  \n+a = 1"},
+			true,
+		},
+		"indentation": {
+			&pull.File{Status: pull.FileModified, Patch: "@@ -1,2 +1,2 @@\n if x:\n-\treturn\n+    return"},
+			false,
+		},
+		"innerWhitespace": {
+			&pull.File{Status: pull.FileModified, Patch: "@@ -1 +1 @@\n-s = \"a  b\"\n+s = \"a b\""},
+			false,
+		},
+		"lineEndings": {
+			&pull.File{Status: pull.FileModified, Patch: "@@ -1 +1 @@\n-a = 1\r\n+a = 1\n\\ No newline at end of file"},
+			true,
+		},
+		"blankLines": {
+			&pull.File{Status: pull.FileModified, Patch: "@@ -1,2 +1,3 @@\n a = 1\n+\n b = 2"},
+			true,
+		},
+		"contentChange": {
+			&pull.File{Status: pull.FileModified, Patch: "@@ -1 +1 @@\n-a = 1\n+a = 2"},
+			false,
+		},
+		"joinedTokens": {
+			&pull.File{Status: pull.FileModified, Patch: "@@ -1 +1 @@\n-a b\n+ab"},
+			false,
+		},
+		"movedLine": {
+			&pull.File{Status: pull.FileModified, Patch: "@@ -1,3 +1,3 @@\n-a = 1\n b = 2\n+a = 1"},
+			false,
+		},
+		"addedFile": {
+			&pull.File{Status: pull.FileAdded, Patch: "@@ -0,0 +1 @@\n+\n"},
+			false,
+		},
+		"missingPatch": {
+			&pull.File{Status: pull.FileModified},
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, isWhitespaceOnly([]*pull.File{test.File}))
+		})
+	}
+}
+
+func TestPredicateCommits(t *testing.T) {
+	ctx := context.Background()
+
+	basePullContext := func() *pulltest.Context {
+		return &pulltest.Context{
+			AuthorValue: "mhaypenny",
+			NumberValue: 7,
+			TitleValue:  "Add feature",
+			BodyValue:   "Fixes #1",
+			CommitsValue: []*pull.Commit{
+				{SHA: "1", Author: "mhaypenny", AuthorEmail: "mhaypenny@gmail.com", Message: "WIP"},
+				{SHA: "2", Author: "mhaypenny", AuthorEmail: "mhaypenny@palantir.com", Message: "fix typo"},
+			},
+		}
+	}
+
+	r := &Rule{
+		Predicates: Predicates{
+			CommitAuthorEmail: &predicate.CommitAuthorEmail{
+				Patterns: []string{`.*@palantir\.com`},
+				MatchAll: true,
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		Mode     string
+		Methods  *pull.MergeMethods
+		Expected common.EvaluationStatus
+	}{
+		"allCommits":         {"", &pull.MergeMethods{Squash: true}, common.StatusSkipped},
+		"squash":             {PredicateCommitsSquash, &pull.MergeMethods{Merge: true}, common.StatusApproved},
+		"autoSquashOnlyRepo": {PredicateCommitsAuto, &pull.MergeMethods{Squash: true}, common.StatusApproved},
+		"autoMergeRepo":      {PredicateCommitsAuto, &pull.MergeMethods{Merge: true, Squash: true}, common.StatusSkipped},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prctx := basePullContext()
+			prctx.MergeMethodsValue = test.Methods
+			r.Options.PredicateCommits = test.Mode
+
+			res := r.Evaluate(ctx, prctx)
+			require.NoError(t, res.Error)
+			assert.Equal(t, test.Expected, res.Status)
+		})
+	}
+
+	t.Run("squashCommit", func(t *testing.T) {
+		commits, err := squashContext{basePullContext()}.MessageCommits()
+		require.NoError(t, err)
+
+		require.Len(t, commits, 1)
+		assert.Equal(t, "mhaypenny", commits[0].Author)
+		assert.Equal(t, "mhaypenny@palantir.com", commits[0].AuthorEmail)
+		assert.Equal(t, "Add feature (#7)\n\nFixes #1", commits[0].Message)
+		assert.True(t, commits[0].CommittedViaWeb)
+	})
+
+	t.Run("squashContributors", func(t *testing.T) {
+		prctx := basePullContext()
+		prctx.CommitsValue = append(prctx.CommitsValue, &pull.Commit{
+			SHA:         "3",
+			Author:      "outsider",
+			Committer:   "outsider",
+			AuthorEmail: "outsider@palantir.com",
+		})
+
+		onlyAuthor := predicate.AuthorIsOnlyContributor(true)
+		r := &Rule{
+			Options: Options{
+				PredicateCommits: PredicateCommitsSquash,
+			},
+			Predicates: Predicates{
+				AuthorIsOnlyContributor: &onlyAuthor,
+			},
+		}
+
+		// contributors who pushed commits are not hidden by the squash commit
+		res := r.Evaluate(ctx, prctx)
+		require.NoError(t, res.Error)
+		assert.Equal(t, common.StatusSkipped, res.Status)
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/pull"
+)
+
+// changeRequesters returns the users whose most recent review requested
+// changes. Like branch protection, requests from users who no longer have
+// write access to the repository are ignored.
+func changeRequesters(ctx context.Context, prctx pull.Context) ([]string, error) {
+	log := zerolog.Ctx(ctx)
+
+	reviews, err := prctx.Reviews()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list reviews")
+	}
+
+	var requesters []string
+	for _, u := range common.ChangeRequesters(reviews) {
+		if u == prctx.Author() {
+			continue
+		}
+
+		hasWrite, err := hasWriteAccess(prctx, u)
+		if err != nil {
+			return nil, err
+		}
+		if !hasWrite {
+			log.Debug().Str("user", u).Msg("ignoring request for changes by user without write access")
+			continue
+		}
+		requesters = append(requesters, u)
+	}
+	return requesters, nil
+}
+
+func hasWriteAccess(prctx pull.Context, user string) (bool, error) {
+	for _, perm := range []string{common.GithubAdminPermission, common.GithubWritePermission} {
+		ok, err := prctx.IsCollaborator(prctx.RepositoryOwner(), prctx.RepositoryName(), user, perm)
+		if err != nil {
+			return false, errors.Wrap(err, "failed to check repository permission")
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
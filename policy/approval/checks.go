@@ -0,0 +1,86 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/pull"
+)
+
+// withCheckRuns combines the approval status of a rule with the status of its
+// check runs. It returns a result for the approvals, with the given children,
+// followed by a result for each check run.
+func (req *Requires) withCheckRuns(ctx context.Context, prctx pull.Context, approved bool, msg string, reason common.Reason, children []*common.Result) (bool, string, common.Reason, []*common.Result, error) {
+	log := zerolog.Ctx(ctx)
+
+	statuses, err := prctx.Statuses()
+	if err != nil {
+		return false, "", "", nil, errors.Wrap(err, "failed to get commit statuses")
+	}
+
+	approvals := &common.Result{
+		Name:        "Approvals",
+		Description: msg,
+		Status:      common.StatusApproved,
+		Reason:      reason,
+		Children:    children,
+	}
+	if !approved {
+		approvals.Status = common.StatusPending
+	}
+	results := []*common.Result{approvals}
+
+	var pending []string
+	for _, name := range req.CheckRuns {
+		res := &common.Result{
+			Name: name,
+		}
+		switch state := statuses[name]; state {
+		case "success":
+			res.Status = common.StatusApproved
+			res.Description = "Succeeded"
+		case "":
+			res.Status = common.StatusPending
+			res.Description = "Waiting for the check to run"
+			res.Reason = common.ReasonPendingStatus
+		default:
+			res.Status = common.StatusPending
+			res.Description = fmt.Sprintf("Waiting for the check to succeed, the current state is %s", state)
+			res.Reason = common.ReasonPendingStatus
+		}
+		if res.Status != common.StatusApproved {
+			log.Debug().Msgf("check run %q is %q", name, statuses[name])
+			pending = append(pending, name)
+		}
+		results = append(results, res)
+	}
+
+	switch {
+	case approved && len(pending) == 0:
+		return true, msg + ". All required checks succeeded", "", results, nil
+	case approved:
+		return false, fmt.Sprintf("Waiting for checks to succeed: %s", strings.Join(pending, ", ")), common.ReasonPendingStatus, results, nil
+	case len(pending) > 0:
+		return false, fmt.Sprintf("%s. Waiting for checks to succeed: %s", msg, strings.Join(pending, ", ")), reason, results, nil
+	}
+	return false, msg, reason, results, nil
+}
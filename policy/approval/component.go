@@ -0,0 +1,117 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/policy/predicate"
+	"github.com/palantir/policy-bot/pull"
+)
+
+// Component is a set of files with owners who must approve changes to them.
+type Component struct {
+	Name string `yaml:"name"`
+
+	// Paths are patterns, using .gitattributes syntax, for the files in the
+	// component.
+	Paths []string `yaml:"paths"`
+
+	// Count is the number of approvals required from owners. If zero, one
+	// approval is required.
+	Count int `yaml:"count"`
+
+	common.Actors `yaml:",inline"`
+}
+
+func (c *Component) requiredApprovals() int {
+	if c.Count < 1 {
+		return 1
+	}
+	return c.Count
+}
+
+// componentResults returns a result for each component with changed files and
+// the names of components that are not approved. Users are the candidates
+// that may approve, in order, and each counts once toward each component they
+// own.
+func (r *Rule) componentResults(ctx context.Context, prctx pull.Context, users []string) ([]*common.Result, []string, error) {
+	if len(r.Requires.Components) == 0 {
+		return nil, nil, nil
+	}
+
+	files, err := prctx.ChangedFiles()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list changed files")
+	}
+
+	var results []*common.Result
+	var pending []string
+	for i := range r.Requires.Components {
+		c := &r.Requires.Components[i]
+
+		paths, err := predicate.GlobsToRegexps(c.Paths)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse paths of component %q", c.Name)
+		}
+
+		if !hasMatchingFile(paths, files) {
+			continue
+		}
+
+		var owners []string
+		for _, u := range users {
+			isOwner, err := c.IsActor(ctx, prctx, u)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to check owners of component %q", c.Name)
+			}
+			if isOwner {
+				owners = append(owners, u)
+			}
+		}
+
+		res := &common.Result{
+			Name: c.Name,
+		}
+		if len(owners) >= c.requiredApprovals() {
+			res.Status = common.StatusApproved
+			res.Description = fmt.Sprintf("Approved by %s", strings.Join(owners, ", "))
+		} else {
+			res.Status = common.StatusPending
+			res.Reason = common.ReasonInsufficientApprovals
+			res.Description = fmt.Sprintf("%d/%d approvals required from owners", len(owners), c.requiredApprovals())
+			pending = append(pending, c.Name)
+		}
+		results = append(results, res)
+	}
+	return results, pending, nil
+}
+
+func hasMatchingFile(paths []*regexp.Regexp, files []*pull.File) bool {
+	for _, f := range files {
+		for _, p := range paths {
+			if p.MatchString(f.Filename) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// uncoveredFiles returns the names of changed files that no approver left a
+// review comment on. GitHub only associates comments with files that have a
+// diff, so files without one, like binary files or files with diffs too large
+// to display, are covered by any approval.
+func uncoveredFiles(prctx pull.Context, approvers []string) ([]string, error) {
+	files, err := prctx.ChangedFiles()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list changed files")
+	}
+
+	comments, err := prctx.ReviewComments()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list review comments")
+	}
+
+	isApprover := make(map[string]bool)
+	for _, a := range approvers {
+		isApprover[a] = true
+	}
+
+	covered := make(map[string]bool)
+	for _, c := range comments {
+		if isApprover[c.Author] {
+			covered[c.Path] = true
+		}
+	}
+
+	var uncovered []string
+	for _, f := range files {
+		if f.Patch != "" && !covered[f.Filename] {
+			uncovered = append(uncovered, f.Filename)
+		}
+	}
+	return uncovered, nil
+}
+
+// inlineCommenters returns the users who left at least one review comment on
+// the diff of the pull request, including comments that are now outdated.
+func inlineCommenters(prctx pull.Context) (map[string]bool, error) {
+	comments, err := prctx.ReviewComments()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list review comments")
+	}
+
+	commenters := make(map[string]bool)
+	for _, c := range comments {
+		commenters[c.Author] = true
+	}
+	return commenters, nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// hasOutsideApprover returns true if any approver does not share a department
+// with the author. If the author is not in any department, every approver is
+// outside of the author's departments.
+func hasOutsideApprover(ctx context.Context, prctx pull.Context, author string, approvers []string) (bool, error) {
+	log := zerolog.Ctx(ctx)
+
+	org := prctx.RepositoryOwner()
+	cache := make(map[string]map[string]bool)
+
+	authorDepts, err := departments(prctx, org, author, cache)
+	if err != nil {
+		return false, err
+	}
+
+	for _, approver := range approvers {
+		depts, err := departments(prctx, org, approver, cache)
+		if err != nil {
+			return false, err
+		}
+
+		shared := false
+		for d := range depts {
+			if authorDepts[d] {
+				shared = true
+				break
+			}
+		}
+		if !shared {
+			return true, nil
+		}
+		log.Debug().Str("user", approver).Msgf("approver shares a department with %s", author)
+	}
+	return false, nil
+}
+
+// departments returns the top-level teams in the organization that contain
+// the user, either directly or through any nested team. Team membership
+// includes the members of child teams, so only top-level teams are checked.
+// Results are stored in the cache by user.
+func departments(prctx pull.Context, org, user string, cache map[string]map[string]bool) (map[string]bool, error) {
+	if depts, ok := cache[user]; ok {
+		return depts, nil
+	}
+
+	teams, err := prctx.Teams(org)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list organization teams")
+	}
+
+	depts := make(map[string]bool)
+	for _, team := range teams {
+		parent, err := prctx.TeamParent(team)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get parent of team %s", team)
+		}
+		if parent != "" {
+			continue
+		}
+
+		isMember, err := prctx.IsTeamMember(team, user)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check team membership")
+		}
+		if isMember {
+			depts[team] = true
+		}
+	}
+
+	cache[user] = depts
+	return depts, nil
+}
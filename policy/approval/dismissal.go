@@ -0,0 +1,47 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"time"
+
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/pull"
+)
+
+// selfDismissal returns the dismissal that made the review candidate
+// suspect: a dismissal of a review by the candidate's user, performed by the
+// same user, less than window before the candidate was submitted. It returns
+// nil if the candidate is not a review or no such dismissal exists.
+func selfDismissal(dismissals []*pull.ReviewDismissal, c *common.Candidate, window time.Duration) *pull.ReviewDismissal {
+	if c.Type != common.ReviewCandidate {
+		return nil
+	}
+
+	var last *pull.ReviewDismissal
+	for _, d := range dismissals {
+		if d.Reviewer != c.User || d.Actor != c.User || d.CreatedAt.After(c.CreatedAt) {
+			continue
+		}
+		if last == nil || d.CreatedAt.After(last.CreatedAt) {
+			last = d
+		}
+	}
+
+	if last != nil && c.CreatedAt.Sub(last.CreatedAt) < window {
+		return last
+	}
+	return nil
+}
@@ -70,7 +70,7 @@ func (r *OrRequirement) Evaluate(ctx context.Context, prctx pull.Context) common
 	}
 
 	var err error
-	var pending, approved, skipped int
+	var pending, approved, skipped, overdue int
 	for _, c := range children {
 		if c.Error != nil {
 			err = c.Error
@@ -82,28 +82,43 @@ func (r *OrRequirement) Evaluate(ctx context.Context, prctx pull.Context) common
 			approved++
 		case common.StatusPending:
 			pending++
+			if c.Overdue {
+				overdue++
+			}
 		case common.StatusSkipped:
 			skipped++
 		}
 	}
 
 	var status common.EvaluationStatus
+	reason := common.ReasonRulesSkipped
 	description := "All of the rules are skipped"
 
 	switch {
 	case approved > 0:
 		status = common.StatusApproved
+		reason = ""
 		description = "One or more rules approved"
 		err = nil
 	case pending > 0:
 		status = common.StatusPending
+		reason = common.ReasonRulesPending
 		description = "None of the rules are satisfied"
 		err = nil
 	}
 
+	if status == common.StatusPending && overdue > 0 {
+		description = fmt.Sprintf("%s, %d overdue", description, overdue)
+	}
+
+	if err != nil {
+		reason = common.ReasonError
+	}
+
 	return common.Result{
 		Name:        "or",
 		Status:      status,
+		Reason:      reason,
 		Description: description,
 		Error:       err,
 		Children:    children,
@@ -122,7 +137,7 @@ func (r *AndRequirement) Evaluate(ctx context.Context, prctx pull.Context) commo
 	}
 
 	var err error
-	var pending, approved, skipped int
+	var pending, approved, skipped, overdue int
 	for _, c := range children {
 		if c.Error != nil {
 			err = c.Error
@@ -134,26 +149,41 @@ func (r *AndRequirement) Evaluate(ctx context.Context, prctx pull.Context) commo
 			approved++
 		case common.StatusPending:
 			pending++
+			if c.Overdue {
+				overdue++
+			}
 		case common.StatusSkipped:
 			skipped++
 		}
 	}
 
 	var status common.EvaluationStatus
+	reason := common.ReasonRulesSkipped
 	description := "All of the rules are skipped"
 
 	switch {
 	case approved > 0 && pending == 0:
 		status = common.StatusApproved
+		reason = ""
 		description = fmt.Sprintf("All rules are approved")
 	case pending > 0:
 		status = common.StatusPending
+		reason = common.ReasonRulesPending
 		description = fmt.Sprintf("%d/%d rules approved", approved, approved+pending)
 	}
 
+	if status == common.StatusPending && overdue > 0 {
+		description = fmt.Sprintf("%s, %d overdue", description, overdue)
+	}
+
+	if err != nil {
+		reason = common.ReasonError
+	}
+
 	return common.Result{
 		Name:        "and",
 		Status:      status,
+		Reason:      reason,
 		Description: description,
 		Error:       err,
 		Children:    children,
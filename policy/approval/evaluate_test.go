@@ -33,6 +33,7 @@ import (
 func TestRules(t *testing.T) {
 	ruleText := `
 - name: rule1
+  description: "Requires approval from the owners"
   if:
     changed_files:
       paths: ["path1"]
@@ -65,7 +66,8 @@ func TestRules(t *testing.T) {
 
 	expected := []*Rule{
 		{
-			Name: "rule1",
+			Name:        "rule1",
+			Description: "Requires approval from the owners",
 			Predicates: Predicates{
 				ChangedFiles: &predicate.ChangedFiles{
 					Paths: []string{"path1"},
@@ -142,6 +144,7 @@ func TestAndRequirement(t *testing.T) {
 	result := and.Evaluate(ctx, prctx)
 	assert.NoError(t, result.Error)
 	assert.Equal(t, common.StatusPending, result.Status)
+	assert.Equal(t, common.ReasonRulesPending, result.Reason)
 
 	// All approved is approved
 	and = &AndRequirement{
@@ -184,6 +187,35 @@ func TestAndRequirement(t *testing.T) {
 	}
 	result = and.Evaluate(ctx, prctx)
 	assert.Error(t, result.Error)
+
+	// Overdue rules are reported
+	and = &AndRequirement{
+		requirements: []common.Evaluator{
+			&mockRequirement{
+				result: &common.Result{
+					Status: common.StatusApproved,
+				},
+			},
+			&mockRequirement{
+				result: &common.Result{
+					Status:  common.StatusPending,
+					Reason:  common.ReasonInsufficientApprovals,
+					Overdue: true,
+				},
+			},
+			&mockRequirement{
+				result: &common.Result{
+					Status: common.StatusPending,
+					Reason: common.ReasonInsufficientApprovals,
+				},
+			},
+		},
+	}
+	result = and.Evaluate(ctx, prctx)
+	assert.NoError(t, result.Error)
+	assert.Equal(t, common.StatusPending, result.Status)
+	assert.Equal(t, common.ReasonRulesPending, result.Reason)
+	assert.Equal(t, "1/3 rules approved, 1 overdue", result.Description)
 }
 
 func TestOrRequirement(t *testing.T) {
@@ -221,6 +253,7 @@ func TestOrRequirement(t *testing.T) {
 	result = or.Evaluate(ctx, prctx)
 	assert.NoError(t, result.Error)
 	assert.Equal(t, common.StatusSkipped, result.Status)
+	assert.Equal(t, common.ReasonRulesSkipped, result.Reason)
 
 	// Error does not block approval
 	or = &OrRequirement{
@@ -240,4 +273,117 @@ func TestOrRequirement(t *testing.T) {
 	result = or.Evaluate(ctx, prctx)
 	assert.NoError(t, result.Error)
 	assert.Equal(t, common.StatusApproved, result.Status)
+
+	// Overdue rules are reported unless another rule is approved
+	overdue := &mockRequirement{
+		result: &common.Result{
+			Status:  common.StatusPending,
+			Reason:  common.ReasonInsufficientApprovals,
+			Overdue: true,
+		},
+	}
+	or = &OrRequirement{
+		requirements: []common.Evaluator{overdue, overdue},
+	}
+	result = or.Evaluate(ctx, prctx)
+	assert.NoError(t, result.Error)
+	assert.Equal(t, common.StatusPending, result.Status)
+	assert.Equal(t, common.ReasonRulesPending, result.Reason)
+	assert.Equal(t, "None of the rules are satisfied, 2 overdue", result.Description)
+
+	or.requirements = append(or.requirements, makeRulesResultingIn(common.StatusApproved)...)
+	result = or.Evaluate(ctx, prctx)
+	assert.NoError(t, result.Error)
+	assert.Equal(t, common.StatusApproved, result.Status)
+}
+
+func TestStatusRequirementComposition(t *testing.T) {
+	ctx := context.Background()
+
+	ruleText := `
+- name: two approvals
+  requires:
+    count: 2
+    users: ["user1", "user2"]
+- name: one approval
+  requires:
+    count: 1
+    users: ["user1", "user2"]
+- name: security scan
+  requires:
+    statuses: ["security-scan"]
+`
+	policyText := `
+- or:
+  - two approvals
+  - and:
+    - one approval
+    - security scan
+`
+
+	var rules []*Rule
+	require.NoError(t, yaml.UnmarshalStrict([]byte(ruleText), &rules))
+
+	rulesByName := make(map[string]*Rule)
+	for _, r := range rules {
+		rulesByName[r.Name] = r
+	}
+
+	var policy Policy
+	require.NoError(t, yaml.UnmarshalStrict([]byte(policyText), &policy))
+
+	eval, err := policy.Parse(rulesByName)
+	require.NoError(t, err)
+
+	approval := func(user string) *pull.Review {
+		return &pull.Review{Author: user, State: pull.ReviewApproved}
+	}
+
+	tests := map[string]struct {
+		Reviews  []*pull.Review
+		Statuses map[string]string
+		Expected common.EvaluationStatus
+	}{
+		"twoApprovals": {
+			Reviews:  []*pull.Review{approval("user1"), approval("user2")},
+			Expected: common.StatusApproved,
+		},
+		"oneApprovalAndScan": {
+			Reviews:  []*pull.Review{approval("user1")},
+			Statuses: map[string]string{"security-scan": "success"},
+			Expected: common.StatusApproved,
+		},
+		"oneApprovalAndFailedScan": {
+			Reviews:  []*pull.Review{approval("user1")},
+			Statuses: map[string]string{"security-scan": "failure"},
+			Expected: common.StatusPending,
+		},
+		"oneApprovalAndMissingScan": {
+			Reviews:  []*pull.Review{approval("user1")},
+			Expected: common.StatusPending,
+		},
+		"scanWithoutApproval": {
+			Statuses: map[string]string{"security-scan": "success"},
+			Expected: common.StatusPending,
+		},
+		"twoApprovalsAndFailedScan": {
+			Reviews:  []*pull.Review{approval("user1"), approval("user2")},
+			Statuses: map[string]string{"security-scan": "failure"},
+			Expected: common.StatusApproved,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prctx := &pulltest.Context{
+				AuthorValue:   "author",
+				ReviewsValue:  test.Reviews,
+				StatusesValue: test.Statuses,
+			}
+
+			result := eval.Evaluate(ctx, prctx)
+			require.NoError(t, result.Error)
+			assert.Equal(t, test.Expected, result.Status)
+		})
+	}
 }
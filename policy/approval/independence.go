@@ -0,0 +1,84 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+const (
+	DefaultIndependenceLookback = 30 * 24 * time.Hour
+)
+
+// IndependentApprovers ignores approvals from users who form an exclusive
+// pair with the author or with another approver: within the lookback period,
+// every pull request by either user that has approvals was approved only by
+// the other user.
+type IndependentApprovers struct {
+	Lookback time.Duration `yaml:"lookback"`
+}
+
+func (ia *IndependentApprovers) lookback() time.Duration {
+	if ia.Lookback <= 0 {
+		return DefaultIndependenceLookback
+	}
+	return ia.Lookback
+}
+
+// coReviewers maps each author of a recent pull request to the users who
+// approved any of their pull requests. Approvals by the author are ignored.
+type coReviewers map[string]map[string]bool
+
+func (ia *IndependentApprovers) coReviewers(prctx pull.Context) (coReviewers, error) {
+	approvals, err := prctx.RecentApprovals(ia.lookback())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get recent approvals")
+	}
+
+	cr := make(coReviewers)
+	for _, a := range approvals {
+		for _, approver := range a.Approvers {
+			if approver == a.Author {
+				continue
+			}
+			if cr[a.Author] == nil {
+				cr[a.Author] = make(map[string]bool)
+			}
+			cr[a.Author][approver] = true
+		}
+	}
+	return cr, nil
+}
+
+// exclusive returns true if the only user who approved pull requests by a is
+// b and the only user who approved pull requests by b is a
+func (cr coReviewers) exclusive(a, b string) bool {
+	return len(cr[a]) == 1 && cr[a][b] && len(cr[b]) == 1 && cr[b][a]
+}
+
+// partner returns the first of users that forms an exclusive pair with user,
+// or an empty string if there is none
+func (cr coReviewers) partner(user string, users []string) string {
+	for _, u := range users {
+		if u != user && cr.exclusive(user, u) {
+			return u
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// missingLastAuthors returns the users who last changed a file modified by
+// the pull request and have not approved it. Added files have no last author.
+// Last authors who are the pull request author, are banned, or may not
+// approve the rule are exempt, so the requirement never waits for an approval
+// that cannot count.
+func (r *Rule) missingLastAuthors(ctx context.Context, prctx pull.Context, banned map[string]bool, approvers []string) ([]string, error) {
+	log := zerolog.Ctx(ctx)
+
+	files, err := prctx.ChangedFiles()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list changed files")
+	}
+
+	approved := make(map[string]bool)
+	for _, a := range approvers {
+		approved[a] = true
+	}
+
+	var missing []string
+	checked := make(map[string]bool)
+	for _, f := range files {
+		if f.Status == pull.FileAdded {
+			continue
+		}
+
+		user, err := prctx.LastAuthor(f.Filename)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get last author")
+		}
+		if user == "" || checked[user] {
+			continue
+		}
+		checked[user] = true
+
+		if approved[user] || user == prctx.Author() || banned[user] {
+			continue
+		}
+
+		isApprover, err := r.isApprover(ctx, prctx, user)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to check last author status")
+		}
+		if !isApprover {
+			log.Debug().Str("user", user).Msgf("ignoring last author of %s who may not approve", f.Filename)
+			continue
+		}
+		missing = append(missing, user)
+	}
+	return missing, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/pull"
+)
+
+// ActorMethods replaces the approval methods of a rule for a set of actors.
+type ActorMethods struct {
+	common.Actors `yaml:",inline"`
+
+	Methods *common.Methods `yaml:"methods"`
+}
+
+// GetMethods returns the methods of the actors, using the same defaults as
+// the methods of a rule.
+func (am *ActorMethods) GetMethods() *common.Methods {
+	return (&Options{Methods: am.Methods}).GetMethods()
+}
+
+// MethodsFor returns the approval methods that count for the user: the
+// methods of the first actors that contain the user, or the methods of the
+// rule if no actors contain the user.
+func (r *Rule) MethodsFor(ctx context.Context, prctx pull.Context, user string) (*common.Methods, error) {
+	i, err := r.actorMethodsIndex(ctx, prctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if i == 0 {
+		return r.Options.GetMethods(), nil
+	}
+	return r.Options.ActorMethods[i-1].GetMethods(), nil
+}
+
+// CommentMatches returns true if a comment by the user matches the methods
+// that count for the user.
+func (r *Rule) CommentMatches(ctx context.Context, prctx pull.Context, user, commentBody string) (bool, error) {
+	methods, err := r.MethodsFor(ctx, prctx, user)
+	if err != nil {
+		return false, err
+	}
+	return methods.CommentMatches(commentBody), nil
+}
+
+// candidates returns a candidate for every use of an approval method. If the
+// rule has actor methods, each candidate is only included if it used the
+// methods of the first actors that contain the user, or the methods of the
+// rule if no actors contain the user.
+func (r *Rule) candidates(ctx context.Context, prctx pull.Context) ([]*common.Candidate, error) {
+	candidates, err := r.Options.GetMethods().AllCandidates(ctx, prctx)
+	if err != nil || len(r.Options.ActorMethods) == 0 {
+		return candidates, err
+	}
+
+	log := zerolog.Ctx(ctx)
+
+	// index 0 is the rule methods, index i+1 is the methods of actors i
+	sources := make(map[*common.Candidate]int)
+	for i := range r.Options.ActorMethods {
+		actorCandidates, err := r.Options.ActorMethods[i].GetMethods().AllCandidates(ctx, prctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range actorCandidates {
+			sources[c] = i + 1
+		}
+		candidates = append(candidates, actorCandidates...)
+	}
+
+	methodsForUser := make(map[string]int)
+	var allowed []*common.Candidate
+	for _, c := range candidates {
+		methods, ok := methodsForUser[c.User]
+		if !ok {
+			methods, err = r.actorMethodsIndex(ctx, prctx, c.User)
+			if err != nil {
+				return nil, err
+			}
+			methodsForUser[c.User] = methods
+		}
+
+		if sources[c] != methods {
+			log.Debug().Str("user", c.User).Msg("ignoring approval that does not use the methods for the user")
+			continue
+		}
+		allowed = append(allowed, c)
+	}
+	return allowed, nil
+}
+
+func (r *Rule) actorMethodsIndex(ctx context.Context, prctx pull.Context, user string) (int, error) {
+	for i := range r.Options.ActorMethods {
+		isActor, err := r.Options.ActorMethods[i].IsActor(ctx, prctx, user)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to check actor methods")
+		}
+		if isActor {
+			return i + 1, nil
+		}
+	}
+	return 0, nil
+}
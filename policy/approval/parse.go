@@ -27,10 +27,24 @@ type Policy []interface{}
 func (p Policy) Parse(rules map[string]*Rule) (common.Evaluator, error) {
 	eval := &evaluator{}
 
+	for _, r := range rules {
+		if err := r.Options.validate(); err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("invalid options for rule '%s'", r.Name))
+		}
+		if err := r.Requires.validate(); err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("invalid requirements for rule '%s'", r.Name))
+		}
+		if err := r.Predicates.Validate(); err != nil {
+			return nil, errors.WithMessage(err, fmt.Sprintf("invalid predicates for rule '%s'", r.Name))
+		}
+	}
+
 	if len(p) == 0 {
 		return eval, nil
 	}
 
+	linkApprovalPools(rules)
+
 	// assume "and" for the list of rules
 	root := map[interface{}]interface{}{
 		"and": []interface{}(p),
@@ -105,3 +119,25 @@ func parsePolicyR(policy interface{}, rules map[string]*Rule, depth int) (common
 
 	return nil, errors.Errorf("malformed policy, expected string or map, but encountered %T", policy)
 }
+
+// linkApprovalPools gives each rule in an approval pool the requirements of
+// the other rules in the same pool
+func linkApprovalPools(rules map[string]*Rule) {
+	pools := make(map[string][]*Rule)
+	for _, r := range rules {
+		if name := r.Options.ApprovalPool; name != "" {
+			pools[name] = append(pools[name], r)
+		}
+	}
+
+	for _, pool := range pools {
+		for _, r := range pool {
+			r.pool = nil
+			for _, other := range pool {
+				if other != r {
+					r.pool = append(r.pool, &other.Requires)
+				}
+			}
+		}
+	}
+}
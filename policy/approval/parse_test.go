@@ -212,6 +212,234 @@ func TestParsePolicyError_recursiveDepth(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestParsePolicyError_invalidQuorum(t *testing.T) {
+	policy := `
+- rule1
+`
+
+	tests := map[string]string{
+		"noUsers": `
+- name: rule1
+  requires:
+    quorum:
+      count: 1
+`,
+		"countTooLarge": `
+- name: rule1
+  requires:
+    quorum:
+      users: ["user1", "user2"]
+      count: 3
+`,
+		"missingCount": `
+- name: rule1
+  requires:
+    quorum:
+      users: ["user1", "user2"]
+`,
+		"duplicateUser": `
+- name: rule1
+  requires:
+    quorum:
+      users: ["user1", "user1", "user2"]
+      count: 3
+`,
+	}
+
+	for name, rules := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadAndParsePolicy(t, policy, rules)
+			require.Error(t, err)
+		})
+	}
+
+	_, err := loadAndParsePolicy(t, policy, `
+- name: rule1
+  requires:
+    quorum:
+      users: ["user1", "user2", "user3"]
+      count: 2
+`)
+	require.NoError(t, err)
+}
+
+func TestParsePolicyError_invalidSizeTiers(t *testing.T) {
+	policy := `
+- rule1
+`
+
+	tests := map[string]struct {
+		Rules string
+		Error string
+	}{
+		"countAndTiers": {
+			Rules: `
+- name: rule1
+  requires:
+    count: 2
+    size_tiers:
+      - name: small
+        count: 1
+`,
+			Error: "count and size_tiers cannot both be set",
+		},
+		"negativeCount": {
+			Rules: `
+- name: rule1
+  requires:
+    size_tiers:
+      - name: small
+        lines: 50
+        count: -1
+`,
+			Error: `size tier "small" must not have a negative count`,
+		},
+		"negativeLimit": {
+			Rules: `
+- name: rule1
+  requires:
+    size_tiers:
+      - name: small
+        files: -5
+        count: 1
+`,
+			Error: `size tier "small" must not have negative limits`,
+		},
+		"missingName": {
+			Rules: `
+- name: rule1
+  requires:
+    size_tiers:
+      - lines: 50
+        count: 1
+`,
+			Error: "size tier 1 must have a name",
+		},
+		"duplicateName": {
+			Rules: `
+- name: rule1
+  requires:
+    size_tiers:
+      - name: small
+        lines: 50
+        count: 1
+      - name: small
+        count: 2
+`,
+			Error: `size tier "small" is defined more than once`,
+		},
+		"outOfOrder": {
+			Rules: `
+- name: rule1
+  requires:
+    size_tiers:
+      - name: medium
+        lines: 500
+        count: 2
+      - name: small
+        lines: 50
+        files: 5
+        count: 1
+`,
+			Error: `size tier "small" can never apply because it is listed after the larger tier "medium"`,
+		},
+		"unlimitedFirst": {
+			Rules: `
+- name: rule1
+  requires:
+    size_tiers:
+      - name: large
+        count: 3
+      - name: small
+        lines: 50
+        count: 1
+`,
+			Error: `size tier "small" can never apply because it is listed after the larger tier "large"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadAndParsePolicy(t, policy, test.Rules)
+			require.EqualError(t, err, "invalid requirements for rule 'rule1': "+test.Error)
+		})
+	}
+
+	// limits on different measures may overlap
+	_, err := loadAndParsePolicy(t, policy, `
+- name: rule1
+  requires:
+    size_tiers:
+      - name: small
+        lines: 100
+        count: 1
+      - name: medium
+        lines: 500
+        files: 10
+        count: 2
+      - name: large
+        count: 3
+`)
+	require.NoError(t, err)
+}
+
+func TestParsePolicyError_invalidPredicates(t *testing.T) {
+	policy := `
+- rule1
+`
+
+	_, err := loadAndParsePolicy(t, policy, `
+- name: rule1
+  if:
+    within_time_window:
+      timezone: America/Nowhere
+      windows:
+        - start: "09:00"
+`)
+	require.EqualError(t, err, `invalid predicates for rule 'rule1': invalid timezone "America/Nowhere": unknown time zone America/Nowhere`)
+
+	_, err = loadAndParsePolicy(t, policy, `
+- name: rule1
+  if:
+    outside_time_window:
+      windows:
+        - days: ["someday"]
+`)
+	require.EqualError(t, err, `invalid predicates for rule 'rule1': invalid day of the week "someday"`)
+
+	_, err = loadAndParsePolicy(t, policy, `
+- name: rule1
+  if:
+    within_time_window:
+      timezone: America/New_York
+      windows:
+        - days: ["mon", "Friday"]
+          start: "09:00"
+          end: "24:00"
+`)
+	require.NoError(t, err)
+}
+
+func TestParsePolicyError_invalidPredicateCommits(t *testing.T) {
+	policy := `
+- rule1
+`
+
+	_, err := loadAndParsePolicy(t, policy, `
+- name: rule1
+  options:
+    predicate_commits: squashed
+`)
+	require.EqualError(t, err, `invalid options for rule 'rule1': unknown predicate_commits value "squashed"`)
+
+	_, err = loadAndParsePolicy(t, policy, `
+- name: rule1
+  options:
+    predicate_commits: auto
+`)
+	require.NoError(t, err)
+}
+
 func loadAndParsePolicy(t *testing.T, policyText string, ruleText string) (common.Evaluator, error) {
 	var policy Policy
 	err := yaml.UnmarshalStrict([]byte(policyText), &policy)
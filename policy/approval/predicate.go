@@ -26,10 +26,48 @@ type Predicates struct {
 	HasContributorIn        *predicate.HasContributorIn        `yaml:"has_contributor_in"`
 	OnlyHasContributorsIn   *predicate.OnlyHasContributorsIn   `yaml:"only_has_contributors_in"`
 	AuthorIsOnlyContributor *predicate.AuthorIsOnlyContributor `yaml:"author_is_only_contributor"`
+	AuthorIsBot             *predicate.AuthorIsBot             `yaml:"author_is_bot"`
+	CommitAuthorEmail       *predicate.CommitAuthorEmail       `yaml:"commit_author_email"`
+	Assignees               *predicate.Assignees               `yaml:"assignees"`
 
-	TargetsBranch *predicate.TargetsBranch `yaml:"targets_branch"`
+	TargetsBranch  *predicate.TargetsBranch  `yaml:"targets_branch"`
+	HeadBranch     *predicate.HeadBranch     `yaml:"head_branch"`
+	BranchBehindBy *predicate.BranchBehindBy `yaml:"branch_behind_by"`
+	IsMergeable    *predicate.IsMergeable    `yaml:"is_mergeable"`
+	MergeMethods   *predicate.MergeMethods   `yaml:"merge_methods"`
+	FromFork       *predicate.FromFork       `yaml:"from_fork"`
+
+	TargetsDefaultBranch *predicate.TargetsDefaultBranch `yaml:"targets_default_branch"`
 
 	ModifiedLines *predicate.ModifiedLines `yaml:"modified_lines"`
+	ModifiedHunks *predicate.ModifiedHunks `yaml:"modified_hunks"`
+
+	ChangedDirectories *predicate.ChangedDirectories `yaml:"changed_directories"`
+
+	ReviewComments *predicate.ReviewComments `yaml:"review_comments"`
+	HasReviewState *predicate.HasReviewState `yaml:"has_review_state"`
+	ApprovalCount  *predicate.ApprovalCount  `yaml:"approval_count"`
+	RequestedTeams *predicate.RequestedTeams `yaml:"requested_teams"`
+
+	WithinTimeWindow  *predicate.WithinTimeWindow  `yaml:"within_time_window"`
+	OutsideTimeWindow *predicate.OutsideTimeWindow `yaml:"outside_time_window"`
+
+	HasMissingStatus *predicate.HasMissingStatus `yaml:"has_missing_status"`
+
+	HasLinkedIssue *predicate.HasLinkedIssue `yaml:"has_linked_issue"`
+	ClosesIssue    *predicate.ClosesIssue    `yaml:"closes_issue"`
+}
+
+// Validate returns an error if any predicate has invalid options.
+func (p *Predicates) Validate() error {
+	for _, pred := range p.Predicates() {
+		if v, ok := pred.(predicate.Validator); ok {
+			if err := v.Validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (p *Predicates) Predicates() []predicate.Predicate {
@@ -54,13 +92,82 @@ func (p *Predicates) Predicates() []predicate.Predicate {
 	if p.AuthorIsOnlyContributor != nil {
 		ps = append(ps, predicate.Predicate(p.AuthorIsOnlyContributor))
 	}
+	if p.AuthorIsBot != nil {
+		ps = append(ps, predicate.Predicate(p.AuthorIsBot))
+	}
+	if p.CommitAuthorEmail != nil {
+		ps = append(ps, predicate.Predicate(p.CommitAuthorEmail))
+	}
+	if p.Assignees != nil {
+		ps = append(ps, predicate.Predicate(p.Assignees))
+	}
 
 	if p.TargetsBranch != nil {
 		ps = append(ps, predicate.Predicate(p.TargetsBranch))
 	}
+	if p.HeadBranch != nil {
+		ps = append(ps, predicate.Predicate(p.HeadBranch))
+	}
+	if p.BranchBehindBy != nil {
+		ps = append(ps, predicate.Predicate(p.BranchBehindBy))
+	}
+
+	if p.IsMergeable != nil {
+		ps = append(ps, predicate.Predicate(p.IsMergeable))
+	}
+	if p.MergeMethods != nil {
+		ps = append(ps, predicate.Predicate(p.MergeMethods))
+	}
+
+	if p.FromFork != nil {
+		ps = append(ps, predicate.Predicate(p.FromFork))
+	}
+	if p.TargetsDefaultBranch != nil {
+		ps = append(ps, predicate.Predicate(p.TargetsDefaultBranch))
+	}
 	if p.ModifiedLines != nil {
 		ps = append(ps, predicate.Predicate(p.ModifiedLines))
 	}
 
+	if p.ModifiedHunks != nil {
+		ps = append(ps, predicate.Predicate(p.ModifiedHunks))
+	}
+
+	if p.ChangedDirectories != nil {
+		ps = append(ps, predicate.Predicate(p.ChangedDirectories))
+	}
+
+	if p.ReviewComments != nil {
+		ps = append(ps, predicate.Predicate(p.ReviewComments))
+	}
+	if p.HasReviewState != nil {
+		ps = append(ps, predicate.Predicate(p.HasReviewState))
+	}
+	if p.ApprovalCount != nil {
+		ps = append(ps, predicate.Predicate(p.ApprovalCount))
+	}
+	if p.RequestedTeams != nil {
+		ps = append(ps, predicate.Predicate(p.RequestedTeams))
+	}
+
+	if p.WithinTimeWindow != nil {
+		ps = append(ps, predicate.Predicate(p.WithinTimeWindow))
+	}
+
+	if p.OutsideTimeWindow != nil {
+		ps = append(ps, predicate.Predicate(p.OutsideTimeWindow))
+	}
+
+	if p.HasMissingStatus != nil {
+		ps = append(ps, predicate.Predicate(p.HasMissingStatus))
+	}
+
+	if p.HasLinkedIssue != nil {
+		ps = append(ps, predicate.Predicate(p.HasLinkedIssue))
+	}
+	if p.ClosesIssue != nil {
+		ps = append(ps, predicate.Predicate(p.ClosesIssue))
+	}
+
 	return ps
 }
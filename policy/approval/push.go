@@ -0,0 +1,139 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/pull"
+)
+
+// keepsApproval returns true if the candidate, which was submitted before the
+// last push, still counts because the push only made changes allowed by the
+// rule options.
+func (r *Rule) keepsApproval(ctx context.Context, prctx pull.Context, commits []*pull.Commit, c *common.Candidate) (bool, error) {
+	log := zerolog.Ctx(ctx)
+
+	approved := approvedCommit(commits, c)
+	if approved == "" {
+		return false, nil
+	}
+
+	if r.Options.IgnoreWhitespaceChanges {
+		whitespace, err := isWhitespacePush(prctx, approved)
+		if err != nil {
+			return false, err
+		}
+		if whitespace {
+			log.Debug().Str("user", c.User).Msg("keeping approval invalidated by whitespace changes")
+			return true, nil
+		}
+	}
+
+	if r.Options.IgnoreBaseUpdates {
+		update, err := isBaseUpdate(prctx, approved)
+		if err != nil {
+			return false, err
+		}
+		if update {
+			log.Debug().Str("user", c.User).Msg("keeping approval invalidated by an update from the target branch")
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// approvedCommit returns the SHA of the head commit when the candidate was
+// submitted. Reviews record their commit. For comments, this is the last
+// commit pushed at or before the comment, or an empty string if there is no
+// such commit.
+func approvedCommit(commits []*pull.Commit, c *common.Candidate) string {
+	if c.SHA != "" {
+		return c.SHA
+	}
+
+	var last *pull.Commit
+	for _, commit := range commits {
+		if commit.PushedAt != nil && !commit.PushedAt.After(c.CreatedAt) && (last == nil || commit.PushedAt.After(*last.PushedAt)) {
+			last = commit
+		}
+	}
+	if last == nil {
+		return ""
+	}
+	return last.SHA
+}
+
+// isBaseUpdate returns true if the changes of the pull request at the approved
+// commit are the same as its changes at the head commit, meaning the pushes
+// since the approval only merged or rebased on the target branch. Changes are
+// the same if the same files have the same status and the same patch,
+// ignoring line numbers. Files without a patch are never the same.
+func isBaseUpdate(prctx pull.Context, approved string) (bool, error) {
+	base, _ := prctx.Branches()
+
+	before, err := prctx.CompareFiles(base, approved)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to compare approved commit")
+	}
+	if before == nil {
+		return false, nil
+	}
+
+	after, err := prctx.ChangedFiles()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to list changed files")
+	}
+
+	if len(before) != len(after) {
+		return false, nil
+	}
+
+	patches := make(map[string]*pull.File, len(before))
+	for _, f := range before {
+		patches[f.Filename] = f
+	}
+
+	for _, f := range after {
+		b, ok := patches[f.Filename]
+		if !ok || b.Status != f.Status || f.Patch == "" {
+			return false, nil
+		}
+		if stripHunkHeaders(b.Patch) != stripHunkHeaders(f.Patch) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func stripHunkHeaders(patch string) string {
+	lines := strings.Split(patch, "\n")
+
+	var stripped []string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "@@") {
+			// keep hunk boundaries so that merged hunks are detected
+			line = "@@"
+		}
+		stripped = append(stripped, line)
+	}
+	return strings.Join(stripped, "\n")
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/policy/common"
+)
+
+// Quorum is a set of named users, some number of whom must approve.
+type Quorum struct {
+	Users []string `yaml:"users"`
+	Count int      `yaml:"count"`
+}
+
+func (q *Quorum) validate() error {
+	if len(q.Users) == 0 {
+		return errors.New("quorum must list at least one user")
+	}
+
+	seen := make(map[string]bool)
+	for _, u := range q.Users {
+		if seen[u] {
+			return errors.Errorf("quorum lists user %q more than once", u)
+		}
+		seen[u] = true
+	}
+
+	if q.Count < 1 || q.Count > len(q.Users) {
+		return errors.Errorf("quorum count must be between 1 and %d, but is %d", len(q.Users), q.Count)
+	}
+	return nil
+}
+
+// results returns a result for each user in the quorum, the users who
+// approved, and the users who have not approved. Users are the candidates
+// that may approve; users outside the quorum are ignored.
+func (q *Quorum) results(users []string) ([]*common.Result, []string, []string) {
+	approvedBy := make(map[string]bool)
+	for _, u := range users {
+		approvedBy[u] = true
+	}
+
+	var results []*common.Result
+	var approved, waiting []string
+	for _, u := range q.Users {
+		res := &common.Result{
+			Name: u,
+		}
+		if approvedBy[u] {
+			res.Status = common.StatusApproved
+			res.Description = "Approved"
+			approved = append(approved, u)
+		} else {
+			res.Status = common.StatusPending
+			res.Description = "Not yet approved"
+			waiting = append(waiting, u)
+		}
+		results = append(results, res)
+	}
+	return results, approved, waiting
+}
+
+func (q *Quorum) pendingDescription(approved, waiting []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d/%d quorum approvals", len(approved), q.Count)
+	if len(approved) > 0 {
+		fmt.Fprintf(&b, " from %s", strings.Join(approved, ", "))
+	}
+	fmt.Fprintf(&b, ". %d more required from %s", q.Count-len(approved), strings.Join(waiting, ", "))
+	return b.String()
+}
@@ -0,0 +1,41 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// wasRequested returns true if the user was requested to review the pull
+// request, directly or as a member of a requested team
+func wasRequested(prctx pull.Context, history *pull.ReviewRequests, user string) (bool, error) {
+	for _, u := range history.Users {
+		if u == user {
+			return true, nil
+		}
+	}
+	for _, team := range history.Teams {
+		member, err := prctx.IsTeamMember(team, user)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to check membership in requested team %s", team)
+		}
+		if member {
+			return true, nil
+		}
+	}
+	return false, nil
+}
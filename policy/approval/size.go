@@ -0,0 +1,118 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// SizeTier is a range of pull request sizes that requires a number of
+// approvals, like "small" or "large".
+type SizeTier struct {
+	Name string `yaml:"name"`
+
+	// Lines is the maximum number of modified lines, additions plus
+	// deletions, of pull requests in the tier. If zero, lines are not limited.
+	Lines int `yaml:"lines"`
+
+	// Files is the maximum number of changed files of pull requests in the
+	// tier. If zero, files are not limited.
+	Files int `yaml:"files"`
+
+	// Count is the number of approvals required for pull requests in the tier
+	Count int `yaml:"count"`
+}
+
+// covers returns true if every pull request in the other tier is also in this
+// tier
+func (t *SizeTier) covers(other *SizeTier) bool {
+	coversLimit := func(limit, otherLimit int) bool {
+		return limit <= 0 || (otherLimit > 0 && otherLimit <= limit)
+	}
+	return coversLimit(t.Lines, other.Lines) && coversLimit(t.Files, other.Files)
+}
+
+// validateSizeTiers returns an error if a tier is invalid or can never apply
+// because an earlier tier contains every pull request it contains
+func validateSizeTiers(tiers []SizeTier) error {
+	names := make(map[string]bool)
+	for i := range tiers {
+		t := &tiers[i]
+		if t.Name == "" {
+			return errors.Errorf("size tier %d must have a name", i+1)
+		}
+		if names[t.Name] {
+			return errors.Errorf("size tier %q is defined more than once", t.Name)
+		}
+		names[t.Name] = true
+
+		if t.Lines < 0 || t.Files < 0 {
+			return errors.Errorf("size tier %q must not have negative limits", t.Name)
+		}
+		if t.Count < 0 {
+			return errors.Errorf("size tier %q must not have a negative count", t.Name)
+		}
+
+		for j := 0; j < i; j++ {
+			if tiers[j].covers(t) {
+				return errors.Errorf("size tier %q can never apply because it is listed after the larger tier %q", t.Name, tiers[j].Name)
+			}
+		}
+	}
+	return nil
+}
+
+func (t *SizeTier) contains(lines, files int) bool {
+	return (t.Lines <= 0 || lines <= t.Lines) && (t.Files <= 0 || files <= t.Files)
+}
+
+// pullSize is the size tier of a pull request and the measurements that placed
+// it in the tier
+type pullSize struct {
+	Tier  *SizeTier
+	Lines int
+	Files int
+}
+
+func (s *pullSize) String() string {
+	return fmt.Sprintf("the %s size tier (%d modified lines, %s)", s.Tier.Name, s.Lines, numberOfChangedFiles(s.Files))
+}
+
+// findSizeTier returns the first tier that contains the pull request, or the
+// last tier if the pull request is larger than all of them
+func findSizeTier(prctx pull.Context, tiers []SizeTier) (*pullSize, error) {
+	files, err := prctx.ChangedFiles()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list changed files")
+	}
+
+	size := &pullSize{Files: len(files)}
+	for _, f := range files {
+		size.Lines += f.Additions + f.Deletions
+	}
+
+	size.Tier = &tiers[len(tiers)-1]
+	for i := range tiers {
+		if tiers[i].contains(size.Lines, size.Files) {
+			size.Tier = &tiers[i]
+			break
+		}
+	}
+	return size, nil
+}
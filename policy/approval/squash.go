@@ -0,0 +1,96 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/policy/predicate"
+	"github.com/palantir/policy-bot/pull"
+)
+
+// Values for the PredicateCommits option
+const (
+	PredicateCommitsAll    = "all"
+	PredicateCommitsSquash = "squash"
+	PredicateCommitsAuto   = "auto"
+)
+
+func (opts *Options) validate() error {
+	switch opts.PredicateCommits {
+	case "", PredicateCommitsAll, PredicateCommitsSquash, PredicateCommitsAuto:
+		return nil
+	}
+	return errors.Errorf("unknown predicate_commits value %q", opts.PredicateCommits)
+}
+
+// predicateContext returns the context used to evaluate the predicates of the
+// rule, which replaces the commits of the pull request with a preview of the
+// squash commit if the rule evaluates predicates against it.
+func (r *Rule) predicateContext(prctx pull.Context) (pull.Context, error) {
+	switch r.Options.PredicateCommits {
+	case PredicateCommitsSquash:
+		return squashContext{prctx}, nil
+	case PredicateCommitsAuto:
+		methods, err := prctx.MergeMethods()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get merge methods")
+		}
+		if methods.Squash && !methods.Merge && !methods.Rebase {
+			return squashContext{prctx}, nil
+		}
+	}
+	return prctx, nil
+}
+
+// squashContext is a pull.Context whose commit messages and author emails are
+// those of a single commit that previews the commit created by squash merging
+// the pull request. Contributor predicates still see every commit, because
+// the squash commit hides the users who pushed commits.
+type squashContext struct {
+	pull.Context
+}
+
+var _ predicate.MessageContext = squashContext{}
+
+func (sc squashContext) MessageCommits() ([]*pull.Commit, error) {
+	commits, err := sc.Context.Commits()
+	if err != nil {
+		return nil, err
+	}
+
+	author := sc.Author()
+	squash := &pull.Commit{
+		SHA:             sc.HeadSHA(),
+		Author:          author,
+		CommittedViaWeb: true,
+		Message:         strings.TrimSpace(fmt.Sprintf("%s (#%d)\n\n%s", sc.Title(), sc.Number(), sc.Body())),
+	}
+
+	// GitHub uses an email of the author, which is only known if the author
+	// created some of the commits, so use the most recent one
+	for _, c := range commits {
+		if c.Author == author && c.AuthorEmail != "" {
+			squash.AuthorEmail = c.AuthorEmail
+		}
+		if c.PushedAt != nil {
+			squash.PushedAt = c.PushedAt
+		}
+	}
+	return []*pull.Commit{squash}, nil
+}
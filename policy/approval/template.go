@@ -0,0 +1,90 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+var (
+	headingRegexp     = regexp.MustCompile(`^ {0,3}#{1,6}[ \t]+(.*?)[ \t#]*$`)
+	htmlCommentRegexp = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+type templateSection struct {
+	Title   string
+	Content string
+}
+
+// incompleteTemplateSections returns the titles of the sections of the
+// repository's pull request template that are missing from the description
+// or that are empty. A section is empty if it only contains HTML comments or
+// the same content as the template. Repositories without a template have no
+// incomplete sections.
+func incompleteTemplateSections(prctx pull.Context) ([]string, error) {
+	template, err := prctx.PullRequestTemplate()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pull request template")
+	}
+
+	described := make(map[string]string)
+	for _, s := range parseSections(prctx.Body()) {
+		if _, ok := described[s.Title]; !ok {
+			described[s.Title] = s.Content
+		}
+	}
+
+	var incomplete []string
+	for _, s := range parseSections(template) {
+		content, ok := described[s.Title]
+		if !ok || content == "" || content == s.Content {
+			incomplete = append(incomplete, s.Title)
+		}
+	}
+	return incomplete, nil
+}
+
+// parseSections splits markdown text into sections at ATX headings, ignoring
+// any text before the first heading. Titles and content are normalized so
+// that sections from different documents can be compared.
+func parseSections(text string) []templateSection {
+	text = htmlCommentRegexp.ReplaceAllString(text, "")
+
+	var sections []templateSection
+	var content []string
+	flush := func() {
+		if len(sections) > 0 {
+			sections[len(sections)-1].Content = strings.TrimSpace(strings.Join(content, "\n"))
+		}
+		content = nil
+	}
+
+	for _, line := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+		if m := headingRegexp.FindStringSubmatch(line); m != nil {
+			flush()
+			sections = append(sections, templateSection{Title: strings.TrimSpace(m[1])})
+			continue
+		}
+		content = append(content, line)
+	}
+	flush()
+
+	return sections
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// isWhitespacePush returns true if the only changes between the approved
+// commit and the head commit are whitespace changes.
+func isWhitespacePush(prctx pull.Context, approved string) (bool, error) {
+	files, err := prctx.CompareFiles(approved, prctx.HeadSHA())
+	if err != nil {
+		return false, errors.Wrap(err, "failed to compare commits")
+	}
+	if files == nil {
+		return false, nil
+	}
+	return isWhitespaceOnly(files), nil
+}
+
+// isWhitespaceOnly returns true if every file is modified and only changes
+// whitespace. A change is whitespace-only if, within each block of
+// consecutive removed and added lines, the non-blank removed lines equal the
+// non-blank added lines after removing trailing whitespace, including line
+// endings. Changes to indentation or to the whitespace between words are not
+// whitespace-only, because they are significant in many languages and in
+// string literals. Files that are added, deleted, or have no patch, like
+// renames, binary files, and large diffs, are never whitespace-only.
+func isWhitespaceOnly(files []*pull.File) bool {
+	for _, f := range files {
+		if f.Status != pull.FileModified || f.Patch == "" {
+			return false
+		}
+
+		var removed, added []string
+		for _, line := range strings.Split(f.Patch+"\n ", "\n") {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				removed = appendNormalized(removed, line[1:])
+			case strings.HasPrefix(line, "+"):
+				added = appendNormalized(added, line[1:])
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file"
+			default:
+				if !equalLines(removed, added) {
+					return false
+				}
+				removed, added = nil, nil
+			}
+		}
+	}
+	return true
+}
+
+func appendNormalized(lines []string, line string) []string {
+	if normalized := strings.TrimRight(line, " \t\r"); normalized != "" {
+		lines = append(lines, normalized)
+	}
+	return lines
+}
+
+func equalLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package approval
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// Workflows are GitHub Actions workflows that must succeed on the head commit.
+// Only the most recent run of each workflow counts, so a successful re-run
+// replaces an earlier failure and a failed re-run replaces an earlier success.
+type Workflows struct {
+	Names []string `yaml:"names"`
+
+	// AllowSkipped counts runs that skipped all of their jobs as successful.
+	AllowSkipped bool `yaml:"allow_skipped"`
+}
+
+// pending returns a description of the first workflow that has not
+// succeeded, or an empty string if all workflows succeeded
+func (w *Workflows) pending(prctx pull.Context) (string, error) {
+	runs, err := prctx.WorkflowRuns()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get workflow runs")
+	}
+
+	latest := make(map[string]*pull.WorkflowRun)
+	for _, run := range runs {
+		if l, ok := latest[run.Workflow]; !ok || !run.CreatedAt.Before(l.CreatedAt) {
+			latest[run.Workflow] = run
+		}
+	}
+
+	for _, name := range w.Names {
+		run, ok := latest[name]
+		switch {
+		case !ok:
+			return fmt.Sprintf("Waiting for workflow %q to run", name), nil
+		case run.Status != "completed":
+			return fmt.Sprintf("Waiting for workflow %q to complete", name), nil
+		case run.Conclusion == "success":
+		case run.Conclusion == "skipped" && w.AllowSkipped:
+		default:
+			return fmt.Sprintf("Waiting for workflow %q to succeed, the latest run is %s", name, run.Conclusion), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/pull"
+)
+
+// Bypass approves pull requests that have a label applied by one of the
+// actors, regardless of the result of the policy.
+type Bypass struct {
+	Label string `yaml:"label"`
+
+	common.Actors `yaml:",inline"`
+}
+
+func (b *Bypass) validate() error {
+	if b.Label == "" {
+		return errors.New("bypass requires a label")
+	}
+	if b.IsEmpty() && !b.Admins && !b.WriteCollaborators {
+		return errors.New("bypass requires users, organizations, or teams that may apply the label")
+	}
+	return nil
+}
+
+// bypassedBy returns the event that applied the bypass label if the label is
+// on the pull request and the user who applied it is allowed to bypass the
+// policy. Otherwise, it returns nil.
+func (b *Bypass) bypassedBy(ctx context.Context, prctx pull.Context) (*pull.LabelEvent, error) {
+	event, err := prctx.LabeledBy(b.Label)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get bypass label")
+	}
+	if event == nil {
+		return nil, nil
+	}
+
+	allowed, err := b.IsActor(ctx, prctx, event.Actor)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to check bypass user")
+	}
+	if !allowed {
+		zerolog.Ctx(ctx).Debug().Str("user", event.Actor).Msgf("Ignoring label %q applied by user who may not bypass the policy", b.Label)
+		return nil, nil
+	}
+	return event, nil
+}
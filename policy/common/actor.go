@@ -30,6 +30,10 @@ type Actors struct {
 	Teams         []string `yaml:"teams"`
 	Organizations []string `yaml:"organizations"`
 
+	// ApproverLists are the names of lists of users loaded from services
+	// configured by the server.
+	ApproverLists []string `yaml:"approver_lists"`
+
 	// Github repository specific interpolation options
 	Admins             bool `yaml:"admins"`
 	WriteCollaborators bool `yaml:"write_collaborators"`
@@ -42,7 +46,7 @@ const (
 
 // IsEmpty returns true if no conditions for actors are defined.
 func (a *Actors) IsEmpty() bool {
-	return a == nil || (len(a.Users) == 0 && len(a.Teams) == 0 && len(a.Organizations) == 0)
+	return a == nil || (len(a.Users) == 0 && len(a.Teams) == 0 && len(a.Organizations) == 0 && len(a.ApproverLists) == 0)
 }
 
 // IsActor returns true if the given user satisfies at least one of the
@@ -54,6 +58,18 @@ func (a *Actors) IsActor(ctx context.Context, prctx pull.Context, user string) (
 		}
 	}
 
+	for _, name := range a.ApproverLists {
+		users, err := prctx.ApproverList(name)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to get approver list %q", name)
+		}
+		for _, u := range users {
+			if user == u {
+				return true, nil
+			}
+		}
+	}
+
 	for _, t := range a.Teams {
 		member, err := prctx.IsTeamMember(t, user)
 		if err != nil {
@@ -36,6 +36,9 @@ func TestIsActor(t *testing.T) {
 		CollaboratorMemberships: map[string][]string{
 			"mhaypenny": {GithubAdminPermission, GithubWritePermission},
 		},
+		ApproverListsValue: map[string][]string{
+			"release-managers": {"bbaker", "mhaypenny"},
+		},
 	}
 
 	assertActor := func(t *testing.T, a *Actors, user string) {
@@ -77,6 +80,15 @@ func TestIsActor(t *testing.T) {
 		assertNotActor(t, a, "ttest")
 	})
 
+	t.Run("approverLists", func(t *testing.T) {
+		a := &Actors{
+			ApproverLists: []string{"release-managers"},
+		}
+
+		assertActor(t, a, "mhaypenny")
+		assertNotActor(t, a, "ttest")
+	})
+
 	t.Run("admins", func(t *testing.T) {
 		a := &Actors{Admins: true}
 
@@ -105,6 +117,9 @@ func TestIsEmpty(t *testing.T) {
 	a = &Actors{Organizations: []string{"org"}}
 	assert.False(t, a.IsEmpty(), "Actors struct was empty")
 
+	a = &Actors{ApproverLists: []string{"list"}}
+	assert.False(t, a.IsEmpty(), "Actors struct was empty")
+
 	a = nil
 	assert.True(t, a.IsEmpty(), "nil struct was not empty")
 }
@@ -0,0 +1,73 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	shortcodeRegexp = regexp.MustCompile(`:[a-z0-9_+-]+:`)
+
+	// emojiShortcodes maps the GitHub shortcodes of emoji commonly used to
+	// express approval or disapproval to their unicode characters. Every
+	// alias of an emoji maps to the same character.
+	emojiShortcodes = map[string]string{
+		":+1:":                    "👍",
+		":thumbsup:":              "👍",
+		":-1:":                    "👎",
+		":thumbsdown:":            "👎",
+		":white_check_mark:":      "✅",
+		":heavy_check_mark:":      "✔",
+		":ballot_box_with_check:": "☑",
+		":ok_hand:":               "👌",
+		":ok:":                    "🆗",
+		":clap:":                  "👏",
+		":raised_hands:":          "🙌",
+		":muscle:":                "💪",
+		":rocket:":                "🚀",
+		":ship:":                  "🚢",
+		":tada:":                  "🎉",
+		":100:":                   "💯",
+		":heart:":                 "❤",
+		":star:":                  "⭐",
+		":sparkles:":              "✨",
+		":fire:":                  "🔥",
+		":eyes:":                  "👀",
+		":x:":                     "❌",
+		":no_entry:":              "⛔",
+		":no_entry_sign:":         "🚫",
+		":stop_sign:":             "🛑",
+		":warning:":               "⚠",
+	}
+
+	// emojiVariationSelector requests the emoji presentation of a character
+	// and is removed so that "❤️" and "❤" are equivalent
+	emojiVariationSelector = "\ufe0f"
+)
+
+// normalizeEmoji replaces known emoji shortcodes with their unicode
+// characters and removes variation selectors, so that all forms of an emoji
+// compare equal. Unknown shortcodes are not changed.
+func normalizeEmoji(s string) string {
+	s = strings.Replace(s, emojiVariationSelector, "", -1)
+	return shortcodeRegexp.ReplaceAllStringFunc(s, func(code string) string {
+		if e, ok := emojiShortcodes[code]; ok {
+			return e
+		}
+		return code
+	})
+}
@@ -16,6 +16,7 @@ package common
 
 import (
 	"context"
+	"sort"
 	"strings"
 	"time"
 
@@ -26,15 +27,99 @@ type Methods struct {
 	Comments     []string `yaml:"comments,omitempty"`
 	GithubReview bool     `yaml:"github_review,omitempty"`
 
+	// GithubReviewComments enables matching the bodies of commented reviews
+	// against the Comments patterns, for reviewers who leave an approval
+	// phrase instead of selecting the review state.
+	GithubReviewComments bool `yaml:"github_review_comments,omitempty"`
+
+	// WeightedComments are comment patterns that count as more than one
+	// approval. Comments that only match Comments have a weight of one.
+	WeightedComments []WeightedComment `yaml:"weighted_comments,omitempty"`
+
+	// AnchorComments requires comment patterns to appear at the start of the
+	// comment or alone on a line, ignoring surrounding whitespace, instead of
+	// anywhere in the comment.
+	AnchorComments bool `yaml:"anchor_comments,omitempty"`
+
+	// ExcludedComments are patterns that prevent a comment from matching any
+	// of the other comment patterns, like "can't approve". They match
+	// anywhere in the comment, even if AnchorComments is true.
+	ExcludedComments []string `yaml:"excluded_comments,omitempty"`
+
+	// IgnoreCommentCase makes all comment patterns case-insensitive.
+	IgnoreCommentCase bool `yaml:"ignore_comment_case,omitempty"`
+
+	// NormalizeEmoji makes all comment patterns match any form of the emoji
+	// they contain, so that ":+1:", ":thumbsup:", and "👍" are equivalent.
+	NormalizeEmoji bool `yaml:"normalize_emoji,omitempty"`
+
 	// If GithubReview is true, GithubReviewState is the state a review must
 	// have to be considered a candidated. It is currently excluded from
 	// serialized forms and should be set by the application.
 	GithubReviewState pull.ReviewState `yaml:"-" json:"-"`
 }
 
+// DefaultApprovalMethods returns the methods used by approval rules that do
+// not define their own methods.
+func DefaultApprovalMethods() *Methods {
+	return &Methods{
+		Comments: []string{
+			":+1:",
+			"👍",
+		},
+		GithubReview:      true,
+		GithubReviewState: pull.ReviewApproved,
+	}
+}
+
+// IneligibleApprovers returns the users who may not approve a pull request
+// because they changed it: the author, unless allowAuthor or allowContributor
+// is true, and the users associated with the commits, unless allowContributor
+// is true.
+func IneligibleApprovers(author string, commits []*pull.Commit, allowAuthor, allowContributor bool) map[string]bool {
+	ineligible := make(map[string]bool)
+
+	// if contributors are allowed, the author counts as a contributor
+	if !allowAuthor && !allowContributor {
+		ineligible[author] = true
+	}
+
+	if !allowContributor {
+		for _, c := range commits {
+			for _, u := range c.Users() {
+				if u != author {
+					ineligible[u] = true
+				}
+			}
+		}
+	}
+	return ineligible
+}
+
+type WeightedComment struct {
+	Pattern string `yaml:"pattern"`
+	Weight  int    `yaml:"weight"`
+}
+
+type CandidateType int
+
+const (
+	CommentCandidate CandidateType = iota
+	ReviewCandidate
+)
+
 type Candidate struct {
 	User      string
 	CreatedAt time.Time
+	Type      CandidateType
+
+	// Weight is the number of approvals the candidate counts as. Values less
+	// than one are treated as one.
+	Weight int
+
+	// SHA is the commit a review candidate was submitted on. It is empty for
+	// comment candidates.
+	SHA string
 }
 
 type CandidatesByCreationTime []*Candidate
@@ -50,44 +135,74 @@ func (cs CandidatesByCreationTime) Less(i, j int) bool {
 // taken multiple actions that match the methods, only the most recent by event
 // order is included. The order of the candidates is unspecified.
 func (m *Methods) Candidates(ctx context.Context, prctx pull.Context) ([]*Candidate, error) {
+	candidates, err := m.AllCandidates(ctx, prctx)
+	if err != nil {
+		return nil, err
+	}
+	return DeduplicateCandidates(candidates), nil
+}
+
+// AllCandidates returns a candidate for every use of one of the methods,
+// including multiple candidates for the same user.
+func (m *Methods) AllCandidates(ctx context.Context, prctx pull.Context) ([]*Candidate, error) {
 	var candidates []*Candidate
 
-	if len(m.Comments) > 0 {
+	if len(m.Comments) > 0 || len(m.WeightedComments) > 0 {
 		comments, err := prctx.Comments()
 		if err != nil {
 			return nil, err
 		}
 
 		for _, c := range comments {
-			if m.CommentMatches(c.Body) {
+			if weight := m.CommentWeight(c.Body); weight > 0 {
 				candidates = append(candidates, &Candidate{
 					User:      c.Author,
 					CreatedAt: c.CreatedAt,
+					Type:      CommentCandidate,
+					Weight:    weight,
 				})
 			}
 		}
 	}
 
-	if m.GithubReview {
+	if m.GithubReview || m.GithubReviewComments {
 		reviews, err := prctx.Reviews()
 		if err != nil {
 			return nil, err
 		}
 
+		if m.GithubReviewComments {
+			commented, err := prctx.CommentedReviews()
+			if err != nil {
+				return nil, err
+			}
+			reviews = append(reviews[:len(reviews):len(reviews)], commented...)
+		}
+
 		for _, r := range reviews {
-			if r.State == m.GithubReviewState {
+			matchesState := m.GithubReview && r.State == m.GithubReviewState
+			matchesComment := m.GithubReviewComments && r.State == pull.ReviewCommented && m.CommentMatches(r.Body)
+			if matchesState || matchesComment {
+				weight := 1
+				if matchesComment {
+					weight = m.CommentWeight(r.Body)
+				}
 				candidates = append(candidates, &Candidate{
 					User:      r.Author,
 					CreatedAt: r.CreatedAt,
+					Type:      ReviewCandidate,
+					Weight:    weight,
+					SHA:       r.SHA,
 				})
 			}
 		}
 	}
 
-	return deduplicateCandidates(candidates), nil
+	return candidates, nil
 }
 
-func deduplicateCandidates(all []*Candidate) []*Candidate {
+// DeduplicateCandidates returns the latest candidate for each user.
+func DeduplicateCandidates(all []*Candidate) []*Candidate {
 	users := make(map[string]*Candidate)
 	for _, c := range all {
 		last, ok := users[c.User]
@@ -104,12 +219,100 @@ func deduplicateCandidates(all []*Candidate) []*Candidate {
 	return candidates
 }
 
+// ChangeRequesters returns the users whose most recent approving or
+// change-requesting review requested changes, in the order of their first
+// such review. Dismissed reviews are not returned by GitHub, so dismissing a
+// review removes the request.
+func ChangeRequesters(reviews []*pull.Review) []string {
+	sorted := make([]*pull.Review, len(reviews))
+	copy(sorted, reviews)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	var users []string
+	latest := make(map[string]pull.ReviewState)
+	for _, r := range sorted {
+		if r.State != pull.ReviewApproved && r.State != pull.ReviewChangesRequested {
+			continue
+		}
+		if _, ok := latest[r.Author]; !ok {
+			users = append(users, r.Author)
+		}
+		latest[r.Author] = r.State
+	}
+
+	var requesters []string
+	for _, u := range users {
+		if latest[u] == pull.ReviewChangesRequested {
+			requesters = append(requesters, u)
+		}
+	}
+	return requesters
+}
+
 func (m *Methods) CommentMatches(commentBody string) bool {
+	return m.CommentWeight(commentBody) > 0
+}
+
+// CommentWeight returns the largest weight of the patterns contained in the
+// comment, or zero if the comment does not contain any patterns or contains
+// an excluded pattern.
+func (m *Methods) CommentWeight(commentBody string) int {
+	commentBody = m.normalize(commentBody)
+
+	for _, excluded := range m.ExcludedComments {
+		if strings.Contains(commentBody, m.normalize(excluded)) {
+			return 0
+		}
+	}
+
+	weight := 0
 	for _, comment := range m.Comments {
-		if strings.Contains(commentBody, comment) {
-			return true
+		if m.containsPattern(commentBody, comment) {
+			weight = 1
+			break
 		}
 	}
 
+	for _, wc := range m.WeightedComments {
+		w := wc.Weight
+		if w < 1 {
+			w = 1
+		}
+		if w > weight && m.containsPattern(commentBody, wc.Pattern) {
+			weight = w
+		}
+	}
+
+	return weight
+}
+
+// normalize converts comments and patterns to the form used for matching
+func (m *Methods) normalize(s string) string {
+	if m.IgnoreCommentCase {
+		s = strings.ToLower(s)
+	}
+	if m.NormalizeEmoji {
+		s = normalizeEmoji(s)
+	}
+	return s
+}
+
+// containsPattern returns true if the normalized comment contains the pattern
+func (m *Methods) containsPattern(commentBody, pattern string) bool {
+	pattern = m.normalize(pattern)
+	if !m.AnchorComments {
+		return strings.Contains(commentBody, pattern)
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(commentBody), pattern) {
+		return true
+	}
+	for _, line := range strings.Split(commentBody, "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return true
+		}
+	}
 	return false
 }
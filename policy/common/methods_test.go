@@ -114,6 +114,73 @@ func TestCandidates(t *testing.T) {
 		assert.Equal(t, "mhaypenny", cs[0].User)
 		assert.Equal(t, "ttest", cs[1].User)
 	})
+
+	t.Run("reviewComments", func(t *testing.T) {
+		prctx := &pulltest.Context{
+			CommentedReviewsValue: []*pull.Review{
+				{
+					CreatedAt: now.Add(1 * time.Minute),
+					Author:    "rrandom",
+					State:     pull.ReviewCommented,
+					Body:      "LGTM",
+				},
+				{
+					CreatedAt: now.Add(2 * time.Minute),
+					Author:    "mhaypenny",
+					State:     pull.ReviewCommented,
+					Body:      "A few questions first",
+				},
+				{
+					CreatedAt: now.Add(3 * time.Minute),
+					Author:    "ttest",
+					State:     pull.ReviewCommented,
+					Body:      "LGTM once the tests pass",
+				},
+			},
+			ReviewsValue: []*pull.Review{
+				{
+					CreatedAt: now.Add(4 * time.Minute),
+					Author:    "ttest",
+					State:     pull.ReviewApproved,
+				},
+				{
+					CreatedAt: now.Add(5 * time.Minute),
+					Author:    "bbot",
+					State:     pull.ReviewChangesRequested,
+					Body:      "Not LGTM",
+				},
+			},
+		}
+
+		m := &Methods{
+			Comments:             []string{"LGTM"},
+			GithubReview:         true,
+			GithubReviewComments: true,
+			GithubReviewState:    pull.ReviewApproved,
+		}
+
+		cs, err := m.AllCandidates(ctx, prctx)
+		require.NoError(t, err)
+		require.Len(t, cs, 3, "incorrect number of candidates found")
+
+		cs, err = m.Candidates(ctx, prctx)
+		require.NoError(t, err)
+
+		sort.Sort(CandidatesByCreationTime(cs))
+
+		require.Len(t, cs, 2, "incorrect number of candidates found")
+		assert.Equal(t, "rrandom", cs[0].User)
+		assert.Equal(t, "ttest", cs[1].User)
+		assert.Equal(t, ReviewCandidate, cs[0].Type)
+
+		m.GithubReviewComments = false
+
+		cs, err = m.Candidates(ctx, prctx)
+		require.NoError(t, err)
+
+		require.Len(t, cs, 1, "incorrect number of candidates found")
+		assert.Equal(t, "ttest", cs[0].User)
+	})
 }
 
 func TestCandidatesByCreationTime(t *testing.T) {
@@ -142,3 +209,144 @@ func TestCandidatesByCreationTime(t *testing.T) {
 		assert.Equalf(t, u, cs[i].User, "candidate at position %d is incorrect", i)
 	}
 }
+
+func TestChangeRequesters(t *testing.T) {
+	now := time.Now()
+	reviews := []*pull.Review{
+		{Author: "a", State: pull.ReviewChangesRequested, CreatedAt: now.Add(-5 * time.Minute)},
+		{Author: "b", State: pull.ReviewChangesRequested, CreatedAt: now.Add(-4 * time.Minute)},
+		{Author: "b", State: pull.ReviewApproved, CreatedAt: now.Add(-3 * time.Minute)},
+		{Author: "c", State: pull.ReviewApproved, CreatedAt: now.Add(-3 * time.Minute)},
+		{Author: "c", State: pull.ReviewChangesRequested, CreatedAt: now.Add(-2 * time.Minute)},
+		{Author: "a", State: pull.ReviewCommented, CreatedAt: now.Add(-1 * time.Minute)},
+	}
+
+	assert.Equal(t, []string{"a", "c"}, ChangeRequesters(reviews))
+	assert.Empty(t, ChangeRequesters(nil))
+}
+
+func TestCommentWeight(t *testing.T) {
+	m := &Methods{
+		Comments: []string{"approve"},
+		WeightedComments: []WeightedComment{
+			{Pattern: "strong approve", Weight: 2},
+			{Pattern: "invalid weight", Weight: -1},
+		},
+	}
+
+	assert.Equal(t, 0, m.CommentWeight("looks ok"))
+	assert.Equal(t, 1, m.CommentWeight("I approve"))
+	assert.Equal(t, 2, m.CommentWeight("strong approve!"))
+	assert.Equal(t, 1, m.CommentWeight("invalid weight"))
+	assert.True(t, m.CommentMatches("strong approve"))
+}
+
+func TestExcludedComments(t *testing.T) {
+	m := &Methods{
+		Comments:         []string{"approve", ":+1:"},
+		ExcludedComments: []string{"can't approve", "not approve"},
+		WeightedComments: []WeightedComment{
+			{Pattern: "strong approve", Weight: 2},
+		},
+	}
+
+	tests := map[string]struct {
+		Body   string
+		Weight int
+	}{
+		"positive":         {"I approve :+1:", 1},
+		"negative":         {"I can't approve this", 0},
+		"negativeOverlaps": {"I can't approve this yet :+1:", 0},
+		"negativeWeighted": {"this is not approve-worthy, strong approve later", 0},
+		"weighted":         {"strong approve", 2},
+		"similarText":      {"I can approve this", 1},
+		"caseSensitive":    {"Not approved yet, but :+1:", 1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Weight, m.CommentWeight(test.Body))
+		})
+	}
+
+	m.AnchorComments = true
+	assert.Equal(t, 0, m.CommentWeight(":+1:\nbut I can't approve the API"), "excluded patterns should match anywhere")
+}
+
+func TestAnchorComments(t *testing.T) {
+	m := &Methods{
+		Comments:       []string{"approve", ":+1:"},
+		AnchorComments: true,
+		WeightedComments: []WeightedComment{
+			{Pattern: "strong approve", Weight: 2},
+		},
+	}
+
+	tests := map[string]struct {
+		Body   string
+		Weight int
+	}{
+		"start":             {"approve, nice work", 1},
+		"leadingWhitespace": {"  \n\t:+1: thanks", 1},
+		"standaloneLine":    {"Looks good to me.\r\n  :+1:  \r\n", 1},
+		"negation":          {"I do NOT approve", 0},
+		"negationPrefix":    {"not approve", 0},
+		"mention":           {"Waiting for someone to approve\nthis", 0},
+		"lineWithText":      {"Thanks!\n:+1: but fix the typo", 0},
+		"weighted":          {"strong approve", 2},
+		"weightedMention":   {"this is not a strong approve", 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Weight, m.CommentWeight(test.Body))
+		})
+	}
+
+	m.AnchorComments = false
+	assert.Equal(t, 1, m.CommentWeight("I do NOT approve"), "unanchored patterns should match anywhere")
+}
+
+func TestNormalizedComments(t *testing.T) {
+	m := &Methods{
+		Comments:          []string{":+1:", "lgtm"},
+		ExcludedComments:  []string{"not yet"},
+		IgnoreCommentCase: true,
+		NormalizeEmoji:    true,
+		WeightedComments: []WeightedComment{
+			{Pattern: "👍👍", Weight: 2},
+		},
+	}
+
+	tests := map[string]struct {
+		Body   string
+		Weight int
+	}{
+		"shortcode":         {"Nice work :+1:", 1},
+		"shortcodeAlias":    {"Nice work :thumbsup:", 1},
+		"unicode":           {"Nice work 👍", 1},
+		"skinTone":          {"👍🏽", 1},
+		"uppercase":         {"LGTM", 1},
+		"mixedCase":         {"Lgtm!", 1},
+		"uppercaseAlias":    {":THUMBSUP:", 1},
+		"weightedMixed":     {":thumbsup:👍", 2},
+		"excludedUppercase": {"NOT YET :+1:", 0},
+		"unknownShortcode":  {":shipit:", 0},
+		"otherEmoji":        {":-1:", 0},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Weight, m.CommentWeight(test.Body))
+		})
+	}
+
+	m.Comments = []string{"❤\ufe0f"}
+	assert.Equal(t, 1, m.CommentWeight("❤"), "variation selector should be ignored")
+	assert.Equal(t, 1, m.CommentWeight(":heart:"))
+
+	m.IgnoreCommentCase = false
+	m.NormalizeEmoji = false
+	m.Comments = []string{":+1:", "lgtm"}
+	assert.Equal(t, 0, m.CommentWeight(":thumbsup: LGTM"), "normalization should be disabled by default")
+}
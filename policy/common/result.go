@@ -37,10 +37,47 @@ func (s EvaluationStatus) String() string {
 	return "unknown"
 }
 
+// Reason is a machine-readable explanation of the status of a Result. Results
+// that are approved or have nothing to report have an empty reason.
+type Reason string
+
+const (
+	ReasonError                     Reason = "ERROR"
+	ReasonPredicatesNotSatisfied    Reason = "PREDICATES_NOT_SATISFIED"
+	ReasonRulesSkipped              Reason = "RULES_SKIPPED"
+	ReasonRulesPending              Reason = "RULES_PENDING"
+	ReasonInsufficientApprovals     Reason = "INSUFFICIENT_APPROVALS"
+	ReasonDisqualifiedApprovals     Reason = "DISQUALIFIED_APPROVALS"
+	ReasonPendingStatus             Reason = "PENDING_STATUS"
+	ReasonPendingWorkflow           Reason = "PENDING_WORKFLOW"
+	ReasonPendingExternalCheck      Reason = "PENDING_EXTERNAL_CHECK"
+	ReasonRequiresOutsideDepartment Reason = "REQUIRES_OUTSIDE_DEPARTMENT"
+	ReasonRequiresFileCoverage      Reason = "REQUIRES_FILE_COVERAGE"
+	ReasonRequiresLastAuthors       Reason = "REQUIRES_LAST_AUTHORS"
+	ReasonRequiresComponentApproval Reason = "REQUIRES_COMPONENT_APPROVAL"
+	ReasonChangesRequested          Reason = "CHANGES_REQUESTED"
+	ReasonForcePushed               Reason = "FORCE_PUSHED"
+	ReasonRequiresQuorum            Reason = "REQUIRES_QUORUM"
+	ReasonRequiresTemplateSections  Reason = "REQUIRES_TEMPLATE_SECTIONS"
+	ReasonBypassed                  Reason = "BYPASSED"
+	ReasonPolicySkipped             Reason = "POLICY_SKIPPED"
+	ReasonDisapproved               Reason = "DISAPPROVED"
+)
+
 type Result struct {
 	Name        string
 	Description string
 	Status      EvaluationStatus
+	Reason      Reason
+
+	// RuleDescription is the user-provided description of the rule that
+	// produced this result, if any
+	RuleDescription string
+
+	// Overdue is true if the result is pending and the rule has been pending
+	// for longer than its overdue_after option. The reason still explains why
+	// the rule is pending.
+	Overdue bool
 
 	Error error
 
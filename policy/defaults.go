@@ -0,0 +1,75 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ApplyRuleDefaults merges the "rule_defaults" in the options of a policy
+// beneath the options of every approval rule and returns the new policy. Maps
+// are merged recursively; other values set by a rule replace the default. The
+// policy is returned unchanged if it does not define rule defaults.
+func ApplyRuleDefaults(b []byte) ([]byte, error) {
+	var config map[interface{}]interface{}
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal policy")
+	}
+
+	options, _ := config["options"].(map[interface{}]interface{})
+	defaults, ok := options["rule_defaults"].(map[interface{}]interface{})
+	if !ok {
+		return b, nil
+	}
+
+	rules, _ := config["approval_rules"].([]interface{})
+	for _, r := range rules {
+		if rule, ok := r.(map[interface{}]interface{}); ok {
+			rule["options"] = mergeDefaults(defaults, rule["options"])
+		}
+	}
+
+	merged, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal policy")
+	}
+	return merged, nil
+}
+
+func mergeDefaults(defaults, value interface{}) interface{} {
+	if value == nil {
+		return defaults
+	}
+
+	d, ok := defaults.(map[interface{}]interface{})
+	if !ok {
+		return value
+	}
+
+	v, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return value
+	}
+
+	merged := make(map[interface{}]interface{}, len(d)+len(v))
+	for k, dv := range d {
+		merged[k] = dv
+	}
+	for k, vv := range v {
+		merged[k] = mergeDefaults(d[k], vv)
+	}
+	return merged
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
@@ -33,6 +34,11 @@ type Policy struct {
 
 type Options struct {
 	Methods Methods `yaml:"methods"`
+
+	// BlockOnChangesRequested disapproves the pull request while the most
+	// recent review of any allowed user requests changes. Only that user can
+	// revoke the disapproval, by approving or by dismissing the review.
+	BlockOnChangesRequested bool `yaml:"block_on_changes_requested"`
 }
 
 type Methods struct {
@@ -92,12 +98,14 @@ func (p *Policy) Evaluate(ctx context.Context, prctx pull.Context) (res common.R
 	disapproved, msg, err := p.IsDisapproved(ctx, prctx)
 	if err != nil {
 		res.Error = errors.WithMessage(err, "failed to compute disapproval status")
+		res.Reason = common.ReasonError
 		return
 	}
 
 	res.Description = msg
 	if disapproved {
 		res.Status = common.StatusDisapproved
+		res.Reason = common.ReasonDisapproved
 	} else {
 		res.Status = common.StatusSkipped
 	}
@@ -105,6 +113,16 @@ func (p *Policy) Evaluate(ctx context.Context, prctx pull.Context) (res common.R
 }
 
 func (p *Policy) IsDisapproved(ctx context.Context, prctx pull.Context) (disapproved bool, msg string, err error) {
+	if p.Options.BlockOnChangesRequested {
+		requesters, err := p.changeRequesters(ctx, prctx)
+		if err != nil {
+			return false, "", errors.WithMessage(err, "failed to get change requesters")
+		}
+		if len(requesters) > 0 {
+			return true, fmt.Sprintf("Changes requested by %s", strings.Join(requesters, ", ")), nil
+		}
+	}
+
 	disapproveMethods := p.Options.GetDisapproveMethods()
 	revokeMethods := p.Options.GetRevokeMethods()
 
@@ -182,6 +200,31 @@ func (p *Policy) filter(ctx context.Context, prctx pull.Context, candidates []*c
 	return filtered, nil
 }
 
+// changeRequesters returns the allowed users whose most recent review requested
+// changes.
+func (p *Policy) changeRequesters(ctx context.Context, prctx pull.Context) ([]string, error) {
+	log := zerolog.Ctx(ctx)
+
+	reviews, err := prctx.Reviews()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list reviews")
+	}
+
+	var requesters []string
+	for _, u := range common.ChangeRequesters(reviews) {
+		ok, err := p.Requires.IsActor(ctx, prctx, u)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed to check reviewer status")
+		}
+		if !ok {
+			log.Debug().Str("user", u).Msg("ignoring requested changes by non-whitelisted user")
+			continue
+		}
+		requesters = append(requesters, u)
+	}
+	return requesters, nil
+}
+
 func last(c []*common.Candidate) *common.Candidate {
 	if len(c) == 0 {
 		return nil
@@ -145,6 +145,75 @@ func TestIsDisapproved(t *testing.T) {
 
 		assertDisapproved(t, p, "Disapproved by disapprover-4")
 	})
+
+	t.Run("blockOnChangesRequested", func(t *testing.T) {
+		p := &Policy{}
+		p.Options.BlockOnChangesRequested = true
+		p.Requires.Users = []string{"disapprover-4", "revoker-2"}
+
+		// other users cannot revoke a request for changes
+		assertDisapproved(t, p, "Changes requested by disapprover-4")
+
+		// requests from users who may not disapprove are ignored
+		p.Requires.Users = []string{"revoker-2"}
+		assertSkipped(t, p, "No disapprovals")
+	})
+
+	t.Run("blockOnChangesRequestedCycle", func(t *testing.T) {
+		p := &Policy{}
+		p.Options.BlockOnChangesRequested = true
+		p.Requires.Users = []string{"reviewer"}
+
+		reviewCtx := &pulltest.Context{
+			ReviewsValue: []*pull.Review{
+				{
+					Author:    "reviewer",
+					State:     pull.ReviewChangesRequested,
+					CreatedAt: date(0),
+				},
+				{
+					Author:    "reviewer",
+					State:     pull.ReviewCommented,
+					CreatedAt: date(1),
+				},
+			},
+		}
+
+		disapproved, msg, err := p.IsDisapproved(ctx, reviewCtx)
+		require.NoError(t, err)
+		assert.True(t, disapproved, "comment review removed the block")
+		assert.Equal(t, "Changes requested by reviewer", msg)
+
+		// the reviewer approves
+		reviewCtx.ReviewsValue = append(reviewCtx.ReviewsValue, &pull.Review{
+			Author:    "reviewer",
+			State:     pull.ReviewApproved,
+			CreatedAt: date(2),
+		})
+
+		disapproved, msg, err = p.IsDisapproved(ctx, reviewCtx)
+		require.NoError(t, err)
+		assert.False(t, disapproved, "approval did not remove the block")
+		assert.Equal(t, "Disapproval revoked by reviewer", msg)
+
+		// the reviewer requests changes again
+		reviewCtx.ReviewsValue = append(reviewCtx.ReviewsValue, &pull.Review{
+			Author:    "reviewer",
+			State:     pull.ReviewChangesRequested,
+			CreatedAt: date(3),
+		})
+
+		disapproved, _, err = p.IsDisapproved(ctx, reviewCtx)
+		require.NoError(t, err)
+		assert.True(t, disapproved, "new request for changes did not block")
+
+		// dismissed reviews are not returned
+		reviewCtx.ReviewsValue = reviewCtx.ReviewsValue[:3]
+
+		disapproved, _, err = p.IsDisapproved(ctx, reviewCtx)
+		require.NoError(t, err)
+		assert.False(t, disapproved, "dismissal did not remove the block")
+	})
 }
 
 func date(hour int) time.Time {
@@ -0,0 +1,177 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// MaxIncludeDepth is the maximum number of nested includes in a policy
+const MaxIncludeDepth = 10
+
+// IncludeLoader returns the contents of the file at a path in the repository
+// that contains the policy, or a nil slice if the file does not exist.
+type IncludeLoader func(path string) ([]byte, error)
+
+// ResolveIncludes loads the files listed by the "include" key of a policy and
+// returns the policy with their approval rules appended to its own. Included
+// files may only define "approval_rules" and "include"; their includes are
+// resolved recursively. Paths are relative to the root of the repository. The
+// returned map contains the path of the file that defines each included rule.
+// The policy is returned unchanged if it does not include any files.
+func ResolveIncludes(b []byte, policyPath string, load IncludeLoader) ([]byte, map[string]string, error) {
+	var config map[interface{}]interface{}
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to unmarshal policy")
+	}
+	if _, ok := config["include"]; !ok {
+		return b, nil, nil
+	}
+
+	r := includeResolver{
+		load:     load,
+		sources:  make(map[string]string),
+		resolved: make(map[string]bool),
+	}
+	rules, err := r.rules(config, []string{cleanIncludePath(policyPath)})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defined := make(map[string]bool)
+	for _, rule := range rules {
+		name, _ := includedRuleName(rule)
+		if defined[name] {
+			return nil, nil, errors.Errorf("approval rule %q is defined more than once", name)
+		}
+		defined[name] = true
+	}
+
+	delete(config, "include")
+	config["approval_rules"] = rules
+
+	resolved, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to marshal policy")
+	}
+	return resolved, r.sources, nil
+}
+
+type includeResolver struct {
+	load     IncludeLoader
+	sources  map[string]string
+	resolved map[string]bool
+}
+
+// rules returns the approval rules of the file at the end of the stack,
+// followed by the rules of the files it includes. Files that were already
+// included by another file are skipped, so their rules are only added once.
+func (r *includeResolver) rules(config map[interface{}]interface{}, stack []string) ([]interface{}, error) {
+	current := stack[len(stack)-1]
+
+	rules, ok := config["approval_rules"].([]interface{})
+	if !ok && config["approval_rules"] != nil {
+		return nil, errors.Errorf("approval_rules in %s must be a list", current)
+	}
+
+	includes, err := includePaths(config["include"])
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid include in "+current)
+	}
+
+	for _, p := range includes {
+		for _, s := range stack {
+			if s == p {
+				return nil, errors.Errorf("include cycle: %s", strings.Join(append(stack, p), " -> "))
+			}
+		}
+		if len(stack) > MaxIncludeDepth {
+			return nil, errors.Errorf("%s included by %s exceeds the maximum include depth of %d", p, current, MaxIncludeDepth)
+		}
+		if r.resolved[p] {
+			continue
+		}
+		r.resolved[p] = true
+
+		b, err := r.load(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load %s included by %s", p, current)
+		}
+		if b == nil {
+			return nil, errors.Errorf("%s included by %s does not exist", p, current)
+		}
+
+		var included map[interface{}]interface{}
+		if err := yaml.Unmarshal(b, &included); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal %s included by %s", p, current)
+		}
+		for k := range included {
+			if k != "approval_rules" && k != "include" {
+				return nil, errors.Errorf("%s included by %s may only define approval_rules and include, found %q", p, current, k)
+			}
+		}
+
+		includedRules, err := r.rules(included, append(stack, p))
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range includedRules {
+			if name, ok := includedRuleName(rule); ok {
+				if _, seen := r.sources[name]; !seen {
+					r.sources[name] = p
+				}
+			}
+		}
+		rules = append(rules, includedRules...)
+	}
+	return rules, nil
+}
+
+func includePaths(value interface{}) ([]string, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, errors.New("include must be a list of paths")
+	}
+
+	paths := make([]string, 0, len(list))
+	for _, v := range list {
+		p, ok := v.(string)
+		if !ok || p == "" {
+			return nil, errors.New("include must be a list of paths")
+		}
+		paths = append(paths, cleanIncludePath(p))
+	}
+	return paths, nil
+}
+
+func cleanIncludePath(p string) string {
+	return strings.TrimPrefix(path.Clean("/"+p), "/")
+}
+
+func includedRuleName(rule interface{}) (string, bool) {
+	if m, ok := rule.(map[interface{}]interface{}); ok {
+		name, ok := m["name"].(string)
+		return name, ok
+	}
+	return "", false
+}
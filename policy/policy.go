@@ -16,12 +16,16 @@ package policy
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 
 	"github.com/palantir/policy-bot/policy/approval"
 	"github.com/palantir/policy-bot/policy/common"
 	"github.com/palantir/policy-bot/policy/disapproval"
+	"github.com/palantir/policy-bot/policy/predicate"
 	"github.com/palantir/policy-bot/pull"
 )
 
@@ -38,14 +42,150 @@ type RemoteConfig struct {
 type Config struct {
 	Policy        Policy           `yaml:"policy"`
 	ApprovalRules []*approval.Rule `yaml:"approval_rules"`
+	Options       Options          `yaml:"options"`
+}
+
+// Events that can trigger an evaluation of the policy
+const (
+	EventPullRequest       = "pull_request"
+	EventPullRequestReview = "pull_request_review"
+	EventIssueComment      = "issue_comment"
+	EventStatus            = "status"
+	EventWorkflowRun       = "workflow_run"
+	EventCheckRun          = "check_run"
+)
+
+// Outcomes for pull requests that match none of the approval rules
+const (
+	NoMatchingRulesError   = "error"
+	NoMatchingRulesApprove = "approve"
+	NoMatchingRulesPending = "pending"
+)
+
+// Options configures when the policy is evaluated.
+type Options struct {
+	// EvaluateOn lists the webhook events that trigger evaluation. If it is
+	// empty, all events trigger evaluation.
+	EvaluateOn []string `yaml:"evaluate_on"`
+
+	// RuleDefaults are the default options of every approval rule. They are
+	// applied by ApplyRuleDefaults before the policy is unmarshaled and are
+	// only kept here so that unknown options are rejected.
+	RuleDefaults *approval.Options `yaml:"rule_defaults"`
+
+	// Bypass approves pull requests with a label applied by an allowed user,
+	// regardless of the rest of the policy.
+	Bypass *Bypass `yaml:"bypass"`
+
+	// StatusTemplates customize the status description and the summary on
+	// the details page.
+	StatusTemplates *StatusTemplates `yaml:"status_templates"`
+
+	// NoMatchingRules is the outcome for pull requests that match none of
+	// the approval rules. If empty, the policy reports an error.
+	NoMatchingRules string `yaml:"no_matching_rules"`
+}
+
+// IsCommitEvent returns true if events of the given type are about a commit
+// instead of a pull request, so they only affect policies that use the
+// results of CI on the commit.
+func IsCommitEvent(eventType string) bool {
+	switch eventType {
+	case EventStatus, EventWorkflowRun, EventCheckRun:
+		return true
+	}
+	return false
+}
+
+// DependsOn returns false if events of the given type cannot change the
+// result of the policy. Commit events only affect policies with requirements
+// or conditions that use the statuses of the head commit.
+func (c *Config) DependsOn(eventType string) bool {
+	switch eventType {
+	case EventStatus, EventCheckRun:
+		return c.usesStatuses()
+	case EventWorkflowRun:
+		// check runs of GitHub Actions jobs are evaluated when their workflow
+		// run completes, so statuses depend on workflow runs as well
+		return c.usesStatuses() || c.usesWorkflows()
+	}
+	return true
+}
+
+// usesStatuses returns true if any rule or condition of the policy uses the
+// statuses or check runs of the head commit.
+func (c *Config) usesStatuses() bool {
+	if c.Policy.If != nil && c.Policy.If.HasMissingStatus != nil {
+		return true
+	}
+	for _, r := range c.ApprovalRules {
+		if len(r.Requires.Statuses) > 0 || len(r.Requires.CheckRuns) > 0 || r.Predicates.HasMissingStatus != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// usesWorkflows returns true if any rule of the policy requires workflows to
+// succeed on the head commit.
+func (c *Config) usesWorkflows() bool {
+	for _, r := range c.ApprovalRules {
+		if r.Requires.Workflows != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// EvaluatesOn returns true if the event type triggers evaluation.
+func (opts *Options) EvaluatesOn(eventType string) bool {
+	if len(opts.EvaluateOn) == 0 {
+		return true
+	}
+	for _, e := range opts.EvaluateOn {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
 }
 
 type Policy struct {
+	// If are predicates that must all be satisfied for the policy to apply.
+	// Otherwise, the policy is skipped and the pull request is approved.
+	If *approval.Predicates `yaml:"if"`
+
 	Approval    approval.Policy     `yaml:"approval"`
 	Disapproval *disapproval.Policy `yaml:"disapproval"`
 }
 
 func ParsePolicy(c *Config) (common.Evaluator, error) {
+	for _, e := range c.Options.EvaluateOn {
+		switch e {
+		case EventPullRequest, EventPullRequestReview, EventIssueComment, EventStatus, EventWorkflowRun, EventCheckRun:
+		default:
+			return nil, errors.Errorf("failed to parse options: unknown event %q", e)
+		}
+	}
+
+	if c.Options.Bypass != nil {
+		if err := c.Options.Bypass.validate(); err != nil {
+			return nil, errors.WithMessage(err, "failed to parse options")
+		}
+	}
+
+	if c.Options.StatusTemplates != nil {
+		if err := c.Options.StatusTemplates.validate(); err != nil {
+			return nil, errors.WithMessage(err, "failed to parse options")
+		}
+	}
+
+	switch c.Options.NoMatchingRules {
+	case "", NoMatchingRulesError, NoMatchingRulesApprove, NoMatchingRulesPending:
+	default:
+		return nil, errors.Errorf("failed to parse options: unknown no_matching_rules outcome %q", c.Options.NoMatchingRules)
+	}
+
 	rulesByName := make(map[string]*approval.Rule)
 	for _, r := range c.ApprovalRules {
 		rulesByName[r.Name] = r
@@ -61,22 +201,58 @@ func ParsePolicy(c *Config) (common.Evaluator, error) {
 		evalDisapproval = &disapproval.Policy{}
 	}
 
+	var conditions []predicate.Predicate
+	if c.Policy.If != nil {
+		if err := c.Policy.If.Validate(); err != nil {
+			return nil, errors.WithMessage(err, "failed to parse policy conditions")
+		}
+		conditions = c.Policy.If.Predicates()
+	}
+
 	return evaluator{
 		approval:    evalApproval,
 		disapproval: evalDisapproval,
+		bypass:      c.Options.Bypass,
+		conditions:  conditions,
+		noMatch:     c.Options.NoMatchingRules,
 	}, nil
 }
 
 type evaluator struct {
 	approval    common.Evaluator
 	disapproval common.Evaluator
+	bypass      *Bypass
+	conditions  []predicate.Predicate
+	noMatch     string
 }
 
 func (e evaluator) Evaluate(ctx context.Context, prctx pull.Context) (res common.Result) {
+	res.Name = "policy"
+
+	for _, p := range e.conditions {
+		satisfied, desc, err := p.Evaluate(ctx, prctx)
+		if err != nil {
+			res.Error = errors.Wrap(err, "failed to evaluate policy condition")
+			res.Reason = common.ReasonError
+			return
+		}
+
+		if !satisfied {
+			zerolog.Ctx(ctx).Debug().Msgf("skipping policy, condition of type %T was not satisfied", p)
+
+			if desc == "" {
+				desc = "The conditions of the policy are not satisfied"
+			}
+			res.Status = common.StatusApproved
+			res.Reason = common.ReasonPolicySkipped
+			res.Description = "Policy skipped: " + desc
+			return
+		}
+	}
+
 	disapproval := e.disapproval.Evaluate(ctx, prctx)
 	approval := e.approval.Evaluate(ctx, prctx)
 
-	res.Name = "policy"
 	res.Children = []*common.Result{&approval, &disapproval}
 
 	for _, r := range res.Children {
@@ -87,12 +263,49 @@ func (e evaluator) Evaluate(ctx context.Context, prctx pull.Context) (res common
 
 	switch {
 	case res.Error != nil:
+		res.Reason = common.ReasonError
 	case disapproval.Status == common.StatusDisapproved:
 		res.Status = common.StatusDisapproved
+		res.Reason = disapproval.Reason
 		res.Description = disapproval.Description
 	default:
 		res.Status = approval.Status
+		res.Reason = approval.Reason
 		res.Description = approval.Description
+		if res.Status == common.StatusSkipped {
+			e.applyNoMatch(ctx, &res)
+		}
+	}
+
+	if e.bypass != nil {
+		event, err := e.bypass.bypassedBy(ctx, prctx)
+		switch {
+		case err != nil:
+			res.Error = err
+			res.Reason = common.ReasonError
+		case event != nil:
+			// the bypass applies even if the policy cannot be evaluated
+			res.Error = nil
+			res.Status = common.StatusApproved
+			res.Reason = common.ReasonBypassed
+			res.Description = fmt.Sprintf("Bypassed by %s with label %q at %s", event.Actor, event.Label, event.CreatedAt.UTC().Format(time.RFC3339))
+		}
 	}
 	return
 }
+
+// applyNoMatch sets the configured outcome on the result of a policy where
+// all approval rules were skipped. Skipped results are reported as errors.
+func (e evaluator) applyNoMatch(ctx context.Context, res *common.Result) {
+	switch e.noMatch {
+	case NoMatchingRulesApprove:
+		res.Status = common.StatusApproved
+		res.Description = "No approval rules match this pull request, so it is approved"
+	case NoMatchingRulesPending:
+		res.Status = common.StatusPending
+		res.Description = "No approval rules match this pull request. Waiting for a rule to apply"
+	default:
+		return
+	}
+	zerolog.Ctx(ctx).Debug().Msgf("all approval rules were skipped, using outcome %q", e.noMatch)
+}
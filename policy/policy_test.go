@@ -18,11 +18,15 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 
+	"github.com/palantir/policy-bot/policy/approval"
 	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/policy/predicate"
 	"github.com/palantir/policy-bot/pull"
 	"github.com/palantir/policy-bot/pull/pulltest"
 )
@@ -73,6 +77,54 @@ func TestEvaluator(t *testing.T) {
 		assert.Equal(t, "2 approvals needed", r.Description)
 	})
 
+	t.Run("noMatchingRules", func(t *testing.T) {
+		tests := map[string]struct {
+			Outcome     string
+			Status      common.EvaluationStatus
+			Description string
+		}{
+			"default": {"", common.StatusSkipped, "All of the rules are skipped"},
+			"error":   {NoMatchingRulesError, common.StatusSkipped, "All of the rules are skipped"},
+			"approve": {NoMatchingRulesApprove, common.StatusApproved, "No approval rules match this pull request, so it is approved"},
+			"pending": {NoMatchingRulesPending, common.StatusPending, "No approval rules match this pull request. Waiting for a rule to apply"},
+		}
+
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				eval := evaluator{
+					approval: &StaticEvaluator{
+						Status:      common.StatusSkipped,
+						Reason:      common.ReasonRulesSkipped,
+						Description: "All of the rules are skipped",
+					},
+					disapproval: &StaticEvaluator{
+						Status: common.StatusSkipped,
+					},
+					noMatch: test.Outcome,
+				}
+
+				r := eval.Evaluate(ctx, prctx)
+				require.NoError(t, r.Error)
+
+				assert.Equal(t, test.Status, r.Status)
+				assert.Equal(t, common.ReasonRulesSkipped, r.Reason)
+				assert.Equal(t, test.Description, r.Description)
+			})
+		}
+
+		// disapproval takes precedence over the outcome
+		eval := evaluator{
+			approval: &StaticEvaluator{
+				Status: common.StatusSkipped,
+			},
+			disapproval: &StaticEvaluator{
+				Status: common.StatusDisapproved,
+			},
+			noMatch: NoMatchingRulesApprove,
+		}
+		assert.Equal(t, common.StatusDisapproved, eval.Evaluate(ctx, prctx).Status)
+	})
+
 	t.Run("propagateError", func(t *testing.T) {
 		eval := evaluator{
 			approval: &StaticEvaluator{
@@ -108,8 +160,386 @@ func TestEvaluator(t *testing.T) {
 			assert.Equal(t, castToResult(eval.disapproval), r.Children[1])
 		}
 	})
+
+	t.Run("bypass", func(t *testing.T) {
+		eval := evaluator{
+			approval: &StaticEvaluator{
+				Status: common.StatusPending,
+			},
+			disapproval: &StaticEvaluator{
+				Status: common.StatusDisapproved,
+			},
+			bypass: &Bypass{
+				Label:  "emergency",
+				Actors: common.Actors{Teams: []string{"org/oncall"}},
+			},
+		}
+
+		labeled := &pull.LabelEvent{
+			Label:     "emergency",
+			Actor:     "mhaypenny",
+			CreatedAt: time.Date(2020, 5, 20, 12, 38, 56, 0, time.UTC),
+		}
+
+		bypassCtx := &pulltest.Context{
+			LabelEventsValue: map[string]*pull.LabelEvent{"emergency": labeled},
+			TeamMemberships:  map[string][]string{"mhaypenny": {"org/oncall"}},
+		}
+
+		r := eval.Evaluate(ctx, bypassCtx)
+		require.NoError(t, r.Error)
+
+		assert.Equal(t, common.StatusApproved, r.Status)
+		assert.Equal(t, common.ReasonBypassed, r.Reason)
+		assert.Equal(t, `Bypassed by mhaypenny with label "emergency" at 2020-05-20T12:38:56Z`, r.Description)
+		assert.Len(t, r.Children, 2)
+
+		unauthorizedCtx := &pulltest.Context{
+			LabelEventsValue: map[string]*pull.LabelEvent{"emergency": labeled},
+		}
+
+		r = eval.Evaluate(ctx, unauthorizedCtx)
+		require.NoError(t, r.Error)
+
+		assert.Equal(t, common.StatusDisapproved, r.Status)
+		assert.NotEqual(t, common.ReasonBypassed, r.Reason)
+
+		removedCtx := &pulltest.Context{
+			TeamMemberships: map[string][]string{"mhaypenny": {"org/oncall"}},
+		}
+
+		r = eval.Evaluate(ctx, removedCtx)
+		require.NoError(t, r.Error)
+
+		assert.Equal(t, common.StatusDisapproved, r.Status)
+
+		errorCtx := &pulltest.Context{
+			LabelEventError: errors.New("events failed"),
+		}
+
+		r = eval.Evaluate(ctx, errorCtx)
+		assert.EqualError(t, r.Error, "failed to get bypass label: events failed")
+		assert.Equal(t, common.ReasonError, r.Reason)
+	})
+
+	t.Run("conditions", func(t *testing.T) {
+		eval := evaluator{
+			approval: &StaticEvaluator{
+				Status: common.StatusPending,
+			},
+			disapproval: &StaticEvaluator{
+				Status: common.StatusDisapproved,
+			},
+			conditions: []predicate.Predicate{
+				&predicate.TargetsBranch{Pattern: "^master$"},
+			},
+		}
+
+		docsCtx := &pulltest.Context{
+			BranchBaseName: "docs",
+		}
+
+		r := eval.Evaluate(ctx, docsCtx)
+		require.NoError(t, r.Error)
+
+		assert.Equal(t, common.StatusApproved, r.Status)
+		assert.Equal(t, common.ReasonPolicySkipped, r.Reason)
+		assert.Equal(t, "Policy skipped: Target branch \"docs\" does not match required pattern \"^master$\"", r.Description)
+		assert.Empty(t, r.Children)
+
+		masterCtx := &pulltest.Context{
+			BranchBaseName: "master",
+		}
+
+		r = eval.Evaluate(ctx, masterCtx)
+		require.NoError(t, r.Error)
+
+		assert.Equal(t, common.StatusDisapproved, r.Status)
+		assert.Len(t, r.Children, 2)
+
+		eval.conditions = []predicate.Predicate{&predicate.TargetsBranch{Pattern: "("}}
+
+		r = eval.Evaluate(ctx, masterCtx)
+		assert.EqualError(t, r.Error, "failed to evaluate policy condition: failed to compile the target regex: error parsing regexp: missing closing ): `(`")
+		assert.Equal(t, common.ReasonError, r.Reason)
+	})
+}
+
+func TestParsePolicyEvents(t *testing.T) {
+	_, err := ParsePolicy(&Config{
+		Options: Options{EvaluateOn: []string{EventPullRequest, EventIssueComment, EventStatus, EventWorkflowRun, EventCheckRun}},
+	})
+	assert.NoError(t, err)
+
+	_, err = ParsePolicy(&Config{
+		Options: Options{EvaluateOn: []string{EventPullRequest, "push"}},
+	})
+	assert.EqualError(t, err, `failed to parse options: unknown event "push"`)
+}
+
+func TestParsePolicyNoMatchingRules(t *testing.T) {
+	_, err := ParsePolicy(&Config{
+		Options: Options{NoMatchingRules: NoMatchingRulesPending},
+	})
+	assert.NoError(t, err)
+
+	_, err = ParsePolicy(&Config{
+		Options: Options{NoMatchingRules: "skip"},
+	})
+	assert.EqualError(t, err, `failed to parse options: unknown no_matching_rules outcome "skip"`)
+}
+
+func TestParsePolicyBypass(t *testing.T) {
+	_, err := ParsePolicy(&Config{
+		Options: Options{Bypass: &Bypass{
+			Label:  "emergency",
+			Actors: common.Actors{Users: []string{"mhaypenny"}},
+		}},
+	})
+	assert.NoError(t, err)
+
+	_, err = ParsePolicy(&Config{
+		Options: Options{Bypass: &Bypass{
+			Actors: common.Actors{Users: []string{"mhaypenny"}},
+		}},
+	})
+	assert.EqualError(t, err, "failed to parse options: bypass requires a label")
+
+	_, err = ParsePolicy(&Config{
+		Options: Options{Bypass: &Bypass{Label: "emergency"}},
+	})
+	assert.EqualError(t, err, "failed to parse options: bypass requires users, organizations, or teams that may apply the label")
+}
+
+func TestStatusTemplates(t *testing.T) {
+	data := &StatusData{
+		Status:       "pending",
+		Description:  "0/1 rules approved",
+		PendingRules: []string{"api", "docs"},
+		Owner:        "palantir",
+		Repo:         "policy-bot",
+		Number:       42,
+		Author:       "mhaypenny",
+	}
+
+	templates := &StatusTemplates{
+		Description: "[{{.Status}}] waiting on {{len .PendingRules}} rules: {{join .PendingRules \", \"}}",
+	}
+
+	desc, err := templates.RenderDescription(data)
+	require.NoError(t, err)
+	assert.Equal(t, "[pending] waiting on 2 rules: api, docs", desc)
+
+	summary, err := templates.RenderSummary(data)
+	require.NoError(t, err)
+	assert.Equal(t, "0/1 rules approved", summary, "empty template did not use the default")
+
+	templates.Summary = "{{.Author}} opened {{.Owner}}/{{.Repo}}#{{.Number}}. {{.Description}}"
+	summary, err = templates.RenderSummary(data)
+	require.NoError(t, err)
+	assert.Equal(t, "mhaypenny opened palantir/policy-bot#42. 0/1 rules approved", summary)
+
+	_, err = ParsePolicy(&Config{
+		Options: Options{StatusTemplates: templates},
+	})
+	assert.NoError(t, err)
+
+	_, err = ParsePolicy(&Config{
+		Options: Options{StatusTemplates: &StatusTemplates{Description: "{{.Status"}},
+	})
+	assert.Error(t, err, "invalid template syntax was accepted")
+
+	_, err = ParsePolicy(&Config{
+		Options: Options{StatusTemplates: &StatusTemplates{Summary: "{{.Title}}"}},
+	})
+	assert.Error(t, err, "unknown field was accepted")
+}
+
+func TestEvaluatesOn(t *testing.T) {
+	opts := &Options{}
+	assert.True(t, opts.EvaluatesOn(EventIssueComment), "empty options should evaluate on all events")
+
+	opts.EvaluateOn = []string{EventPullRequest}
+	assert.True(t, opts.EvaluatesOn(EventPullRequest))
+	assert.False(t, opts.EvaluatesOn(EventIssueComment))
+}
+
+func TestApplyRuleDefaults(t *testing.T) {
+	b, err := ApplyRuleDefaults([]byte(`
+options:
+  rule_defaults:
+    invalidate_on_push: true
+    ignore_update_merges: true
+    methods:
+      comments: ["lgtm"]
+      github_review: false
+approval_rules:
+  - name: defaults
+  - name: overrides
+    options:
+      ignore_update_merges: false
+      methods:
+        github_review: true
+`))
+	require.NoError(t, err)
+
+	var config Config
+	require.NoError(t, yaml.UnmarshalStrict(b, &config))
+	require.Len(t, config.ApprovalRules, 2)
+
+	defaults := config.ApprovalRules[0].Options
+	assert.True(t, defaults.InvalidateOnPush)
+	assert.True(t, defaults.IgnoreUpdateMerges)
+	if assert.NotNil(t, defaults.Methods) {
+		assert.Equal(t, []string{"lgtm"}, defaults.Methods.Comments)
+		assert.False(t, defaults.Methods.GithubReview)
+	}
+
+	overrides := config.ApprovalRules[1].Options
+	assert.True(t, overrides.InvalidateOnPush)
+	assert.False(t, overrides.IgnoreUpdateMerges)
+	if assert.NotNil(t, overrides.Methods) {
+		assert.Equal(t, []string{"lgtm"}, overrides.Methods.Comments)
+		assert.True(t, overrides.Methods.GithubReview)
+	}
+
+	unchanged := []byte("approval_rules:\n  - name: rule\n")
+	b, err = ApplyRuleDefaults(unchanged)
+	require.NoError(t, err)
+	assert.Equal(t, unchanged, b, "policy without defaults was modified")
+
+	var invalid Config
+	assert.Error(t, yaml.UnmarshalStrict([]byte("options:\n  rule_defaults:\n    unknown_option: true\n"), &invalid))
+}
+
+func TestResolveIncludes(t *testing.T) {
+	files := map[string]string{
+		"policies/shared.yml": `
+include: ["policies/nested.yml"]
+approval_rules:
+  - name: shared
+`,
+		"policies/nested.yml": `
+approval_rules:
+  - name: nested
+`,
+		"policies/cycle.yml": `
+include: [".policy.yml"]
+`,
+		"policies/invalid.yml": `
+policy:
+  approval: [shared]
+`,
+		"policies/duplicate.yml": `
+approval_rules:
+  - name: local
+`,
+		"policies/diamond.yml": `
+include: ["policies/nested.yml"]
+approval_rules:
+  - name: diamond
+`,
+	}
+	load := func(p string) ([]byte, error) {
+		if f, ok := files[p]; ok {
+			return []byte(f), nil
+		}
+		return nil, nil
+	}
+
+	b, sources, err := ResolveIncludes([]byte(`
+include: ["./policies/shared.yml"]
+policy:
+  approval: [local, shared, nested]
+approval_rules:
+  - name: local
+`), ".policy.yml", load)
+	require.NoError(t, err)
+
+	var config Config
+	require.NoError(t, yaml.UnmarshalStrict(b, &config))
+	require.Len(t, config.ApprovalRules, 3)
+	assert.Equal(t, "local", config.ApprovalRules[0].Name)
+	assert.Equal(t, "shared", config.ApprovalRules[1].Name)
+	assert.Equal(t, "nested", config.ApprovalRules[2].Name)
+	assert.Equal(t, map[string]string{
+		"shared": "policies/shared.yml",
+		"nested": "policies/nested.yml",
+	}, sources)
+
+	// files included by more than one file only add their rules once
+	b, sources, err = ResolveIncludes([]byte(`
+include: ["policies/shared.yml", "policies/diamond.yml", "policies/nested.yml"]
+`), ".policy.yml", load)
+	require.NoError(t, err)
+
+	config = Config{}
+	require.NoError(t, yaml.UnmarshalStrict(b, &config))
+	require.Len(t, config.ApprovalRules, 3)
+	assert.Equal(t, "shared", config.ApprovalRules[0].Name)
+	assert.Equal(t, "nested", config.ApprovalRules[1].Name)
+	assert.Equal(t, "diamond", config.ApprovalRules[2].Name)
+	assert.Equal(t, "policies/nested.yml", sources["nested"])
+
+	unchanged := []byte("approval_rules:\n  - name: rule\n")
+	b, sources, err = ResolveIncludes(unchanged, ".policy.yml", load)
+	require.NoError(t, err)
+	assert.Equal(t, unchanged, b, "policy without includes was modified")
+	assert.Nil(t, sources)
+
+	_, _, err = ResolveIncludes([]byte(`include: ["policies/missing.yml"]`), ".policy.yml", load)
+	assert.EqualError(t, err, "policies/missing.yml included by .policy.yml does not exist")
+
+	_, _, err = ResolveIncludes([]byte(`include: ["policies/cycle.yml"]`), ".policy.yml", load)
+	assert.EqualError(t, err, "include cycle: .policy.yml -> policies/cycle.yml -> .policy.yml")
+
+	_, _, err = ResolveIncludes([]byte(`include: ["policies/invalid.yml"]`), ".policy.yml", load)
+	assert.EqualError(t, err, `policies/invalid.yml included by .policy.yml may only define approval_rules and include, found "policy"`)
+
+	_, _, err = ResolveIncludes([]byte("include: [\"policies/duplicate.yml\"]\napproval_rules:\n  - name: local\n"), ".policy.yml", load)
+	assert.EqualError(t, err, `approval rule "local" is defined more than once`)
+
+	_, _, err = ResolveIncludes([]byte(`include: "policies/shared.yml"`), ".policy.yml", load)
+	assert.EqualError(t, err, "invalid include in .policy.yml: include must be a list of paths")
 }
 
 func castToResult(e common.Evaluator) *common.Result {
 	return (*common.Result)(e.(*StaticEvaluator))
 }
+
+func TestDependsOn(t *testing.T) {
+	c := &Config{
+		ApprovalRules: []*approval.Rule{
+			{Name: "review"},
+		},
+	}
+	assert.True(t, c.DependsOn(EventPullRequest))
+	assert.False(t, c.DependsOn(EventStatus))
+	assert.False(t, c.DependsOn(EventCheckRun))
+	assert.False(t, c.DependsOn(EventWorkflowRun))
+
+	c.ApprovalRules = append(c.ApprovalRules, &approval.Rule{Name: "workflows", Requires: approval.Requires{Workflows: &approval.Workflows{}}})
+	assert.False(t, c.DependsOn(EventStatus))
+	assert.False(t, c.DependsOn(EventCheckRun))
+	assert.True(t, c.DependsOn(EventWorkflowRun))
+
+	c.ApprovalRules = append(c.ApprovalRules, &approval.Rule{Name: "ci", Requires: approval.Requires{Statuses: []string{"build"}}})
+	assert.True(t, c.DependsOn(EventStatus))
+	assert.True(t, c.DependsOn(EventCheckRun))
+	assert.True(t, c.DependsOn(EventWorkflowRun))
+
+	c.ApprovalRules = []*approval.Rule{{Name: "checks", Requires: approval.Requires{CheckRuns: []string{"scan"}}}}
+	assert.True(t, c.DependsOn(EventStatus))
+
+	c.ApprovalRules = nil
+	c.Policy.If = &approval.Predicates{HasMissingStatus: &predicate.HasMissingStatus{Statuses: []string{"build"}}}
+	assert.True(t, c.DependsOn(EventStatus))
+}
+
+func TestIsCommitEvent(t *testing.T) {
+	assert.True(t, IsCommitEvent(EventStatus))
+	assert.True(t, IsCommitEvent(EventWorkflowRun))
+	assert.True(t, IsCommitEvent(EventCheckRun))
+	assert.False(t, IsCommitEvent(EventPullRequest))
+	assert.False(t, IsCommitEvent(EventIssueComment))
+}
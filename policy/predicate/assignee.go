@@ -0,0 +1,52 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// Assignees is satisfied if one of the users is assigned to the pull request
+// or, if no users are listed, if the pull request has any assignee.
+type Assignees struct {
+	Users []string `yaml:"users"`
+}
+
+var _ Predicate = &Assignees{}
+
+func (pred *Assignees) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	assignees, err := prctx.Assignees()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to get assignees")
+	}
+
+	if len(pred.Users) == 0 {
+		if len(assignees) > 0 {
+			return true, "", nil
+		}
+		return false, "The pull request has no assignees", nil
+	}
+
+	for _, a := range assignees {
+		if contains(pred.Users, a) {
+			return true, "", nil
+		}
+	}
+	return false, "None of the required users are assigned to the pull request", nil
+}
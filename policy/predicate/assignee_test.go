@@ -0,0 +1,46 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"testing"
+
+	"github.com/palantir/policy-bot/pull/pulltest"
+)
+
+func TestAssignees(t *testing.T) {
+	assigned := func(users ...string) *pulltest.Context {
+		return &pulltest.Context{
+			AssigneesValue: users,
+		}
+	}
+
+	p := &Assignees{}
+
+	runTargetsTestCase(t, p, []targetsTestCase{
+		{"noAssignees", false, assigned()},
+		{"anyAssignee", true, assigned("mhaypenny")},
+	})
+
+	p = &Assignees{
+		Users: []string{"mhaypenny", "ttest"},
+	}
+
+	runTargetsTestCase(t, p, []targetsTestCase{
+		{"noAssignees", false, assigned()},
+		{"listedAssignee", true, assigned("rrandom", "ttest")},
+		{"otherAssignee", false, assigned("rrandom")},
+	})
+}
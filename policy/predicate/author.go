@@ -137,3 +137,24 @@ func (pred AuthorIsOnlyContributor) Evaluate(ctx context.Context, prctx pull.Con
 	}
 	return false, fmt.Sprintf("All commits were authored and committed by %s", author), nil
 }
+
+// AuthorIsBot, when true, is satisfied if the pull request was opened by a
+// GitHub App, like Dependabot. When false, it is satisfied if the pull
+// request was opened by a user.
+type AuthorIsBot bool
+
+var _ Predicate = AuthorIsBot(false)
+
+func (pred AuthorIsBot) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	author := prctx.Author()
+	isBot := pull.IsBot(author)
+
+	switch {
+	case bool(pred) == isBot:
+		return true, "", nil
+	case isBot:
+		return false, fmt.Sprintf("The pull request was opened by the bot %s", author), nil
+	default:
+		return false, fmt.Sprintf("The pull request was opened by %s, who is not a bot", author), nil
+	}
+}
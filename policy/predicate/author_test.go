@@ -443,6 +443,49 @@ func TestAuthorIsNotOnlyContributor(t *testing.T) {
 	})
 }
 
+func TestAuthorIsBot(t *testing.T) {
+	runAuthorTests(t, AuthorIsBot(true), []AuthorTestCase{
+		{
+			"authorIsBot",
+			true,
+			&pulltest.Context{
+				AuthorValue: "dependabot[bot]",
+			},
+		},
+		{
+			"authorIsUser",
+			false,
+			&pulltest.Context{
+				AuthorValue: "mhaypenny",
+			},
+		},
+		{
+			"authorNameContainsBot",
+			false,
+			&pulltest.Context{
+				AuthorValue: "release-bot",
+			},
+		},
+	})
+
+	runAuthorTests(t, AuthorIsBot(false), []AuthorTestCase{
+		{
+			"authorIsBot",
+			false,
+			&pulltest.Context{
+				AuthorValue: "dependabot[bot]",
+			},
+		},
+		{
+			"authorIsUser",
+			true,
+			&pulltest.Context{
+				AuthorValue: "mhaypenny",
+			},
+		},
+	})
+}
+
 type AuthorTestCase struct {
 	Name     string
 	Expected bool
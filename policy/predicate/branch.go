@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -46,3 +47,110 @@ func (pred *TargetsBranch) Evaluate(ctx context.Context, prctx pull.Context) (bo
 
 	return matches, desc, nil
 }
+
+// HeadBranch is satisfied if the name of the head branch of the pull request
+// matches any of the regular expressions in Patterns or any of the Globs,
+// which use .gitattributes syntax. The branches of forks are matched without
+// the prefix that contains the owner of the fork.
+type HeadBranch struct {
+	Patterns []string `yaml:"patterns"`
+	Globs    []string `yaml:"globs"`
+}
+
+var _ Predicate = &HeadBranch{}
+
+func (pred *HeadBranch) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	patterns, err := pathsToRegexps(pred.Patterns)
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to compile the head branch regex")
+	}
+	globs, err := GlobsToRegexps(pred.Globs)
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to compile the head branch glob")
+	}
+
+	_, head := prctx.Branches()
+	if i := strings.IndexByte(head, ':'); i >= 0 {
+		head = head[i+1:]
+	}
+
+	for _, re := range append(patterns, globs...) {
+		if re.MatchString(head) {
+			return true, "", nil
+		}
+	}
+	return false, fmt.Sprintf("Head branch %q does not match any of the required patterns", head), nil
+}
+
+// FromFork, when true, is satisfied if the head branch of the pull request is
+// in a different repository than the base branch. When false, it is satisfied
+// if both branches are in the same repository.
+type FromFork bool
+
+var _ Predicate = FromFork(false)
+
+func (pred FromFork) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	_, head := prctx.Branches()
+	isFork := strings.Contains(head, ":")
+
+	switch {
+	case isFork == bool(pred):
+		return true, "", nil
+	case isFork:
+		return false, "The pull request is from a fork", nil
+	}
+	return false, "The pull request is not from a fork", nil
+}
+
+// TargetsDefaultBranch, when true, is satisfied if the base branch of the pull
+// request is the default branch of the repository. When false, it is
+// satisfied if the pull request targets any other branch.
+type TargetsDefaultBranch bool
+
+var _ Predicate = TargetsDefaultBranch(false)
+
+func (pred TargetsDefaultBranch) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	defaultBranch, err := prctx.DefaultBranch()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to get default branch")
+	}
+
+	base, _ := prctx.Branches()
+	isDefault := base == defaultBranch
+
+	switch {
+	case isDefault == bool(pred):
+		return true, "", nil
+	case isDefault:
+		return false, fmt.Sprintf("The pull request targets the default branch %q", defaultBranch), nil
+	}
+	return false, fmt.Sprintf("Target branch %q is not the default branch %q", base, defaultBranch), nil
+}
+
+// BranchBehindBy is satisfied if the number of commits on the base branch
+// that are not in the head branch matches the comparison. Diverged branches
+// are compared using only the commits missing from the head branch.
+type BranchBehindBy struct {
+	Commits ComparisonExpr `yaml:"commits"`
+}
+
+var _ Predicate = &BranchBehindBy{}
+
+func (pred *BranchBehindBy) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	behindBy, err := prctx.BehindBy()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to compare branches")
+	}
+
+	matches, err := pred.Commits.Evaluate(int64(behindBy))
+	if err != nil {
+		return false, "", err
+	}
+
+	desc := ""
+	if !matches {
+		desc = fmt.Sprintf("Head branch is %d commits behind the target branch, which does not match %q", behindBy, pred.Commits)
+	}
+
+	return matches, desc, nil
+}
@@ -103,6 +103,175 @@ func TestTargetsBranch(t *testing.T) {
 	})
 }
 
+func TestBranchBehindBy(t *testing.T) {
+	p := &BranchBehindBy{
+		Commits: "> 2",
+	}
+
+	runTargetsTestCase(t, p, []targetsTestCase{
+		{
+			"up to date",
+			false,
+			&pulltest.Context{
+				BehindByValue: 0,
+			},
+		},
+		{
+			"behind by less",
+			false,
+			&pulltest.Context{
+				BehindByValue: 2,
+			},
+		},
+		{
+			"behind by more",
+			true,
+			&pulltest.Context{
+				BehindByValue: 5,
+			},
+		},
+	})
+}
+
+func TestHeadBranch(t *testing.T) {
+	p := &HeadBranch{
+		Patterns: []string{"^(feature|bugfix)/[a-z0-9-]+$"},
+		Globs:    []string{"release/*"},
+	}
+
+	runTargetsTestCase(t, p, []targetsTestCase{
+		{
+			"matches pattern",
+			true,
+			&pulltest.Context{
+				BranchHeadName: "feature/add-predicate",
+			},
+		},
+		{
+			"matches glob",
+			true,
+			&pulltest.Context{
+				BranchHeadName: "release/1.2",
+			},
+		},
+		{
+			"glob does not match nested branch",
+			false,
+			&pulltest.Context{
+				BranchHeadName: "release/1.2/hotfix",
+			},
+		},
+		{
+			"no match",
+			false,
+			&pulltest.Context{
+				BranchHeadName: "my-branch",
+			},
+		},
+		{
+			"fork branch matches without owner",
+			true,
+			&pulltest.Context{
+				BranchHeadName: "contributor:bugfix/fix-crash",
+			},
+		},
+		{
+			"fork owner is not part of the branch",
+			false,
+			&pulltest.Context{
+				BranchHeadName: "feature:my-branch",
+			},
+		},
+		{
+			"target branch is not matched",
+			false,
+			&pulltest.Context{
+				BranchBaseName: "feature/base",
+				BranchHeadName: "my-branch",
+			},
+		},
+	})
+
+	_, _, err := (&HeadBranch{Patterns: []string{"("}}).Evaluate(context.Background(), &pulltest.Context{})
+	assert.Error(t, err)
+}
+
+func TestFromFork(t *testing.T) {
+	runTargetsTestCase(t, FromFork(true), []targetsTestCase{
+		{
+			"fork",
+			true,
+			&pulltest.Context{
+				BranchHeadName: "contributor:feature",
+			},
+		},
+		{
+			"same repository",
+			false,
+			&pulltest.Context{
+				BranchHeadName: "feature",
+			},
+		},
+	})
+
+	runTargetsTestCase(t, FromFork(false), []targetsTestCase{
+		{
+			"fork",
+			false,
+			&pulltest.Context{
+				BranchHeadName: "contributor:feature",
+			},
+		},
+		{
+			"same repository",
+			true,
+			&pulltest.Context{
+				BranchHeadName: "feature",
+			},
+		},
+	})
+}
+
+func TestTargetsDefaultBranch(t *testing.T) {
+	runTargetsTestCase(t, TargetsDefaultBranch(true), []targetsTestCase{
+		{
+			"default branch",
+			true,
+			&pulltest.Context{
+				BranchBaseName:     "trunk",
+				DefaultBranchValue: "trunk",
+			},
+		},
+		{
+			"other branch",
+			false,
+			&pulltest.Context{
+				BranchBaseName:     "master",
+				DefaultBranchValue: "trunk",
+			},
+		},
+	})
+
+	runTargetsTestCase(t, TargetsDefaultBranch(false), []targetsTestCase{
+		{
+			"default branch",
+			false,
+			&pulltest.Context{
+				BranchBaseName:     "develop",
+				DefaultBranchValue: "develop",
+			},
+		},
+		{
+			"other branch",
+			true,
+			&pulltest.Context{
+				BranchBaseName:     "main",
+				DefaultBranchValue: "develop",
+			},
+		},
+	})
+}
+
 // TODO: generalize this and use it all our test cases
 type targetsTestCase struct {
 	name     string
@@ -0,0 +1,64 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// CommitAuthorEmail is satisfied if any commit author email matches one of the
+// patterns or, if MatchAll is true, if every commit author email matches.
+//
+// Commits by users who keep their email private use a GitHub "noreply"
+// address, which is matched like any other address. Commits with no author
+// email never match.
+type CommitAuthorEmail struct {
+	Patterns []string `yaml:"patterns"`
+	MatchAll bool     `yaml:"match_all"`
+}
+
+var _ Predicate = &CommitAuthorEmail{}
+
+func (pred *CommitAuthorEmail) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	patterns, err := pathsToRegexps(pred.Patterns)
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to parse email patterns")
+	}
+
+	commits, err := messageCommits(prctx)
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to get commits")
+	}
+
+	for _, c := range commits {
+		matches := c.AuthorEmail != "" && anyMatches(patterns, c.AuthorEmail)
+		switch {
+		case matches && !pred.MatchAll:
+			return true, "", nil
+		case !matches && pred.MatchAll:
+			return false, fmt.Sprintf("The author email of commit %.10s does not match the required patterns", c.SHA), nil
+		}
+	}
+
+	if pred.MatchAll {
+		return len(commits) > 0, "", nil
+	}
+	return false, "No commit author emails match the required patterns", nil
+}
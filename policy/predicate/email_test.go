@@ -0,0 +1,59 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"testing"
+
+	"github.com/palantir/policy-bot/pull"
+	"github.com/palantir/policy-bot/pull/pulltest"
+)
+
+func TestCommitAuthorEmail(t *testing.T) {
+	commits := func(emails ...string) *pulltest.Context {
+		prctx := &pulltest.Context{}
+		for _, e := range emails {
+			prctx.CommitsValue = append(prctx.CommitsValue, &pull.Commit{
+				SHA:         "abcdef123456789",
+				AuthorEmail: e,
+			})
+		}
+		return prctx
+	}
+
+	p := &CommitAuthorEmail{
+		Patterns: []string{`@palantir\.com$`},
+	}
+
+	runAuthorTests(t, p, []AuthorTestCase{
+		{"noCommits", false, commits()},
+		{"anyMatch", true, commits("ttest@example.com", "mhaypenny@palantir.com")},
+		{"noMatch", false, commits("ttest@example.com", "ttest@palantir.com.example.com")},
+		{"noreply", false, commits("12345+mhaypenny@users.noreply.github.com")},
+		{"emptyEmail", false, commits("")},
+	})
+
+	p = &CommitAuthorEmail{
+		Patterns: []string{`@palantir\.com$`, `@users\.noreply\.github\.com$`},
+		MatchAll: true,
+	}
+
+	runAuthorTests(t, p, []AuthorTestCase{
+		{"noCommits", false, commits()},
+		{"allMatch", true, commits("mhaypenny@palantir.com", "12345+ttest@users.noreply.github.com")},
+		{"someMatch", false, commits("ttest@example.com", "mhaypenny@palantir.com")},
+		{"emptyEmail", false, commits("mhaypenny@palantir.com", "")},
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -27,6 +28,10 @@ import (
 
 type ChangedFiles struct {
 	Paths []string `yaml:"paths"`
+
+	// ExcludeGenerated ignores files marked with the "linguist-generated"
+	// attribute in the .gitattributes file of the base branch.
+	ExcludeGenerated bool `yaml:"exclude_generated"`
 }
 
 var _ Predicate = &ChangedFiles{}
@@ -37,9 +42,9 @@ func (pred *ChangedFiles) Evaluate(ctx context.Context, prctx pull.Context) (boo
 		return false, "", errors.Wrap(err, "failed to parse paths")
 	}
 
-	files, err := prctx.ChangedFiles()
+	files, err := changedFiles(prctx, pred.ExcludeGenerated)
 	if err != nil {
-		return false, "", errors.Wrap(err, "failed to list changed files")
+		return false, "", err
 	}
 
 	for _, f := range files {
@@ -54,6 +59,14 @@ func (pred *ChangedFiles) Evaluate(ctx context.Context, prctx pull.Context) (boo
 
 type OnlyChangedFiles struct {
 	Paths []string `yaml:"paths"`
+
+	// ExcludeGenerated ignores files marked with the "linguist-generated"
+	// attribute in the .gitattributes file of the base branch.
+	ExcludeGenerated bool `yaml:"exclude_generated"`
+
+	// Ignore are glob patterns, using .gitattributes syntax, for files that
+	// are disregarded when checking that only matching files changed.
+	Ignore []string `yaml:"ignore"`
 }
 
 var _ Predicate = &OnlyChangedFiles{}
@@ -64,12 +77,23 @@ func (pred *OnlyChangedFiles) Evaluate(ctx context.Context, prctx pull.Context)
 		return false, "", errors.Wrap(err, "failed to parse paths")
 	}
 
-	files, err := prctx.ChangedFiles()
+	ignore, err := GlobsToRegexps(pred.Ignore)
 	if err != nil {
-		return false, "", errors.Wrap(err, "failed to list changed files")
+		return false, "", errors.Wrap(err, "failed to parse ignore patterns")
+	}
+
+	files, err := changedFiles(prctx, pred.ExcludeGenerated)
+	if err != nil {
+		return false, "", err
 	}
 
+	filesChanged := false
 	for _, f := range files {
+		if anyMatches(ignore, f.Filename) {
+			continue
+		}
+		filesChanged = true
+
 		if anyMatches(paths, f.Filename) {
 			continue
 		}
@@ -77,8 +101,6 @@ func (pred *OnlyChangedFiles) Evaluate(ctx context.Context, prctx pull.Context)
 		return false, desc, nil
 	}
 
-	filesChanged := len(files) > 0
-
 	desc := ""
 	if !filesChanged {
 		desc = "No files changed"
@@ -156,6 +178,103 @@ func (pred *ModifiedLines) Evaluate(ctx context.Context, prctx pull.Context) (bo
 
 var _ Predicate = &ModifiedLines{}
 
+// ModifiedHunks is satisfied if the number of diff hunks in any changed file
+// matches the expression. Files without a diff, including files whose diff
+// GitHub omits because it is too large, have no hunks.
+type ModifiedHunks struct {
+	PerFile ComparisonExpr `yaml:"per_file"`
+}
+
+func (pred *ModifiedHunks) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	if pred.PerFile.IsEmpty() {
+		return false, "No hunk condition is defined", nil
+	}
+
+	files, err := prctx.ChangedFiles()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to list changed files")
+	}
+
+	for _, f := range files {
+		hunks := countHunks(f.Patch)
+		ok, err := pred.PerFile.Evaluate(int64(hunks))
+		if err != nil {
+			return false, "", err
+		}
+		if ok {
+			return true, fmt.Sprintf("File %s has %d hunks", f.Filename, hunks), nil
+		}
+	}
+	return false, "No file has a number of hunks that matches the condition", nil
+}
+
+var _ Predicate = &ModifiedHunks{}
+
+// countHunks returns the number of hunk headers in a unified diff
+func countHunks(patch string) int {
+	n := strings.Count(patch, "\n@@ ")
+	if strings.HasPrefix(patch, "@@ ") {
+		n++
+	}
+	return n
+}
+
+// ChangedDirectories is satisfied if the number of distinct top-level
+// directories with changed files or the largest directory depth of a changed
+// file matches its expression. Files in the root of the repository count as
+// one top-level directory and have depth 0.
+type ChangedDirectories struct {
+	TopLevel ComparisonExpr `yaml:"top_level"`
+	Depth    ComparisonExpr `yaml:"depth"`
+}
+
+var _ Predicate = &ChangedDirectories{}
+
+func (pred *ChangedDirectories) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	if pred.TopLevel.IsEmpty() && pred.Depth.IsEmpty() {
+		return false, "No directory condition is defined", nil
+	}
+
+	files, err := prctx.ChangedFiles()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to list changed files")
+	}
+
+	topLevel := make(map[string]bool)
+	var depth int64
+	for _, f := range files {
+		parts := strings.Split(f.Filename, "/")
+		if len(parts) > 1 {
+			topLevel[parts[0]] = true
+		} else {
+			topLevel[""] = true
+		}
+		if d := int64(len(parts) - 1); d > depth {
+			depth = d
+		}
+	}
+
+	if !pred.TopLevel.IsEmpty() {
+		ok, err := pred.TopLevel.Evaluate(int64(len(topLevel)))
+		if err != nil {
+			return false, "", err
+		}
+		if ok {
+			return true, "", nil
+		}
+	}
+	if !pred.Depth.IsEmpty() {
+		ok, err := pred.Depth.Evaluate(depth)
+		if err != nil {
+			return false, "", err
+		}
+		if ok {
+			return true, "", nil
+		}
+	}
+	return false, fmt.Sprintf("changes in %d top-level directories with depth %d do not match any conditions", len(topLevel), depth), nil
+}
+
 func anyMatches(re []*regexp.Regexp, s string) bool {
 	for _, r := range re {
 		if r.MatchString(s) {
@@ -165,6 +284,20 @@ func anyMatches(re []*regexp.Regexp, s string) bool {
 	return false
 }
 
+// GlobsToRegexps converts .gitattributes patterns to regular expressions that
+// match full paths relative to the repository root.
+func GlobsToRegexps(globs []string) ([]*regexp.Regexp, error) {
+	var re []*regexp.Regexp
+	for _, g := range globs {
+		r, err := globToRegexp(g)
+		if err != nil {
+			return re, err
+		}
+		re = append(re, r)
+	}
+	return re, nil
+}
+
 func pathsToRegexps(paths []string) ([]*regexp.Regexp, error) {
 	var re []*regexp.Regexp
 	for _, p := range paths {
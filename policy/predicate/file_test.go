@@ -16,6 +16,7 @@ package predicate
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -140,6 +141,53 @@ func TestOnlyChangedFiles(t *testing.T) {
 			},
 		},
 	})
+
+	p.Ignore = []string{"go.sum", "vendor/**"}
+
+	runFileTests(t, p, []FileTestCase{
+		{
+			"ignoredLockfile",
+			true,
+			[]*pull.File{
+				{
+					Filename: "app/client.go",
+					Status:   pull.FileModified,
+				},
+				{
+					Filename: "go.sum",
+					Status:   pull.FileModified,
+				},
+				{
+					Filename: "vendor/github.com/pkg/errors/errors.go",
+					Status:   pull.FileAdded,
+				},
+			},
+		},
+		{
+			"ignoredAndUnmatched",
+			false,
+			[]*pull.File{
+				{
+					Filename: "go.sum",
+					Status:   pull.FileModified,
+				},
+				{
+					Filename: "model/user.go",
+					Status:   pull.FileModified,
+				},
+			},
+		},
+		{
+			"onlyIgnored",
+			false,
+			[]*pull.File{
+				{
+					Filename: "go.sum",
+					Status:   pull.FileModified,
+				},
+			},
+		},
+	})
 }
 
 func TestModifiedLines(t *testing.T) {
@@ -193,6 +241,137 @@ func TestModifiedLines(t *testing.T) {
 	})
 }
 
+func TestModifiedHunks(t *testing.T) {
+	p := &ModifiedHunks{
+		PerFile: "> 2",
+	}
+
+	largeHunk := "@@ -1,4 +1,204 @@\n" + strings.Repeat("+line\n", 200)
+	smallHunks := strings.Repeat("@@ -1,1 +1,2 @@\n context\n+line\n", 3)
+
+	runFileTests(t, p, []FileTestCase{
+		{
+			"empty",
+			false,
+			[]*pull.File{},
+		},
+		{
+			"singleLargeHunk",
+			false,
+			[]*pull.File{
+				{Filename: "large.go", Additions: 200, Patch: largeHunk},
+			},
+		},
+		{
+			"manySmallHunks",
+			true,
+			[]*pull.File{
+				{Filename: "large.go", Additions: 200, Patch: largeHunk},
+				{Filename: "small.go", Additions: 3, Patch: smallHunks},
+			},
+		},
+		{
+			"omittedPatch",
+			false,
+			[]*pull.File{
+				{Filename: "huge.go", Additions: 100000},
+			},
+		},
+	})
+}
+
+func TestCountHunks(t *testing.T) {
+	assert.Equal(t, 0, countHunks(""))
+	assert.Equal(t, 1, countHunks("@@ -1 +1 @@\n-a\n+b"))
+	assert.Equal(t, 2, countHunks("@@ -1 +1 @@\n-a\n+b\n@@ -10 +10 @@\n-c\n+d\n"))
+	assert.Equal(t, 1, countHunks("@@ -1 +1 @@\n-@@ a\n+@@ b\n"))
+}
+
+func TestChangedDirectories(t *testing.T) {
+	p := &ChangedDirectories{
+		TopLevel: "> 2",
+		Depth:    "> 3",
+	}
+
+	runFileTests(t, p, []FileTestCase{
+		{
+			"empty",
+			false,
+			[]*pull.File{},
+		},
+		{
+			"singleDirectory",
+			false,
+			[]*pull.File{
+				{Filename: "server/handler/base.go"},
+				{Filename: "server/handler/details.go"},
+				{Filename: "server/server.go"},
+			},
+		},
+		{
+			"scatteredFiles",
+			true,
+			[]*pull.File{
+				{Filename: "README.md"},
+				{Filename: "server/server.go"},
+				{Filename: "policy/policy.go"},
+			},
+		},
+		{
+			"rootFilesCountOnce",
+			false,
+			[]*pull.File{
+				{Filename: "README.md"},
+				{Filename: "go.mod"},
+				{Filename: "server/server.go"},
+			},
+		},
+		{
+			"deepFile",
+			true,
+			[]*pull.File{
+				{Filename: "server/a/b/c/d.go"},
+			},
+		},
+	})
+
+	// both conditions are evaluated when they are the same expression
+	p = &ChangedDirectories{
+		TopLevel: "> 2",
+		Depth:    "> 2",
+	}
+
+	runFileTests(t, p, []FileTestCase{
+		{
+			"sameExpressionTopLevel",
+			true,
+			[]*pull.File{
+				{Filename: "README.md"},
+				{Filename: "server/server.go"},
+				{Filename: "policy/policy.go"},
+				{Filename: "pull/github.go"},
+			},
+		},
+		{
+			"sameExpressionDepth",
+			true,
+			[]*pull.File{
+				{Filename: "server/a/b/c.go"},
+			},
+		},
+	})
+
+	runFileTests(t, &ChangedDirectories{}, []FileTestCase{
+		{
+			"noConditions",
+			false,
+			[]*pull.File{
+				{Filename: "server/a/b/c/d.go"},
+			},
+		},
+	})
+}
+
 func TestComparisonExpr(t *testing.T) {
 	tests := map[string]struct {
 		Expr   ComparisonExpr
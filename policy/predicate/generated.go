@@ -0,0 +1,163 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+const generatedAttribute = "linguist-generated"
+
+// generatedRule is a line from a .gitattributes file that sets or unsets the
+// generated attribute for the paths matching a pattern
+type generatedRule struct {
+	pattern   *regexp.Regexp
+	generated bool
+}
+
+// parseGeneratedRules returns the rules in a .gitattributes file that set,
+// unset, or unspecify the generated attribute. Rules are returned in file
+// order, so the last matching rule for a path takes precedence.
+func parseGeneratedRules(attributes string) ([]generatedRule, error) {
+	var rules []generatedRule
+	for _, line := range strings.Split(attributes, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		// negative patterns are forbidden in .gitattributes and are ignored
+		if strings.HasPrefix(fields[0], "!") {
+			continue
+		}
+
+		generated, ok := generatedState(fields[1:])
+		if !ok {
+			continue
+		}
+
+		pattern, err := globToRegexp(fields[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid .gitattributes pattern %q", fields[0])
+		}
+		rules = append(rules, generatedRule{pattern: pattern, generated: generated})
+	}
+	return rules, nil
+}
+
+// generatedState returns the state of the generated attribute in a list of
+// attributes and true if the list contains the attribute. Unset, unspecified,
+// and false values are all treated as not generated.
+func generatedState(attrs []string) (generated bool, ok bool) {
+	for _, attr := range attrs {
+		switch attr {
+		case generatedAttribute, generatedAttribute + "=true":
+			generated, ok = true, true
+		case "-" + generatedAttribute, "!" + generatedAttribute, generatedAttribute + "=false":
+			generated, ok = false, true
+		}
+	}
+	return
+}
+
+func isGenerated(rules []generatedRule, filename string) bool {
+	generated := false
+	for _, r := range rules {
+		if r.pattern.MatchString(filename) {
+			generated = r.generated
+		}
+	}
+	return generated
+}
+
+// globToRegexp converts a .gitattributes pattern to a regular expression that
+// matches full paths relative to the repository root. Patterns without a
+// slash match files with the same name in any directory.
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+
+	if !strings.Contains(glob, "/") {
+		b.WriteString("(.*/)?")
+	}
+	glob = strings.TrimPrefix(glob, "/")
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// changedFiles returns the files changed by the pull request, excluding files
+// marked as generated in the .gitattributes file if excludeGenerated is true
+func changedFiles(prctx pull.Context, excludeGenerated bool) ([]*pull.File, error) {
+	files, err := prctx.ChangedFiles()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list changed files")
+	}
+	if !excludeGenerated {
+		return files, nil
+	}
+
+	attributes, err := prctx.GitAttributes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get git attributes")
+	}
+
+	rules, err := parseGeneratedRules(attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*pull.File
+	for _, f := range files {
+		if !isGenerated(rules, f.Filename) {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/palantir/policy-bot/pull"
+	"github.com/palantir/policy-bot/pull/pulltest"
+)
+
+func TestIsGenerated(t *testing.T) {
+	attributes := `
+# generated code
+*.pb.go linguist-generated
+/vendor/** linguist-generated=true
+docs/**/*.html linguist-generated text
+
+# keep these visible
+vendor/github.com/palantir/** -linguist-generated
+api.pb.go !linguist-generated
+internal/**/*.pb.go linguist-generated=false
+!*.lock linguist-generated
+Gopkg.lock binary
+`
+
+	rules, err := parseGeneratedRules(attributes)
+	require.NoError(t, err)
+
+	tests := map[string]bool{
+		"server.go":                            false,
+		"user.pb.go":                           true,
+		"model/user.pb.go":                     true,
+		"api.pb.go":                            false,
+		"model/api.pb.go":                      false,
+		"internal/model/user.pb.go":            false,
+		"vendor/github.com/pkg/errors/errs.go": true,
+		"vendor/github.com/palantir/lib/a.go":  false,
+		"app/vendor/lib.go":                    false,
+		"docs/index.html":                      true,
+		"docs/api/v1/index.html":               true,
+		"docs/index.md":                        false,
+		"Gopkg.lock":                           false,
+	}
+
+	for filename, expected := range tests {
+		assert.Equal(t, expected, isGenerated(rules, filename), "incorrect generated status for %s", filename)
+	}
+}
+
+func TestExcludeGenerated(t *testing.T) {
+	ctx := context.Background()
+
+	prctx := &pulltest.Context{
+		ChangedFilesValue: []*pull.File{
+			{Filename: "app/client.go", Status: pull.FileModified},
+			{Filename: "app/client.pb.go", Status: pull.FileModified},
+			{Filename: "Gopkg.lock", Status: pull.FileModified},
+		},
+		GitAttributesValue: "*.pb.go linguist-generated\nGopkg.lock linguist-generated\n",
+	}
+
+	changed := &ChangedFiles{
+		Paths: []string{".*\\.pb\\.go", "Gopkg\\.lock"},
+	}
+
+	ok, _, err := changed.Evaluate(ctx, prctx)
+	require.NoError(t, err)
+	assert.True(t, ok, "generated files should match by default")
+
+	changed.ExcludeGenerated = true
+	ok, _, err = changed.Evaluate(ctx, prctx)
+	require.NoError(t, err)
+	assert.False(t, ok, "generated files should not match when excluded")
+
+	only := &OnlyChangedFiles{
+		Paths:            []string{"app/.*\\.go"},
+		ExcludeGenerated: true,
+	}
+
+	ok, _, err = only.Evaluate(ctx, prctx)
+	require.NoError(t, err)
+	assert.True(t, ok, "non-matching generated files should be ignored")
+
+	prctx.GitAttributesValue = ""
+	ok, _, err = only.Evaluate(ctx, prctx)
+	require.NoError(t, err)
+	assert.False(t, ok, "files should not be ignored without attributes")
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+var (
+	// linkedIssueRegexp matches GitHub closing keywords followed by a local
+	// issue reference, a cross-repository reference, or an issue URL
+	linkedIssueRegexp = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+(?:https://github\.com/([\w.-]+)/([\w.-]+)/issues/(\d+)|(?:([\w.-]+)/([\w.-]+))?#(\d+))\b`)
+)
+
+// HasLinkedIssue is satisfied if the pull request description links at least
+// one issue with a closing keyword, like "Closes #123", and every linked issue
+// has one of the states and all of the labels. Issues that do not exist or are
+// not accessible never satisfy the predicate.
+type HasLinkedIssue struct {
+	States []string `yaml:"states"`
+	Labels []string `yaml:"labels"`
+}
+
+var _ Predicate = &HasLinkedIssue{}
+
+func (pred *HasLinkedIssue) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	refs := findLinkedIssues(prctx.RepositoryOwner(), prctx.RepositoryName(), prctx.Body())
+	if len(refs) == 0 {
+		return false, "The pull request does not link any issues", nil
+	}
+
+	for _, ref := range refs {
+		issue, err := prctx.Issue(ref.Owner, ref.Repo, ref.Number)
+		if err != nil {
+			return false, "", errors.Wrap(err, "failed to get linked issue")
+		}
+		if issue == nil {
+			return false, fmt.Sprintf("Linked issue %s does not exist or is not accessible", ref), nil
+		}
+
+		if len(pred.States) > 0 && !contains(pred.States, issue.State) {
+			return false, fmt.Sprintf("Linked issue %s is %s", ref, issue.State), nil
+		}
+		for _, label := range pred.Labels {
+			if !contains(issue.Labels, label) {
+				return false, fmt.Sprintf("Linked issue %s does not have label %q", ref, label), nil
+			}
+		}
+	}
+
+	return true, "", nil
+}
+
+// ClosesIssue is satisfied if the pull request description or the message of
+// any commit links at least one existing issue with a closing keyword, like
+// "Closes #123". Unlike HasLinkedIssue, other linked issues may not exist.
+type ClosesIssue struct{}
+
+var _ Predicate = &ClosesIssue{}
+
+func (pred *ClosesIssue) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	commits, err := messageCommits(prctx)
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to list commits")
+	}
+
+	texts := []string{prctx.Body()}
+	for _, c := range commits {
+		texts = append(texts, c.Message)
+	}
+
+	var refs []issueRef
+	seen := make(map[issueRef]bool)
+	for _, text := range texts {
+		for _, ref := range findLinkedIssues(prctx.RepositoryOwner(), prctx.RepositoryName(), text) {
+			if !seen[ref] {
+				seen[ref] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	if len(refs) == 0 {
+		return false, "The pull request does not close any issues", nil
+	}
+
+	for _, ref := range refs {
+		issue, err := prctx.Issue(ref.Owner, ref.Repo, ref.Number)
+		if err != nil {
+			return false, "", errors.Wrap(err, "failed to get linked issue")
+		}
+		if issue != nil {
+			return true, "", nil
+		}
+	}
+	return false, "The pull request does not close any issues that exist or are accessible", nil
+}
+
+type issueRef struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+func (r issueRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Number)
+}
+
+// findLinkedIssues returns the unique issues linked by the body, resolving
+// local references against the given repository
+func findLinkedIssues(owner, repo, body string) []issueRef {
+	var refs []issueRef
+	seen := make(map[issueRef]bool)
+
+	for _, m := range linkedIssueRegexp.FindAllStringSubmatch(body, -1) {
+		ref := issueRef{Owner: owner, Repo: repo}
+
+		var number string
+		switch {
+		case m[3] != "":
+			ref.Owner, ref.Repo, number = m[1], m[2], m[3]
+		case m[4] != "":
+			ref.Owner, ref.Repo, number = m[4], m[5], m[6]
+		default:
+			number = m[6]
+		}
+
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			continue
+		}
+		ref.Number = n
+
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
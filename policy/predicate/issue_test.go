@@ -0,0 +1,128 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/palantir/policy-bot/pull"
+	"github.com/palantir/policy-bot/pull/pulltest"
+)
+
+func TestFindLinkedIssues(t *testing.T) {
+	body := `Fixes #12 and closes palantir/bulldozer#7.
+
+Also resolves: https://github.com/palantir/go-githubapp/issues/3, see #99 and
+fixes #12 again.`
+
+	refs := findLinkedIssues("palantir", "policy-bot", body)
+	assert.Equal(t, []issueRef{
+		{Owner: "palantir", Repo: "policy-bot", Number: 12},
+		{Owner: "palantir", Repo: "bulldozer", Number: 7},
+		{Owner: "palantir", Repo: "go-githubapp", Number: 3},
+	}, refs)
+}
+
+func TestHasLinkedIssue(t *testing.T) {
+	ctx := context.Background()
+
+	p := &HasLinkedIssue{
+		States: []string{"open"},
+		Labels: []string{"approved"},
+	}
+
+	issues := map[string]*pull.Issue{
+		"pulltest/context#1": {State: "open", Labels: []string{"bug", "approved"}},
+		"pulltest/context#2": {State: "open", Labels: []string{"bug"}},
+		"pulltest/context#3": {State: "closed", Labels: []string{"approved"}},
+		"palantir/other#4":   {State: "open", Labels: []string{"approved"}},
+	}
+
+	tests := map[string]struct {
+		Body     string
+		Expected bool
+	}{
+		"noLinks":           {"See #1", false},
+		"matchingIssue":     {"Closes #1", true},
+		"missingLabel":      {"Closes #1, fixes #2", false},
+		"wrongState":        {"Resolves #3", false},
+		"crossRepository":   {"Fixes palantir/other#4", true},
+		"inaccessibleIssue": {"Fixes #1\nFixes palantir/private#5", false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prctx := &pulltest.Context{
+				BodyValue:   test.Body,
+				IssuesValue: issues,
+			}
+
+			ok, _, err := p.Evaluate(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, ok, "predicate was not correct")
+		})
+	}
+}
+
+func TestClosesIssue(t *testing.T) {
+	ctx := context.Background()
+	p := &ClosesIssue{}
+
+	issues := map[string]*pull.Issue{
+		"pulltest/context#1": {State: "open"},
+		"palantir/other#2":   {State: "closed"},
+	}
+
+	tests := map[string]struct {
+		Body     string
+		Messages []string
+		Expected bool
+	}{
+		"noReferences":       {"See #1", nil, false},
+		"body":               {"Closes #1", nil, true},
+		"commitMessage":      {"", []string{"Add feature", "Fix bug\n\nResolves #1"}, true},
+		"crossRepository":    {"Fixes palantir/other#2", nil, true},
+		"issueURL":           {"Fixes https://github.com/palantir/other/issues/2", nil, true},
+		"missingIssue":       {"Fixes #3", nil, false},
+		"oneExistingIssue":   {"Fixes #3, fixes palantir/private#4, closes #1", nil, true},
+		"missingSpace":       {"Fixes#1", nil, false},
+		"keywordSuffix":      {"Prefixes #1", nil, false},
+		"missingNumber":      {"Closes #", []string{"Fixes issue 1"}, false},
+		"unrelatedReference": {"Related to #1", []string{"Mentions #1"}, false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var commits []*pull.Commit
+			for _, m := range test.Messages {
+				commits = append(commits, &pull.Commit{Message: m})
+			}
+
+			prctx := &pulltest.Context{
+				BodyValue:    test.Body,
+				CommitsValue: commits,
+				IssuesValue:  issues,
+			}
+
+			ok, _, err := p.Evaluate(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, ok, "predicate was not correct")
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// IsMergeable is satisfied if the mergeability of the pull request matches
+// the value: true if the pull request can be merged without conflicts, false
+// if it has conflicts. It is never satisfied while GitHub is still computing
+// whether the pull request is mergeable.
+type IsMergeable bool
+
+var _ Predicate = IsMergeable(false)
+
+func (pred IsMergeable) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	mergeable, err := prctx.Mergeable()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to get mergeable state")
+	}
+
+	switch {
+	case mergeable == nil:
+		return false, "GitHub has not determined if the pull request is mergeable", nil
+	case *mergeable == bool(pred):
+		return true, "", nil
+	case bool(pred):
+		return false, "The pull request has merge conflicts", nil
+	}
+	return false, "The pull request does not have merge conflicts", nil
+}
+
+// Values for the MergeMethods predicate
+const (
+	MergeMethodMerge  = "merge"
+	MergeMethodSquash = "squash"
+	MergeMethodRebase = "rebase"
+)
+
+// MergeMethods is satisfied if every merge method that the repository allows
+// is in the list. The method used to merge the pull request is only chosen at
+// merge time, so this only identifies the method in repositories that allow
+// a single method.
+type MergeMethods []string
+
+var _ Predicate = MergeMethods(nil)
+
+func (pred MergeMethods) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	listed := make(map[string]bool)
+	for _, m := range pred {
+		switch m {
+		case MergeMethodMerge, MergeMethodSquash, MergeMethodRebase:
+			listed[m] = true
+		default:
+			return false, "", errors.Errorf("unknown merge method %q", m)
+		}
+	}
+
+	methods, err := prctx.MergeMethods()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to get merge methods")
+	}
+
+	var unlisted []string
+	for _, m := range []struct {
+		Name    string
+		Allowed bool
+	}{
+		{MergeMethodMerge, methods.Merge},
+		{MergeMethodSquash, methods.Squash},
+		{MergeMethodRebase, methods.Rebase},
+	} {
+		if m.Allowed && !listed[m.Name] {
+			unlisted = append(unlisted, m.Name)
+		}
+	}
+
+	if len(unlisted) > 0 {
+		return false, fmt.Sprintf("The repository also allows merge methods: %s", strings.Join(unlisted, ", ")), nil
+	}
+	return true, "", nil
+}
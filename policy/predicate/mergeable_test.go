@@ -0,0 +1,92 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/palantir/policy-bot/pull"
+	"github.com/palantir/policy-bot/pull/pulltest"
+)
+
+func TestIsMergeable(t *testing.T) {
+	ctx := context.Background()
+
+	yes, no := true, false
+	tests := map[string]struct {
+		Mergeable *bool
+		Expected  map[IsMergeable]bool
+	}{
+		"mergeable": {&yes, map[IsMergeable]bool{true: true, false: false}},
+		"conflicts": {&no, map[IsMergeable]bool{true: false, false: true}},
+		"unknown":   {nil, map[IsMergeable]bool{true: false, false: false}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prctx := &pulltest.Context{
+				MergeableValue: test.Mergeable,
+			}
+
+			for p, expected := range test.Expected {
+				ok, _, err := p.Evaluate(ctx, prctx)
+				require.NoError(t, err)
+				assert.Equal(t, expected, ok, "predicate %v was not correct", p)
+			}
+		})
+	}
+}
+
+func TestMergeMethods(t *testing.T) {
+	ctx := context.Background()
+	p := MergeMethods{MergeMethodSquash, MergeMethodRebase}
+
+	tests := map[string]struct {
+		Methods  pull.MergeMethods
+		Expected bool
+	}{
+		"squashOnly":      {pull.MergeMethods{Squash: true}, true},
+		"squashAndRebase": {pull.MergeMethods{Squash: true, Rebase: true}, true},
+		"mergeOnly":       {pull.MergeMethods{Merge: true}, false},
+		"allMethods":      {pull.MergeMethods{Merge: true, Squash: true, Rebase: true}, false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			methods := test.Methods
+			prctx := &pulltest.Context{
+				MergeMethodsValue: &methods,
+			}
+
+			ok, _, err := p.Evaluate(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, ok, "predicate was not correct")
+		})
+	}
+
+	prctx := &pulltest.Context{
+		MergeMethodsValue: &pull.MergeMethods{Merge: true, Rebase: true},
+	}
+	_, desc, err := MergeMethods{MergeMethodSquash}.Evaluate(ctx, prctx)
+	require.NoError(t, err)
+	assert.Equal(t, "The repository also allows merge methods: merge, rebase", desc)
+
+	_, _, err = MergeMethods{"fast-forward"}.Evaluate(ctx, prctx)
+	assert.EqualError(t, err, `unknown merge method "fast-forward"`)
+}
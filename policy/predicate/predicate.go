@@ -25,3 +25,26 @@ type Predicate interface {
 	// optional string providing details about the evaluation result.
 	Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error)
 }
+
+// Validator is implemented by predicates that can detect invalid options when
+// the policy is parsed, instead of failing every evaluation.
+type Validator interface {
+	Validate() error
+}
+
+// MessageContext is implemented by contexts that check the messages and author
+// emails of different commits than the commits of the pull request, like a
+// preview of the squash commit. Predicates about contributors always use the
+// commits of the pull request.
+type MessageContext interface {
+	MessageCommits() ([]*pull.Commit, error)
+}
+
+// messageCommits returns the commits whose messages and author emails are
+// checked by predicates
+func messageCommits(prctx pull.Context) ([]*pull.Commit, error) {
+	if mc, ok := prctx.(MessageContext); ok {
+		return mc.MessageCommits()
+	}
+	return prctx.Commits()
+}
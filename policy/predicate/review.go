@@ -0,0 +1,243 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/pull"
+)
+
+// ReviewComments is satisfied if the number of review comments on the diff of
+// the pull request matches the expression.
+type ReviewComments struct {
+	Count ComparisonExpr `yaml:"count"`
+
+	// IgnoreOutdated excludes comments on lines that changed after the
+	// comment was created.
+	IgnoreOutdated bool `yaml:"ignore_outdated"`
+
+	// IgnoreAuthor excludes comments by the author of the pull request.
+	IgnoreAuthor bool `yaml:"ignore_author"`
+}
+
+var _ Predicate = &ReviewComments{}
+
+func (pred *ReviewComments) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	if pred.Count.IsEmpty() {
+		return false, "No review comment condition is defined", nil
+	}
+
+	comments, err := prctx.ReviewComments()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to list review comments")
+	}
+
+	var n int64
+	for _, c := range comments {
+		switch {
+		case pred.IgnoreOutdated && c.Outdated:
+		case pred.IgnoreAuthor && c.Author == prctx.Author():
+		default:
+			n++
+		}
+	}
+
+	ok, err := pred.Count.Evaluate(n)
+	if err != nil {
+		return false, "", err
+	}
+	if !ok {
+		return false, fmt.Sprintf("The pull request has %d review comments", n), nil
+	}
+	return true, "", nil
+}
+
+// ApprovalCount is satisfied if the number of users who approved the pull
+// request matches the expression. Approvals use the same methods as approval
+// rules and are counted across the whole pull request: approvals by the author
+// or by other contributors never count, but the approvers of specific rules
+// are not considered.
+type ApprovalCount struct {
+	Count ComparisonExpr `yaml:"count"`
+
+	// Methods are the approval methods. If nil, the default methods of
+	// approval rules are used.
+	Methods *common.Methods `yaml:"methods"`
+}
+
+var _ Predicate = &ApprovalCount{}
+
+func (pred *ApprovalCount) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	if pred.Count.IsEmpty() {
+		return false, "No approval count condition is defined", nil
+	}
+
+	methods := pred.Methods
+	if methods == nil {
+		methods = common.DefaultApprovalMethods()
+	}
+	methods.GithubReviewState = pull.ReviewApproved
+
+	candidates, err := methods.Candidates(ctx, prctx)
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to get approval candidates")
+	}
+
+	commits, err := prctx.Commits()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to list commits")
+	}
+	ineligible := common.IneligibleApprovers(prctx.Author(), commits, false, false)
+
+	var n int64
+	for _, c := range candidates {
+		if !ineligible[c.User] {
+			n++
+		}
+	}
+
+	ok, err := pred.Count.Evaluate(n)
+	if err != nil {
+		return false, "", err
+	}
+	if !ok {
+		return false, fmt.Sprintf("The pull request has %d approvals", n), nil
+	}
+	return true, "", nil
+}
+
+// HasReviewState is satisfied if any current review of the pull request has
+// one of the states. A user's current review is their most recent approving
+// or changes requested review or, if they have neither, their most recent
+// commented review. Other reviews are superseded and are only considered if
+// IncludeSuperseded is true.
+type HasReviewState struct {
+	States            []pull.ReviewState `yaml:"states"`
+	IncludeSuperseded bool               `yaml:"include_superseded"`
+}
+
+var _ Predicate = &HasReviewState{}
+
+func (pred *HasReviewState) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	if len(pred.States) == 0 {
+		return false, "No review states are defined", nil
+	}
+
+	reviews, err := prctx.Reviews()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to list reviews")
+	}
+
+	// commented reviews never supersede other reviews, so they are only
+	// needed to match the commented state
+	for _, s := range pred.States {
+		if s == pull.ReviewCommented {
+			commented, err := prctx.CommentedReviews()
+			if err != nil {
+				return false, "", errors.Wrap(err, "failed to list commented reviews")
+			}
+			reviews = append(reviews[:len(reviews):len(reviews)], commented...)
+			break
+		}
+	}
+
+	if !pred.IncludeSuperseded {
+		reviews = currentReviews(reviews)
+	}
+
+	for _, r := range reviews {
+		for _, s := range pred.States {
+			if r.State == s {
+				return true, "", nil
+			}
+		}
+	}
+
+	states := make([]string, len(pred.States))
+	for i, s := range pred.States {
+		states[i] = string(s)
+	}
+	return false, fmt.Sprintf("No reviews are in states %s", strings.Join(states, ", ")), nil
+}
+
+// currentReviews returns the current review of each user
+func currentReviews(reviews []*pull.Review) []*pull.Review {
+	sorted := make([]*pull.Review, len(reviews))
+	copy(sorted, reviews)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+
+	var users []string
+	current := make(map[string]*pull.Review)
+	for _, r := range sorted {
+		prev, ok := current[r.Author]
+		if !ok {
+			users = append(users, r.Author)
+		}
+
+		// comments do not supersede approvals or requests for changes
+		if r.State == pull.ReviewCommented && ok && prev.State != pull.ReviewCommented {
+			continue
+		}
+		current[r.Author] = r
+	}
+
+	result := make([]*pull.Review, len(users))
+	for i, u := range users {
+		result[i] = current[u]
+	}
+	return result
+}
+
+// RequestedTeams is satisfied if one of the teams has a pending request to
+// review the pull request or, if no teams are listed, if any team has a
+// pending request. Teams are specified as "org-name/team-name" and stop
+// matching once a member of the team submits a review for them.
+type RequestedTeams struct {
+	Teams []string `yaml:"teams"`
+}
+
+var _ Predicate = &RequestedTeams{}
+
+func (pred *RequestedTeams) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	requests, err := prctx.RequestedReviewers()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to get requested reviewers")
+	}
+
+	if len(pred.Teams) == 0 {
+		if len(requests.Teams) > 0 {
+			return true, "", nil
+		}
+		return false, "No teams are requested to review the pull request", nil
+	}
+
+	for _, requested := range requests.Teams {
+		for _, team := range pred.Teams {
+			if strings.EqualFold(requested, team) {
+				return true, "", nil
+			}
+		}
+	}
+	return false, "None of the required teams are requested to review the pull request", nil
+}
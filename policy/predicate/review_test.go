@@ -0,0 +1,254 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/pull"
+	"github.com/palantir/policy-bot/pull/pulltest"
+)
+
+func TestReviewComments(t *testing.T) {
+	ctx := context.Background()
+
+	comments := []*pull.ReviewComment{
+		{Author: "mhaypenny", Path: "README.md"},
+		{Author: "bkeyes", Path: "server.go"},
+		{Author: "ttest", Path: "server.go", Outdated: true},
+	}
+
+	tests := map[string]struct {
+		Predicate *ReviewComments
+		Comments  []*pull.ReviewComment
+		Expected  bool
+	}{
+		"noComments": {
+			&ReviewComments{Count: "> 0"},
+			nil,
+			false,
+		},
+		"allComments": {
+			&ReviewComments{Count: "> 2"},
+			comments,
+			true,
+		},
+		"ignoreOutdated": {
+			&ReviewComments{Count: "> 2", IgnoreOutdated: true},
+			comments,
+			false,
+		},
+		"ignoreAuthor": {
+			&ReviewComments{Count: "< 3", IgnoreAuthor: true},
+			comments,
+			true,
+		},
+		"noCondition": {
+			&ReviewComments{},
+			comments,
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prctx := &pulltest.Context{
+				AuthorValue:         "mhaypenny",
+				ReviewCommentsValue: test.Comments,
+			}
+
+			ok, _, err := test.Predicate.Evaluate(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, ok, "predicate was not correct")
+		})
+	}
+}
+
+func TestApprovalCount(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	prctx := &pulltest.Context{
+		AuthorValue: "mhaypenny",
+		CommentsValue: []*pull.Comment{
+			{Author: "mhaypenny", Body: ":+1: my stuff is cool", CreatedAt: now},
+			{Author: "contributor", Body: ":+1:", CreatedAt: now},
+			{Author: "bkeyes", Body: "LGTM :+1:", CreatedAt: now},
+			{Author: "bkeyes", Body: "still :+1:", CreatedAt: now.Add(time.Minute)},
+			{Author: "ttest", Body: "approved", CreatedAt: now},
+		},
+		ReviewsValue: []*pull.Review{
+			{Author: "jgiannuzzi", State: pull.ReviewApproved, CreatedAt: now},
+			{Author: "ttest", State: pull.ReviewChangesRequested, CreatedAt: now},
+		},
+		CommitsValue: []*pull.Commit{
+			{SHA: "1", Author: "mhaypenny", Committer: "contributor"},
+		},
+	}
+
+	tests := map[string]struct {
+		Predicate *ApprovalCount
+		Expected  bool
+	}{
+		"zeroApprovals": {
+			&ApprovalCount{Count: "< 1"},
+			false,
+		},
+		"countsEachUserOnce": {
+			&ApprovalCount{Count: "> 1"},
+			true,
+		},
+		"ignoresAuthorAndContributors": {
+			&ApprovalCount{Count: "> 2"},
+			false,
+		},
+		"customMethods": {
+			&ApprovalCount{Count: "> 0", Methods: &common.Methods{Comments: []string{"approved"}}},
+			true,
+		},
+		"customMethodsExcludeDefaults": {
+			&ApprovalCount{Count: "> 1", Methods: &common.Methods{Comments: []string{"approved"}}},
+			false,
+		},
+		"noCondition": {
+			&ApprovalCount{},
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ok, _, err := test.Predicate.Evaluate(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, ok, "predicate was not correct")
+		})
+	}
+}
+
+func TestHasReviewState(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	reviews := []*pull.Review{
+		{CreatedAt: now.Add(10 * time.Second), Author: "bkeyes", State: pull.ReviewChangesRequested},
+		{CreatedAt: now.Add(20 * time.Second), Author: "ttest", State: pull.ReviewCommented},
+		{CreatedAt: now.Add(30 * time.Second), Author: "bkeyes", State: pull.ReviewApproved},
+		{CreatedAt: now.Add(40 * time.Second), Author: "mhaypenny", State: pull.ReviewChangesRequested},
+		{CreatedAt: now.Add(50 * time.Second), Author: "mhaypenny", State: pull.ReviewCommented},
+	}
+
+	tests := map[string]struct {
+		Predicate *HasReviewState
+		Reviews   []*pull.Review
+		Expected  bool
+	}{
+		"noReviews": {
+			&HasReviewState{States: []pull.ReviewState{pull.ReviewChangesRequested}},
+			nil,
+			false,
+		},
+		"currentChangesRequested": {
+			&HasReviewState{States: []pull.ReviewState{pull.ReviewChangesRequested}},
+			reviews,
+			true,
+		},
+		"supersededChangesRequested": {
+			&HasReviewState{States: []pull.ReviewState{pull.ReviewChangesRequested}},
+			reviews[:3],
+			false,
+		},
+		"includeSuperseded": {
+			&HasReviewState{States: []pull.ReviewState{pull.ReviewChangesRequested}, IncludeSuperseded: true},
+			reviews[:3],
+			true,
+		},
+		"commentDoesNotSupersede": {
+			&HasReviewState{States: []pull.ReviewState{pull.ReviewCommented}},
+			reviews[3:],
+			false,
+		},
+		"onlyComments": {
+			&HasReviewState{States: []pull.ReviewState{pull.ReviewCommented}},
+			reviews[:2],
+			true,
+		},
+		"noStates": {
+			&HasReviewState{},
+			reviews,
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prctx := &pulltest.Context{}
+			for _, r := range test.Reviews {
+				if r.State == pull.ReviewCommented {
+					prctx.CommentedReviewsValue = append(prctx.CommentedReviewsValue, r)
+				} else {
+					prctx.ReviewsValue = append(prctx.ReviewsValue, r)
+				}
+			}
+
+			ok, _, err := test.Predicate.Evaluate(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, ok, "predicate was not correct")
+		})
+	}
+}
+
+func TestRequestedTeams(t *testing.T) {
+	ctx := context.Background()
+
+	p := &RequestedTeams{
+		Teams: []string{"testorg/security", "testorg/Release-Managers"},
+	}
+	anyTeam := &RequestedTeams{}
+
+	tests := map[string]struct {
+		Requests *pull.ReviewRequests
+		Expected bool
+		Any      bool
+	}{
+		"noRequests":       {&pull.ReviewRequests{}, false, false},
+		"onlyUsers":        {&pull.ReviewRequests{Users: []string{"ttest"}}, false, false},
+		"otherTeam":        {&pull.ReviewRequests{Teams: []string{"testorg/frontend"}}, false, true},
+		"requestedTeam":    {&pull.ReviewRequests{Teams: []string{"testorg/frontend", "testorg/security"}}, true, true},
+		"caseInsensitive":  {&pull.ReviewRequests{Teams: []string{"testorg/release-managers"}}, true, true},
+		"otherOrgSameName": {&pull.ReviewRequests{Teams: []string{"otherorg/security"}}, false, true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prctx := &pulltest.Context{
+				RequestedReviewersValue: test.Requests,
+			}
+
+			ok, _, err := p.Evaluate(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, ok, "predicate was not correct")
+
+			ok, _, err = anyTeam.Evaluate(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, test.Any, ok, "predicate without teams was not correct")
+		})
+	}
+}
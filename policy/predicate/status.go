@@ -0,0 +1,48 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// HasMissingStatus is satisfied if any of the listed status contexts or check
+// runs has not reported for the head commit. A status that reported in any
+// state, including failure or pending, is not missing.
+type HasMissingStatus struct {
+	Statuses []string `yaml:"statuses"`
+}
+
+var _ Predicate = &HasMissingStatus{}
+
+func (pred *HasMissingStatus) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	statuses, err := prctx.Statuses()
+	if err != nil {
+		return false, "", errors.Wrap(err, "failed to list statuses")
+	}
+
+	for _, s := range pred.Statuses {
+		if _, ok := statuses[s]; !ok {
+			return true, "", nil
+		}
+	}
+
+	desc := "All of the listed statuses have reported"
+	return false, desc, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/palantir/policy-bot/pull/pulltest"
+)
+
+func TestHasMissingStatus(t *testing.T) {
+	ctx := context.Background()
+
+	p := &HasMissingStatus{
+		Statuses: []string{"ci/build", "lint"},
+	}
+
+	tests := map[string]struct {
+		Statuses map[string]string
+		Expected bool
+	}{
+		"noStatuses": {
+			Expected: true,
+		},
+		"someMissing": {
+			Statuses: map[string]string{
+				"ci/build": "success",
+				"ci/test":  "success",
+			},
+			Expected: true,
+		},
+		"allReported": {
+			Statuses: map[string]string{
+				"ci/build": "success",
+				"lint":     "in_progress",
+			},
+			Expected: false,
+		},
+		"failedIsNotMissing": {
+			Statuses: map[string]string{
+				"ci/build": "failure",
+				"lint":     "error",
+			},
+			Expected: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prctx := &pulltest.Context{
+				StatusesValue: test.Statuses,
+			}
+
+			ok, _, err := p.Evaluate(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, ok, "predicate was not correct")
+		})
+	}
+
+	t.Run("reportsLate", func(t *testing.T) {
+		prctx := &pulltest.Context{
+			StatusesValue: map[string]string{
+				"ci/build": "pending",
+			},
+		}
+
+		ok, _, err := p.Evaluate(ctx, prctx)
+		require.NoError(t, err)
+		assert.True(t, ok, "predicate should be satisfied before lint reports")
+
+		prctx.StatusesValue["lint"] = "queued"
+
+		ok, _, err = p.Evaluate(ctx, prctx)
+		require.NoError(t, err)
+		assert.False(t, ok, "predicate should not be satisfied after lint reports")
+	})
+}
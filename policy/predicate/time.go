@@ -0,0 +1,219 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// TimeWindow is a range of wall clock times on some days of the week. If End
+// is before Start, the window ends on the next day. Days are the days the
+// window starts on and include all days if empty.
+type TimeWindow struct {
+	Days  []string `yaml:"days"`
+	Start string   `yaml:"start"`
+	End   string   `yaml:"end"`
+}
+
+// WithinTimeWindow is satisfied if the time of evaluation is in any of the
+// windows. Times are compared in the configured timezone, which is UTC if
+// empty.
+type WithinTimeWindow struct {
+	Timezone string       `yaml:"timezone"`
+	Windows  []TimeWindow `yaml:"windows"`
+
+	// Now returns the current time; time.Now is used if nil. It can not be
+	// set in a policy.
+	Now func() time.Time `yaml:"-"`
+}
+
+var _ Predicate = &WithinTimeWindow{}
+var _ Validator = &WithinTimeWindow{}
+
+// Validate returns an error if the timezone or any window is invalid.
+func (pred *WithinTimeWindow) Validate() error {
+	if _, err := time.LoadLocation(pred.Timezone); err != nil {
+		return errors.Wrapf(err, "invalid timezone %q", pred.Timezone)
+	}
+	for i := range pred.Windows {
+		if err := pred.Windows[i].validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pred *WithinTimeWindow) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	t, window, err := pred.match()
+	if err != nil {
+		return false, "", err
+	}
+	if window == nil {
+		return false, fmt.Sprintf("%s is not within any time window", t.Format("Mon 15:04 MST")), nil
+	}
+	return true, "", nil
+}
+
+// OutsideTimeWindow is satisfied if the time of evaluation is not in any of
+// the windows. It is the inverse of WithinTimeWindow.
+type OutsideTimeWindow struct {
+	WithinTimeWindow `yaml:",inline"`
+}
+
+var _ Predicate = &OutsideTimeWindow{}
+
+func (pred *OutsideTimeWindow) Evaluate(ctx context.Context, prctx pull.Context) (bool, string, error) {
+	t, window, err := pred.match()
+	if err != nil {
+		return false, "", err
+	}
+	if window != nil {
+		return false, fmt.Sprintf("%s is within time window %s", t.Format("Mon 15:04 MST"), window), nil
+	}
+	return true, "", nil
+}
+
+// match returns the current time in the configured timezone and the first
+// window that contains it, or nil if no window contains it
+func (pred *WithinTimeWindow) match() (time.Time, *TimeWindow, error) {
+	loc, err := time.LoadLocation(pred.Timezone)
+	if err != nil {
+		return time.Time{}, nil, errors.Wrapf(err, "invalid timezone %q", pred.Timezone)
+	}
+
+	now := time.Now
+	if pred.Now != nil {
+		now = pred.Now
+	}
+	t := now().In(loc)
+
+	for i := range pred.Windows {
+		w := &pred.Windows[i]
+		ok, err := w.contains(t)
+		if err != nil {
+			return t, nil, err
+		}
+		if ok {
+			return t, w, nil
+		}
+	}
+	return t, nil, nil
+}
+
+func (w *TimeWindow) String() string {
+	days := "every day"
+	if len(w.Days) > 0 {
+		days = strings.Join(w.Days, ",")
+	}
+	return fmt.Sprintf("%s %s-%s", days, w.Start, w.End)
+}
+
+func (w *TimeWindow) validate() error {
+	if _, err := parseClock(w.Start, 0); err != nil {
+		return err
+	}
+	if _, err := parseClock(w.End, 24*60); err != nil {
+		return err
+	}
+	for _, d := range w.Days {
+		if _, err := parseWeekday(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contains returns true if the wall clock time of t is in the window
+func (w *TimeWindow) contains(t time.Time) (bool, error) {
+	start, err := parseClock(w.Start, 0)
+	if err != nil {
+		return false, err
+	}
+	end, err := parseClock(w.End, 24*60)
+	if err != nil {
+		return false, err
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	if start <= end {
+		if minute < start || minute >= end {
+			return false, nil
+		}
+	} else {
+		// the window crosses midnight, so early times belong to the window
+		// that started on the previous day
+		switch {
+		case minute >= start:
+		case minute < end:
+			day = (day + 6) % 7
+		default:
+			return false, nil
+		}
+	}
+	return w.onDay(day)
+}
+
+func (w *TimeWindow) onDay(day time.Weekday) (bool, error) {
+	if len(w.Days) == 0 {
+		return true, nil
+	}
+	for _, d := range w.Days {
+		wd, err := parseWeekday(d)
+		if err != nil {
+			return false, err
+		}
+		if wd == day {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// parseClock returns the minutes since midnight for a time in "15:04" format,
+// or def if the time is empty
+func parseClock(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	if s == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, errors.Errorf("invalid time of day %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	if len(s) >= 3 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			name := strings.ToLower(d.String())
+			if strings.HasPrefix(name, strings.ToLower(s)) {
+				return d, nil
+			}
+		}
+	}
+	return 0, errors.Errorf("invalid day of the week %q", s)
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package predicate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/palantir/policy-bot/pull/pulltest"
+)
+
+func TestWithinTimeWindow(t *testing.T) {
+	ctx := context.Background()
+	prctx := &pulltest.Context{}
+
+	p := &WithinTimeWindow{
+		Timezone: "America/New_York",
+		Windows: []TimeWindow{
+			{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"},
+			{Days: []string{"Friday"}, Start: "22:00", End: "02:00"},
+		},
+	}
+
+	tests := map[string]struct {
+		Time     string
+		Expected bool
+	}{
+		"businessHours":       {"2018-03-05T14:00:00Z", true},
+		"beforeBusinessHours": {"2018-03-05T13:59:00Z", false},
+		"endIsExclusive":      {"2018-03-05T22:00:00Z", false},
+		"weekend":             {"2018-03-10T15:00:00Z", false},
+		"overnightStart":      {"2018-03-10T03:30:00Z", true},
+		"overnightNextDay":    {"2018-03-10T06:30:00Z", true},
+		"overnightWrongDay":   {"2018-03-11T06:30:00Z", false},
+		"afterDSTChange":      {"2018-03-12T13:00:00Z", true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			now, err := time.Parse(time.RFC3339, test.Time)
+			require.NoError(t, err)
+			p.Now = func() time.Time { return now }
+
+			ok, _, err := p.Evaluate(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, test.Expected, ok, "predicate was not correct")
+
+			inverse := &OutsideTimeWindow{WithinTimeWindow: *p}
+			ok, _, err = inverse.Evaluate(ctx, prctx)
+			require.NoError(t, err)
+			assert.Equal(t, !test.Expected, ok, "inverse predicate was not correct")
+		})
+	}
+}
+
+func TestWithinTimeWindowInvalid(t *testing.T) {
+	ctx := context.Background()
+	prctx := &pulltest.Context{}
+
+	tests := map[string]*WithinTimeWindow{
+		"timezone": {Timezone: "Mars/Olympus_Mons", Windows: []TimeWindow{{Start: "09:00"}}},
+		"time":     {Windows: []TimeWindow{{Start: "9am"}}},
+		"day":      {Windows: []TimeWindow{{Days: []string{"someday"}}}},
+	}
+
+	for name, p := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := p.Evaluate(ctx, prctx)
+			assert.Error(t, err)
+			assert.Error(t, p.Validate(), "invalid predicate was not detected")
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// StatusTemplates customize the messages that describe the result of the
+// policy. Each is a Go text/template executed with StatusData. If a template
+// is empty, the default message is used.
+type StatusTemplates struct {
+	// Description replaces the description of the commit status
+	Description string `yaml:"description"`
+
+	// Summary replaces the summary at the top of the details page
+	Summary string `yaml:"summary"`
+}
+
+// StatusData is the data available to status templates.
+type StatusData struct {
+	// Status is the status of the policy, like "approved" or "pending"
+	Status string
+
+	// Description is the default message for the status
+	Description string
+
+	// Reason is the reason code of the result, if any
+	Reason string
+
+	// PendingRules are the names of the rules that are still pending
+	PendingRules []string
+
+	Owner      string
+	Repo       string
+	Number     int
+	Author     string
+	BaseBranch string
+	HeadBranch string
+
+	// AuthorIsBot is true if the author is a GitHub App
+	AuthorIsBot bool
+}
+
+var statusTemplateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+func (t *StatusTemplates) validate() error {
+	sample := &StatusData{PendingRules: []string{"rule"}}
+	for name, text := range map[string]string{"description": t.Description, "summary": t.Summary} {
+		if _, err := renderStatusTemplate(name, text, sample); err != nil {
+			return errors.WithMessage(err, "invalid status template")
+		}
+	}
+	return nil
+}
+
+// RenderDescription returns the description of the commit status.
+func (t *StatusTemplates) RenderDescription(data *StatusData) (string, error) {
+	return renderStatusTemplate("description", t.Description, data)
+}
+
+// RenderSummary returns the summary on the details page.
+func (t *StatusTemplates) RenderSummary(data *StatusData) (string, error) {
+	return renderStatusTemplate("summary", t.Summary, data)
+}
+
+func renderStatusTemplate(name, text string, data *StatusData) (string, error) {
+	if text == "" {
+		return data.Description, nil
+	}
+
+	tmpl, err := template.New(name).Funcs(statusTemplateFuncs).Parse(text)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse %s template", name)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", errors.Wrapf(err, "failed to execute %s template", name)
+	}
+	return strings.TrimSpace(out.String()), nil
+}
@@ -15,14 +15,16 @@
 package pull
 
 import (
+	"strings"
 	"time"
 )
 
 // MembershipContext defines methods to get information
 // about about user membership in Github organizations and teams.
 type MembershipContext interface {
-	// IsTeamMember returns true if the user is a member of the given team.
-	// Teams are specified as "org-name/team-name".
+	// IsTeamMember returns true if the user is a member of the given team,
+	// including through a child team. Teams are specified as
+	// "org-name/team-name".
 	IsTeamMember(team, user string) (bool, error)
 
 	// IsOrgMember returns true if the user is a member of the given organzation.
@@ -30,6 +32,24 @@ type MembershipContext interface {
 
 	// IsCollaborator returns true if the user meets the desiredPerm of the given organzation's repository.
 	IsCollaborator(org, repo, user, desiredPerm string) (bool, error)
+
+	// Teams returns all teams in the given organization. Teams are specified
+	// as "org-name/team-name".
+	Teams(org string) ([]string, error)
+
+	// TeamParent returns the parent of the given team, or an empty string if
+	// the team is not nested. Teams are specified as "org-name/team-name".
+	TeamParent(team string) (string, error)
+}
+
+// ExternalContext defines methods to get information about users from
+// services other than GitHub.
+type ExternalContext interface {
+	// ExternalCheck returns true if the user passes the named external check.
+	ExternalCheck(name, user string) (bool, error)
+
+	// ApproverList returns the logins of the users in the named list.
+	ApproverList(name string) ([]string, error)
 }
 
 // Context is the context for a pull request. It defines methods to get
@@ -40,6 +60,7 @@ type MembershipContext interface {
 // required to be thread-safe.
 type Context interface {
 	MembershipContext
+	ExternalContext
 
 	// RepositoryOwner returns the owner of the repo that the pull request targets.
 	RepositoryOwner() string
@@ -53,18 +74,71 @@ type Context interface {
 	// Author returns the username of the user who opened the pull request.
 	Author() string
 
+	// CreatedAt returns the time when the pull request was created.
+	CreatedAt() time.Time
+
 	// HeadSHA returns the SHA of the head commit of the pull request.
 	HeadSHA() string
 
+	// Title returns the title of the pull request.
+	Title() string
+
+	// Body returns the description of the pull request.
+	Body() string
+
+	// Assignees returns the login names of the users currently assigned to
+	// the pull request.
+	Assignees() ([]string, error)
+
+	// RequestedReviewers returns the users and teams with pending requests to
+	// review the pull request.
+	RequestedReviewers() (*ReviewRequests, error)
+
+	// ReviewRequestHistory returns the users and teams that were requested to
+	// review the pull request at any time, including requests that were
+	// removed or completed by a review.
+	ReviewRequestHistory() (*ReviewRequests, error)
+
 	// Branches returns the base (also known as target) and head branch names
 	// of this pull request. Branches in this repository have no prefix, while
 	// branches in forks are prefixed with the owner of the fork and a colon.
 	// The base branch will always be unprefixed.
 	Branches() (base string, head string)
 
+	// DefaultBranch returns the name of the default branch of the repository
+	// that the pull request targets.
+	DefaultBranch() (string, error)
+
+	// MergeMethods returns the methods allowed for merging pull requests in
+	// the repository that the pull request targets.
+	MergeMethods() (*MergeMethods, error)
+
+	// LabeledBy returns the event that most recently applied the label to the
+	// pull request. It returns nil if the label is not on the pull request.
+	LabeledBy(label string) (*LabelEvent, error)
+
+	// BehindBy returns the number of commits on the base branch that are not
+	// in the head branch. This is non-zero for diverged branches, even if the
+	// head branch also contains commits that are not on the base branch.
+	BehindBy() (int, error)
+
+	// Mergeable returns true if the pull request can be merged without
+	// conflicts. It returns nil if GitHub has not finished computing whether
+	// the pull request is mergeable.
+	Mergeable() (*bool, error)
+
 	// ChangedFiles returns the files that were changed in this pull request.
 	ChangedFiles() ([]*File, error)
 
+	// GitAttributes returns the content of the .gitattributes file at the
+	// root of the base branch, or an empty string if the file does not exist.
+	GitAttributes() (string, error)
+
+	// PullRequestTemplate returns the content of the pull request template on
+	// the base branch, or an empty string if the repository does not have a
+	// template.
+	PullRequestTemplate() (string, error)
+
 	// Commits returns the commits that are part of this pull request. The
 	// commit order is implementation dependent.
 	Commits() ([]*Commit, error)
@@ -76,6 +150,63 @@ type Context interface {
 	// Reviews lists all reviews on a Pull Request. The review order is
 	// implementation dependent.
 	Reviews() ([]*Review, error)
+
+	// CommentedReviews lists the reviews on a Pull Request that only left a
+	// comment, which Reviews does not include. The review order is
+	// implementation dependent.
+	CommentedReviews() ([]*Review, error)
+
+	// ReviewDismissals lists the dismissals of reviews on a Pull Request in
+	// chronological order.
+	ReviewDismissals() ([]*ReviewDismissal, error)
+
+	// ForcePushes lists the force-pushes to the head branch of the pull
+	// request in chronological order.
+	ForcePushes() ([]*ForcePush, error)
+
+	// ReviewComments lists all comments on the diff of a Pull Request. The
+	// comment order is implementation dependent.
+	ReviewComments() ([]*ReviewComment, error)
+
+	// Statuses returns the latest state of each commit status and check run
+	// reported for the head commit, keyed by status context or check name.
+	// Check runs use their conclusion if complete and their status otherwise.
+	Statuses() (map[string]string, error)
+
+	// WorkflowRuns returns the GitHub Actions workflow runs for the head
+	// commit, including earlier runs of workflows that were re-run, in the
+	// order they were created.
+	WorkflowRuns() ([]*WorkflowRun, error)
+
+	// UserCreatedAt returns the time when the account of the user with the
+	// given login was created.
+	UserCreatedAt(user string) (time.Time, error)
+
+	// MergedPullCount returns the number of pull requests by the user that
+	// were merged into the repository.
+	MergedPullCount(user string) (int, error)
+
+	// Issue returns the issue with the given number in the given repository.
+	// It returns nil if the issue does not exist or is not accessible.
+	Issue(owner, repo string, number int) (*Issue, error)
+
+	// LastAuthor returns the login of the author of the most recent commit
+	// that changed the file at path on the base branch. It returns an empty
+	// string if the file has no history on the base branch or if the author
+	// is not associated with a GitHub user.
+	LastAuthor(path string) (string, error)
+
+	// CompareFiles returns the files that changed between the merge base of
+	// the base and head commits and the head commit, including the patch of
+	// each file. Base and head may be commit SHAs or branch names. It returns
+	// nil if either commit does not exist or if the comparison is truncated
+	// because it changes MaxCompareFiles or more files.
+	CompareFiles(base, head string) ([]*File, error)
+
+	// RecentApprovals returns the approvals of the other pull requests in the
+	// repository that were updated within the lookback period, most recently
+	// updated first.
+	RecentApprovals(lookback time.Duration) ([]*PullApprovals, error)
 }
 
 type FileStatus int
@@ -91,6 +222,10 @@ type File struct {
 	Status    FileStatus
 	Additions int
 	Deletions int
+
+	// Patch is the unified diff of the file. It is empty if the file has no
+	// textual changes or if GitHub omits the diff because it is too large.
+	Patch string
 }
 
 type Commit struct {
@@ -106,9 +241,16 @@ type Commit struct {
 	// committer is not a real user.
 	Committer string
 
+	// AuthorEmail is the email address of the author as recorded in the
+	// commit. It is empty if the commit does not contain an email address.
+	AuthorEmail string
+
 	// PushedAt is the timestamp when the commit was pushed. It is nil if that
 	// information is not available for this commit.
 	PushedAt *time.Time
+
+	// Message is the full commit message.
+	Message string
 }
 
 // Users returns the login names of the users associated with this commit.
@@ -123,6 +265,49 @@ func (c *Commit) Users() []string {
 	return users
 }
 
+// IsBot returns true if the login belongs to a GitHub App, like
+// "dependabot[bot]".
+func IsBot(login string) bool {
+	return strings.HasSuffix(login, "[bot]")
+}
+
+type Issue struct {
+	Owner  string
+	Repo   string
+	Number int
+
+	// State is the state of the issue, either "open" or "closed"
+	State  string
+	Labels []string
+}
+
+// PullApprovals are the users who approved a pull request with a review.
+type PullApprovals struct {
+	Number    int
+	Author    string
+	UpdatedAt time.Time
+	Approvers []string
+}
+
+type MergeMethods struct {
+	Merge  bool
+	Squash bool
+	Rebase bool
+}
+
+// ReviewRequests are the users and teams with pending review requests. Teams
+// are specified as "org-name/team-name".
+type ReviewRequests struct {
+	Users []string
+	Teams []string
+}
+
+type LabelEvent struct {
+	Label     string
+	Actor     string
+	CreatedAt time.Time
+}
+
 type Comment struct {
 	CreatedAt time.Time
 	Author    string
@@ -145,6 +330,50 @@ type Review struct {
 	State     ReviewState
 	Body      string
 
+	// SHA is the head commit of the pull request when the review was
+	// submitted.
+	SHA string
+
 	// ID is the GitHub node ID of the review, used to resolve dismissals
 	ID string
 }
+
+// ReviewDismissal is the dismissal of a review. Actor is the user who
+// dismissed the review submitted by Reviewer.
+type ReviewDismissal struct {
+	CreatedAt time.Time
+	Actor     string
+	Reviewer  string
+}
+
+// WorkflowRun is a run of a GitHub Actions workflow. The status is "queued",
+// "in_progress", or "completed", and completed runs have a conclusion, like
+// "success", "failure", or "skipped".
+type WorkflowRun struct {
+	Workflow   string
+	CreatedAt  time.Time
+	Status     string
+	Conclusion string
+}
+
+// ForcePush is a force-push by Actor that replaced the head commit BeforeSHA
+// with AfterSHA. BeforeSHA is empty if the replaced commit no longer exists.
+type ForcePush struct {
+	CreatedAt time.Time
+	Actor     string
+	BeforeSHA string
+	AfterSHA  string
+}
+
+// ReviewComment is a comment on a line or file in the diff of a pull request.
+type ReviewComment struct {
+	CreatedAt time.Time
+	Author    string
+
+	// Path is the file the comment is on, relative to the repository root.
+	Path string
+
+	// Outdated is true if the lines the comment is on changed after the
+	// comment was created, so it no longer appears on the current diff.
+	Outdated bool
+}
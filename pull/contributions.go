@@ -0,0 +1,111 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pull
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// MergedPullCountTTL is how long the number of merged pull requests of a
+	// user is cached across evaluations. The search API has a low rate limit
+	// and counts change slowly, so this is conservative.
+	MergedPullCountTTL = 6 * time.Hour
+)
+
+// mergedPulls caches merged pull request counts for all contexts, keyed by
+// "owner/repo/user"
+var mergedPulls = &countCache{ttl: MergedPullCountTTL}
+
+// MergedPullCountKeys returns the keys of the unexpired merged pull request
+// counts cached for all contexts, in the form "owner/repo/user".
+func MergedPullCountKeys() []string {
+	return mergedPulls.keys()
+}
+
+// ClearMergedPullCounts removes the cached merged pull request counts with
+// keys that match and returns the number of counts removed.
+func ClearMergedPullCounts(match func(key string) bool) int {
+	return mergedPulls.clear(match)
+}
+
+type cachedCount struct {
+	count    int
+	loadedAt time.Time
+}
+
+type countCache struct {
+	ttl time.Duration
+
+	mu     sync.Mutex
+	counts map[string]cachedCount
+}
+
+func (c *countCache) get(key string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.counts[key]
+	if !ok || time.Since(cached.loadedAt) >= c.ttl {
+		return 0, false
+	}
+	return cached.count, true
+}
+
+func (c *countCache) set(key string, count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.counts == nil {
+		c.counts = make(map[string]cachedCount)
+	}
+	now := time.Now()
+	for k, cached := range c.counts {
+		if now.Sub(cached.loadedAt) >= c.ttl {
+			delete(c.counts, k)
+		}
+	}
+	c.counts[key] = cachedCount{count: count, loadedAt: now}
+}
+
+func (c *countCache) keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := make([]string, 0, len(c.counts))
+	for k, cached := range c.counts {
+		if time.Since(cached.loadedAt) < c.ttl {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *countCache) clear(match func(key string) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cleared := 0
+	for k := range c.counts {
+		if match(k) {
+			delete(c.counts, k)
+			cleared++
+		}
+	}
+	return cleared
+}
@@ -16,7 +16,9 @@ package pull
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -34,12 +36,21 @@ const (
 	// MaxPullRequestCommits is the max number of commits returned by GitHub
 	// https://developer.github.com/v3/pulls/#list-commits-on-a-pull-request
 	MaxPullRequestCommits = 250
+
+	// MaxCompareFiles is the max number of files returned by GitHub when
+	// comparing two commits
+	// https://developer.github.com/v3/repos/commits/#compare-two-commits
+	MaxCompareFiles = 300
 )
 
 var (
 	// 5 attempts, exponential 1000ms delay = 15s max wait
 	commitLoadMaxAttempts = 5
 	commitLoadBaseDelay   = 1000 * time.Millisecond
+
+	// 3 attempts, exponential 1000ms delay = 3s max wait
+	mergeableLoadMaxAttempts = 3
+	mergeableLoadBaseDelay   = 1000 * time.Millisecond
 )
 
 // Locator identifies a pull request and optionally contains a full or partial
@@ -50,6 +61,10 @@ type Locator struct {
 	Number int
 
 	Value *github.PullRequest
+
+	// SHA, if set, is used as the head commit of the pull request instead of
+	// its current head. It must be one of the pull request's commits.
+	SHA string
 }
 
 // IsComplete returns true if the locator contains a pull request object with
@@ -73,6 +88,17 @@ func (loc Locator) IsComplete() bool {
 
 // toV4 returns a v4PullRequest, loading data from the API if the locator is not complete.
 func (loc Locator) toV4(ctx context.Context, client *githubv4.Client) (*v4PullRequest, error) {
+	v4, err := loc.loadV4(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if loc.SHA != "" {
+		v4.HeadRefOID = loc.SHA
+	}
+	return v4, nil
+}
+
+func (loc Locator) loadV4(ctx context.Context, client *githubv4.Client) (*v4PullRequest, error) {
 	if !loc.IsComplete() {
 		var q struct {
 			Repository struct {
@@ -92,12 +118,15 @@ func (loc Locator) toV4(ctx context.Context, client *githubv4.Client) (*v4PullRe
 
 	var v4 v4PullRequest
 	v4.Author.Login = loc.Value.GetUser().GetLogin()
+	v4.CreatedAt = loc.Value.GetCreatedAt()
 	v4.IsCrossRepository = loc.Value.GetHead().GetRepo().GetID() != loc.Value.GetBase().GetRepo().GetID()
 	v4.HeadRefOID = loc.Value.GetHead().GetSHA()
 	v4.HeadRefName = loc.Value.GetHead().GetRef()
 	v4.HeadRepository.Name = loc.Value.GetHead().GetRepo().GetName()
 	v4.HeadRepository.Owner.Login = loc.Value.GetHead().GetRepo().GetOwner().GetLogin()
 	v4.BaseRefName = loc.Value.GetBase().GetRef()
+	v4.Title = loc.Value.GetTitle()
+	v4.Body = loc.Value.GetBody()
 	return &v4, nil
 }
 
@@ -105,6 +134,7 @@ func (loc Locator) toV4(ctx context.Context, client *githubv4.Client) (*v4PullRe
 // A new instance must be created for each request.
 type GitHubContext struct {
 	MembershipContext
+	ExternalContext
 
 	ctx      context.Context
 	client   *github.Client
@@ -114,21 +144,44 @@ type GitHubContext struct {
 	repo   string
 	number int
 	pr     *v4PullRequest
+	pinned bool
 
 	// cached fields
-	files      []*File
-	commits    []*Commit
-	comments   []*Comment
-	reviews    []*Review
-	teamIDs    map[string]int64
-	membership map[string]bool
+	files          []*File
+	attributes     *string
+	prTemplate     *string
+	defaultBranch  *string
+	repository     *github.Repository
+	behindBy       *int
+	mergeable      *bool
+	mergeableDone  bool
+	assignees      []string
+	reviewRequests *ReviewRequests
+	requestHistory *ReviewRequests
+	commits        []*Commit
+	comments       []*Comment
+	reviews        []*Review
+	commented      []*Review
+	reviewComments []*ReviewComment
+	statuses       map[string]string
+	workflowRuns   []*WorkflowRun
+	teamIDs        map[string]int64
+	membership     map[string]bool
+	userTimes      map[string]time.Time
+	issues         map[string]*Issue
+	lastAuthors    map[string]string
+	comparisons    map[string][]*File
+	labelEvents    map[string]*LabelEvent
+	dismissals     []*ReviewDismissal
+	forcePushes    []*ForcePush
+	approvals      map[time.Duration][]*PullApprovals
 }
 
 // NewGitHubContext creates a new pull.Context that makes GitHub requests to
 // obtain information. It caches responses for the lifetime of the context. The
 // pull request passed to the context must contain at least the base repository
 // and the number or the function panics.
-func NewGitHubContext(ctx context.Context, mbrCtx MembershipContext, client *github.Client, v4client *githubv4.Client, loc Locator) (Context, error) {
+func NewGitHubContext(ctx context.Context, mbrCtx MembershipContext, extCtx ExternalContext, client *github.Client, v4client *githubv4.Client, loc Locator) (Context, error) {
 	if loc.Owner == "" || loc.Repo == "" || loc.Number == 0 {
 		panic("pull request object does not contain full identifying information")
 	}
@@ -138,8 +191,9 @@ func NewGitHubContext(ctx context.Context, mbrCtx MembershipContext, client *git
 		return nil, err
 	}
 
-	return &GitHubContext{
+	ghc := &GitHubContext{
 		MembershipContext: mbrCtx,
+		ExternalContext:   extCtx,
 
 		ctx:      ctx,
 		client:   client,
@@ -149,7 +203,15 @@ func NewGitHubContext(ctx context.Context, mbrCtx MembershipContext, client *git
 		repo:   loc.Repo,
 		number: loc.Number,
 		pr:     pr,
-	}, nil
+		pinned: loc.SHA != "" && loc.SHA != loc.Value.GetHead().GetSHA(),
+	}
+
+	// webhook payloads include the repository, so avoid a request if possible
+	if branch := loc.Value.GetBase().GetRepo().GetDefaultBranch(); branch != "" {
+		ghc.defaultBranch = &branch
+	}
+
+	return ghc, nil
 }
 
 func (ghc *GitHubContext) RepositoryOwner() string {
@@ -165,13 +227,131 @@ func (ghc *GitHubContext) Number() int {
 }
 
 func (ghc *GitHubContext) Author() string {
-	return ghc.pr.Author.Login
+	return ghc.pr.Author.GetV3Login()
+}
+
+func (ghc *GitHubContext) CreatedAt() time.Time {
+	return ghc.pr.CreatedAt
 }
 
 func (ghc *GitHubContext) HeadSHA() string {
 	return ghc.pr.HeadRefOID
 }
 
+func (ghc *GitHubContext) Title() string {
+	return ghc.pr.Title
+}
+
+func (ghc *GitHubContext) Body() string {
+	return ghc.pr.Body
+}
+
+func (ghc *GitHubContext) Assignees() ([]string, error) {
+	if ghc.assignees == nil {
+		// load the issue instead of using the locator value, which may not
+		// reflect the current assignees
+		issue, _, err := ghc.client.Issues.Get(ghc.ctx, ghc.owner, ghc.repo, ghc.number)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get pull request assignees")
+		}
+
+		assignees := make([]string, 0, len(issue.Assignees))
+		for _, u := range issue.Assignees {
+			assignees = append(assignees, u.GetLogin())
+		}
+		ghc.assignees = assignees
+	}
+	return ghc.assignees, nil
+}
+
+func (ghc *GitHubContext) RequestedReviewers() (*ReviewRequests, error) {
+	if ghc.reviewRequests == nil {
+		requested, _, err := ghc.client.PullRequests.ListReviewers(ghc.ctx, ghc.owner, ghc.repo, ghc.number, &github.ListOptions{
+			PerPage: 100,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get requested reviewers")
+		}
+
+		// teams that can be requested always belong to the organization that
+		// owns the repository
+		requests := &ReviewRequests{}
+		for _, u := range requested.Users {
+			requests.Users = append(requests.Users, u.GetLogin())
+		}
+		for _, t := range requested.Teams {
+			requests.Teams = append(requests.Teams, ghc.owner+"/"+t.GetSlug())
+		}
+		ghc.reviewRequests = requests
+	}
+	return ghc.reviewRequests, nil
+}
+
+func (ghc *GitHubContext) ReviewRequestHistory() (*ReviewRequests, error) {
+	if ghc.requestHistory == nil {
+		var q struct {
+			Repository struct {
+				PullRequest struct {
+					TimelineItems struct {
+						PageInfo v4PageInfo
+						Nodes    []struct {
+							ReviewRequestedEvent struct {
+								RequestedReviewer struct {
+									User struct {
+										Login string
+									} `graphql:"... on User"`
+									Team struct {
+										Slug         string
+										Organization struct {
+											Login string
+										}
+									} `graphql:"... on Team"`
+								}
+							} `graphql:"... on ReviewRequestedEvent"`
+						}
+					} `graphql:"timelineItems(first: 100, after: $cursor, itemTypes: [REVIEW_REQUESTED_EVENT])"`
+				} `graphql:"pullRequest(number: $number)"`
+			} `graphql:"repository(owner: $owner, name: $name)"`
+		}
+		qvars := map[string]interface{}{
+			"owner":  githubv4.String(ghc.owner),
+			"name":   githubv4.String(ghc.repo),
+			"number": githubv4.Int(ghc.number),
+			"cursor": (*githubv4.String)(nil),
+		}
+
+		history := &ReviewRequests{}
+		seen := make(map[string]bool)
+		for {
+			if err := ghc.v4client.Query(ghc.ctx, &q, qvars); err != nil {
+				return nil, errors.Wrap(err, "failed to load review request history")
+			}
+			for _, n := range q.Repository.PullRequest.TimelineItems.Nodes {
+				// requests for other reviewer types, like bots, are ignored
+				reviewer := n.ReviewRequestedEvent.RequestedReviewer
+				switch {
+				case reviewer.User.Login != "":
+					if !seen[reviewer.User.Login] {
+						seen[reviewer.User.Login] = true
+						history.Users = append(history.Users, reviewer.User.Login)
+					}
+				case reviewer.Team.Slug != "":
+					team := reviewer.Team.Organization.Login + "/" + reviewer.Team.Slug
+					if !seen[team] {
+						seen[team] = true
+						history.Teams = append(history.Teams, team)
+					}
+				}
+			}
+			if !q.Repository.PullRequest.TimelineItems.PageInfo.UpdateCursor(qvars, "cursor") {
+				break
+			}
+		}
+		ghc.requestHistory = history
+	}
+	return ghc.requestHistory, nil
+}
+
 // Branches returns the names of the base and head branch. If the head branch
 // is from another repository (it is a fork) then the branch name is
 // `owner:branchName`.
@@ -184,7 +364,104 @@ func (ghc *GitHubContext) Branches() (base string, head string) {
 	return
 }
 
+func (ghc *GitHubContext) DefaultBranch() (string, error) {
+	if ghc.defaultBranch == nil {
+		repo, err := ghc.loadRepository()
+		if err != nil {
+			return "", err
+		}
+		branch := repo.GetDefaultBranch()
+		ghc.defaultBranch = &branch
+	}
+	return *ghc.defaultBranch, nil
+}
+
+func (ghc *GitHubContext) MergeMethods() (*MergeMethods, error) {
+	repo, err := ghc.loadRepository()
+	if err != nil {
+		return nil, err
+	}
+	return &MergeMethods{
+		Merge:  repo.GetAllowMergeCommit(),
+		Squash: repo.GetAllowSquashMerge(),
+		Rebase: repo.GetAllowRebaseMerge(),
+	}, nil
+}
+
+// loadRepository returns the full repository object of the base repository.
+// Webhook payloads do not include all fields of the repository.
+func (ghc *GitHubContext) loadRepository() (*github.Repository, error) {
+	if ghc.repository == nil {
+		repo, _, err := ghc.client.Repositories.Get(ghc.ctx, ghc.owner, ghc.repo)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get repository")
+		}
+		ghc.repository = repo
+	}
+	return ghc.repository, nil
+}
+
+func (ghc *GitHubContext) BehindBy() (int, error) {
+	if ghc.behindBy == nil {
+		comparison, _, err := ghc.client.Repositories.CompareCommits(ghc.ctx, ghc.owner, ghc.repo, ghc.pr.BaseRefName, ghc.pr.HeadRefOID)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to compare base and head commits")
+		}
+		behindBy := comparison.GetBehindBy()
+		ghc.behindBy = &behindBy
+	}
+	return *ghc.behindBy, nil
+}
+
+func (ghc *GitHubContext) Mergeable() (*bool, error) {
+	log := zerolog.Ctx(ghc.ctx)
+
+	// github only computes mergeability for the current head, so it is
+	// unknown for older commits
+	if ghc.pinned {
+		return nil, nil
+	}
+
+	// github computes mergeability in the background after a request for the
+	// pull request, so the first responses may not contain a value
+	attempts := 0
+	for !ghc.mergeableDone {
+		pr, _, err := ghc.client.PullRequests.Get(ghc.ctx, ghc.owner, ghc.repo, ghc.number)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get pull request")
+		}
+		ghc.mergeable = pr.Mergeable
+
+		attempts++
+		if ghc.mergeable != nil || attempts >= mergeableLoadMaxAttempts {
+			ghc.mergeableDone = true
+			break
+		}
+
+		delay := time.Duration(1<<uint(attempts-1)) * mergeableLoadBaseDelay
+		log.Debug().Msgf("mergeability is unknown on attempt %d, sleeping %s and trying again", attempts, delay)
+		time.Sleep(delay)
+	}
+	return ghc.mergeable, nil
+}
+
 func (ghc *GitHubContext) ChangedFiles() ([]*File, error) {
+	if ghc.files == nil && ghc.pinned {
+		// the pull request file list always reflects the current head, so
+		// compare the pinned commit with the base branch instead
+		comparison, _, err := ghc.client.Repositories.CompareCommits(ghc.ctx, ghc.owner, ghc.repo, ghc.pr.BaseRefName, ghc.pr.HeadRefOID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compare commits")
+		}
+		if len(comparison.Files) >= MaxCompareFiles {
+			return nil, errors.Errorf("too many files changed at commit %.10s, maximum is %d", ghc.pr.HeadRefOID, MaxCompareFiles)
+		}
+
+		ghc.files = make([]*File, len(comparison.Files))
+		for i, f := range comparison.Files {
+			ghc.files[i] = toFile(&f)
+		}
+	}
 	if ghc.files == nil {
 		var opt github.ListOptions
 		var allFiles []*github.CommitFile
@@ -202,22 +479,7 @@ func (ghc *GitHubContext) ChangedFiles() ([]*File, error) {
 
 		ghc.files = make([]*File, len(allFiles))
 		for i, f := range allFiles {
-			var status FileStatus
-			switch f.GetStatus() {
-			case "added":
-				status = FileAdded
-			case "deleted":
-				status = FileDeleted
-			case "modified":
-				status = FileModified
-			}
-
-			ghc.files[i] = &File{
-				Filename:  f.GetFilename(),
-				Status:    status,
-				Additions: f.GetAdditions(),
-				Deletions: f.GetDeletions(),
-			}
+			ghc.files[i] = toFile(f)
 		}
 	}
 	if len(ghc.files) >= MaxPullRequestFiles {
@@ -226,6 +488,84 @@ func (ghc *GitHubContext) ChangedFiles() ([]*File, error) {
 	return ghc.files, nil
 }
 
+func toFile(f *github.CommitFile) *File {
+	var status FileStatus
+	switch f.GetStatus() {
+	case "added":
+		status = FileAdded
+	case "deleted":
+		status = FileDeleted
+	case "modified":
+		status = FileModified
+	}
+
+	return &File{
+		Filename:  f.GetFilename(),
+		Status:    status,
+		Additions: f.GetAdditions(),
+		Deletions: f.GetDeletions(),
+		Patch:     f.GetPatch(),
+	}
+}
+
+func (ghc *GitHubContext) GitAttributes() (string, error) {
+	if ghc.attributes == nil {
+		opts := &github.RepositoryContentGetOptions{
+			Ref: ghc.pr.BaseRefName,
+		}
+
+		var content string
+		file, _, _, err := ghc.client.Repositories.GetContents(ghc.ctx, ghc.owner, ghc.repo, ".gitattributes", opts)
+		if err != nil && !isNotFound(err) {
+			return "", errors.Wrap(err, "failed to get .gitattributes")
+		}
+		if file != nil {
+			content, err = file.GetContent()
+			if err != nil {
+				return "", errors.Wrap(err, "failed to decode .gitattributes")
+			}
+		}
+		ghc.attributes = &content
+	}
+	return *ghc.attributes, nil
+}
+
+// pullRequestTemplatePaths are the locations GitHub checks for a pull request
+// template, in order
+var pullRequestTemplatePaths = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+}
+
+func (ghc *GitHubContext) PullRequestTemplate() (string, error) {
+	if ghc.prTemplate == nil {
+		opts := &github.RepositoryContentGetOptions{
+			Ref: ghc.pr.BaseRefName,
+		}
+
+		var content string
+		for _, path := range pullRequestTemplatePaths {
+			file, _, _, err := ghc.client.Repositories.GetContents(ghc.ctx, ghc.owner, ghc.repo, path, opts)
+			if err != nil && !isNotFound(err) {
+				return "", errors.Wrapf(err, "failed to get %s", path)
+			}
+			if file != nil {
+				content, err = file.GetContent()
+				if err != nil {
+					return "", errors.Wrapf(err, "failed to decode %s", path)
+				}
+				break
+			}
+		}
+		ghc.prTemplate = &content
+	}
+	return *ghc.prTemplate, nil
+}
+
 func (ghc *GitHubContext) Commits() ([]*Commit, error) {
 	if ghc.commits == nil {
 		commits, err := ghc.loadCommits()
@@ -236,6 +576,10 @@ func (ghc *GitHubContext) Commits() ([]*Commit, error) {
 			return nil, errors.Errorf("too many commits in pull request, maximum is %d", MaxPullRequestCommits)
 		}
 
+		if ghc.pinned {
+			commits = ancestorCommits(commits, ghc.pr.HeadRefOID)
+		}
+
 		backfillPushedAt(commits, ghc.pr.HeadRefOID)
 		ghc.commits = commits
 	}
@@ -260,6 +604,508 @@ func (ghc *GitHubContext) Reviews() ([]*Review, error) {
 	return ghc.reviews, nil
 }
 
+func (ghc *GitHubContext) CommentedReviews() ([]*Review, error) {
+	if ghc.commented == nil {
+		var q struct {
+			Repository struct {
+				PullRequest struct {
+					Reviews struct {
+						PageInfo v4PageInfo
+						Nodes    []v4PullRequestReview
+					} `graphql:"reviews(first: 100, after: $cursor, states: [COMMENTED])"`
+				} `graphql:"pullRequest(number: $number)"`
+			} `graphql:"repository(owner: $owner, name: $name)"`
+		}
+		qvars := map[string]interface{}{
+			"owner":  githubv4.String(ghc.owner),
+			"name":   githubv4.String(ghc.repo),
+			"number": githubv4.Int(ghc.number),
+			"cursor": (*githubv4.String)(nil),
+		}
+
+		reviews := []*Review{}
+		for {
+			if err := ghc.v4client.Query(ghc.ctx, &q, qvars); err != nil {
+				return nil, errors.Wrap(err, "failed to load commented reviews")
+			}
+			for _, r := range q.Repository.PullRequest.Reviews.Nodes {
+				reviews = append(reviews, r.ToReview())
+			}
+			if !q.Repository.PullRequest.Reviews.PageInfo.UpdateCursor(qvars, "cursor") {
+				break
+			}
+		}
+		ghc.commented = reviews
+	}
+	return ghc.commented, nil
+}
+
+func (ghc *GitHubContext) ReviewDismissals() ([]*ReviewDismissal, error) {
+	if ghc.dismissals == nil {
+		var q struct {
+			Repository struct {
+				PullRequest struct {
+					TimelineItems struct {
+						PageInfo v4PageInfo
+						Nodes    []struct {
+							ReviewDismissedEvent struct {
+								Actor     v4Actor
+								CreatedAt time.Time
+								Review    struct {
+									Author v4Actor
+								}
+							} `graphql:"... on ReviewDismissedEvent"`
+						}
+					} `graphql:"timelineItems(first: 100, after: $cursor, itemTypes: [REVIEW_DISMISSED_EVENT])"`
+				} `graphql:"pullRequest(number: $number)"`
+			} `graphql:"repository(owner: $owner, name: $name)"`
+		}
+		qvars := map[string]interface{}{
+			"owner":  githubv4.String(ghc.owner),
+			"name":   githubv4.String(ghc.repo),
+			"number": githubv4.Int(ghc.number),
+			"cursor": (*githubv4.String)(nil),
+		}
+
+		dismissals := []*ReviewDismissal{}
+		for {
+			if err := ghc.v4client.Query(ghc.ctx, &q, qvars); err != nil {
+				return nil, errors.Wrap(err, "failed to load review dismissals")
+			}
+			for _, n := range q.Repository.PullRequest.TimelineItems.Nodes {
+				e := n.ReviewDismissedEvent
+				dismissals = append(dismissals, &ReviewDismissal{
+					CreatedAt: e.CreatedAt,
+					Actor:     e.Actor.GetV3Login(),
+					Reviewer:  e.Review.Author.GetV3Login(),
+				})
+			}
+			if !q.Repository.PullRequest.TimelineItems.PageInfo.UpdateCursor(qvars, "cursor") {
+				break
+			}
+		}
+		ghc.dismissals = dismissals
+	}
+	return ghc.dismissals, nil
+}
+
+func (ghc *GitHubContext) ForcePushes() ([]*ForcePush, error) {
+	if ghc.forcePushes == nil {
+		var q struct {
+			Repository struct {
+				PullRequest struct {
+					TimelineItems struct {
+						PageInfo v4PageInfo
+						Nodes    []struct {
+							HeadRefForcePushedEvent struct {
+								Actor        v4Actor
+								CreatedAt    time.Time
+								BeforeCommit *struct {
+									OID string
+								}
+								AfterCommit *struct {
+									OID string
+								}
+							} `graphql:"... on HeadRefForcePushedEvent"`
+						}
+					} `graphql:"timelineItems(first: 100, after: $cursor, itemTypes: [HEAD_REF_FORCE_PUSHED_EVENT])"`
+				} `graphql:"pullRequest(number: $number)"`
+			} `graphql:"repository(owner: $owner, name: $name)"`
+		}
+		qvars := map[string]interface{}{
+			"owner":  githubv4.String(ghc.owner),
+			"name":   githubv4.String(ghc.repo),
+			"number": githubv4.Int(ghc.number),
+			"cursor": (*githubv4.String)(nil),
+		}
+
+		pushes := []*ForcePush{}
+		for {
+			if err := ghc.v4client.Query(ghc.ctx, &q, qvars); err != nil {
+				return nil, errors.Wrap(err, "failed to load force-pushes")
+			}
+			for _, n := range q.Repository.PullRequest.TimelineItems.Nodes {
+				e := n.HeadRefForcePushedEvent
+				push := &ForcePush{
+					CreatedAt: e.CreatedAt,
+					Actor:     e.Actor.GetV3Login(),
+				}
+				if e.BeforeCommit != nil {
+					push.BeforeSHA = e.BeforeCommit.OID
+				}
+				if e.AfterCommit != nil {
+					push.AfterSHA = e.AfterCommit.OID
+				}
+				pushes = append(pushes, push)
+			}
+			if !q.Repository.PullRequest.TimelineItems.PageInfo.UpdateCursor(qvars, "cursor") {
+				break
+			}
+		}
+		ghc.forcePushes = pushes
+	}
+	return ghc.forcePushes, nil
+}
+
+func (ghc *GitHubContext) ReviewComments() ([]*ReviewComment, error) {
+	if ghc.reviewComments == nil {
+		opt := &github.PullRequestListCommentsOptions{
+			ListOptions: github.ListOptions{PerPage: 100},
+		}
+
+		comments := []*ReviewComment{}
+		for {
+			page, res, err := ghc.client.PullRequests.ListComments(ghc.ctx, ghc.owner, ghc.repo, ghc.number, opt)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list pull request review comments")
+			}
+			for _, c := range page {
+				comments = append(comments, &ReviewComment{
+					CreatedAt: c.GetCreatedAt(),
+					Author:    c.GetUser().GetLogin(),
+					Path:      c.GetPath(),
+					Outdated:  c.Position == nil,
+				})
+			}
+			if res.NextPage == 0 {
+				break
+			}
+			opt.Page = res.NextPage
+		}
+		ghc.reviewComments = comments
+	}
+	return ghc.reviewComments, nil
+}
+
+func (ghc *GitHubContext) Statuses() (map[string]string, error) {
+	if ghc.statuses == nil {
+		statuses := make(map[string]string)
+
+		opt := &github.ListOptions{PerPage: 100}
+		for {
+			combined, res, err := ghc.client.Repositories.GetCombinedStatus(ghc.ctx, ghc.owner, ghc.repo, ghc.pr.HeadRefOID, opt)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get commit statuses")
+			}
+			for _, s := range combined.Statuses {
+				statuses[s.GetContext()] = s.GetState()
+			}
+			if res.NextPage == 0 {
+				break
+			}
+			opt.Page = res.NextPage
+		}
+
+		checkOpt := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+		for {
+			checks, res, err := ghc.client.Checks.ListCheckRunsForRef(ghc.ctx, ghc.owner, ghc.repo, ghc.pr.HeadRefOID, checkOpt)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list check runs")
+			}
+			for _, c := range checks.CheckRuns {
+				state := c.GetConclusion()
+				if state == "" {
+					state = c.GetStatus()
+				}
+				statuses[c.GetName()] = state
+			}
+			if res.NextPage == 0 {
+				break
+			}
+			checkOpt.Page = res.NextPage
+		}
+
+		ghc.statuses = statuses
+	}
+	return ghc.statuses, nil
+}
+
+func (ghc *GitHubContext) WorkflowRuns() ([]*WorkflowRun, error) {
+	if ghc.workflowRuns == nil {
+		var q struct {
+			Repository struct {
+				Object struct {
+					Commit struct {
+						CheckSuites struct {
+							PageInfo v4PageInfo
+							Nodes    []struct {
+								CreatedAt   time.Time
+								Status      string
+								Conclusion  string
+								WorkflowRun *struct {
+									Workflow struct {
+										Name string
+									}
+								}
+							}
+						} `graphql:"checkSuites(first: 100, after: $cursor)"`
+					} `graphql:"... on Commit"`
+				} `graphql:"object(oid: $oid)"`
+			} `graphql:"repository(owner: $owner, name: $name)"`
+		}
+		qvars := map[string]interface{}{
+			"owner":  githubv4.String(ghc.owner),
+			"name":   githubv4.String(ghc.repo),
+			"oid":    githubv4.GitObjectID(ghc.pr.HeadRefOID),
+			"cursor": (*githubv4.String)(nil),
+		}
+
+		runs := []*WorkflowRun{}
+		for {
+			if err := ghc.v4client.Query(ghc.ctx, &q, qvars); err != nil {
+				return nil, errors.Wrap(err, "failed to load workflow runs")
+			}
+			for _, n := range q.Repository.Object.Commit.CheckSuites.Nodes {
+				// check suites created by other apps are not workflow runs
+				if n.WorkflowRun == nil {
+					continue
+				}
+				runs = append(runs, &WorkflowRun{
+					Workflow:   n.WorkflowRun.Workflow.Name,
+					CreatedAt:  n.CreatedAt,
+					Status:     strings.ToLower(n.Status),
+					Conclusion: strings.ToLower(n.Conclusion),
+				})
+			}
+			if !q.Repository.Object.Commit.CheckSuites.PageInfo.UpdateCursor(qvars, "cursor") {
+				break
+			}
+		}
+
+		sort.SliceStable(runs, func(i, j int) bool { return runs[i].CreatedAt.Before(runs[j].CreatedAt) })
+		ghc.workflowRuns = runs
+	}
+	return ghc.workflowRuns, nil
+}
+
+func (ghc *GitHubContext) UserCreatedAt(user string) (time.Time, error) {
+	if t, ok := ghc.userTimes[user]; ok {
+		return t, nil
+	}
+
+	u, _, err := ghc.client.Users.Get(ghc.ctx, user)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to get user %s", user)
+	}
+
+	if ghc.userTimes == nil {
+		ghc.userTimes = make(map[string]time.Time)
+	}
+	ghc.userTimes[user] = u.GetCreatedAt().Time
+	return u.GetCreatedAt().Time, nil
+}
+
+func (ghc *GitHubContext) MergedPullCount(user string) (int, error) {
+	key := fmt.Sprintf("%s/%s/%s", ghc.owner, ghc.repo, user)
+	if n, ok := mergedPulls.get(key); ok {
+		return n, nil
+	}
+
+	query := fmt.Sprintf("repo:%s/%s is:pr is:merged author:%s", ghc.owner, ghc.repo, user)
+	opt := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}}
+
+	res, _, err := ghc.client.Search.Issues(ghc.ctx, query, opt)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to search merged pull requests by %s", user)
+	}
+
+	mergedPulls.set(key, res.GetTotal())
+	return res.GetTotal(), nil
+}
+
+func (ghc *GitHubContext) Issue(owner, repo string, number int) (*Issue, error) {
+	key := fmt.Sprintf("%s/%s#%d", owner, repo, number)
+	if issue, ok := ghc.issues[key]; ok {
+		return issue, nil
+	}
+
+	var issue *Issue
+	i, _, err := ghc.client.Issues.Get(ghc.ctx, owner, repo, number)
+	switch {
+	case err == nil:
+		issue = &Issue{
+			Owner:  owner,
+			Repo:   repo,
+			Number: number,
+			State:  i.GetState(),
+		}
+		for _, l := range i.Labels {
+			issue.Labels = append(issue.Labels, l.GetName())
+		}
+	case isNotFound(err) || isForbidden(err) || isGone(err):
+		// the issue does not exist or the installation cannot access it
+	default:
+		return nil, errors.Wrapf(err, "failed to get issue %s", key)
+	}
+
+	if ghc.issues == nil {
+		ghc.issues = make(map[string]*Issue)
+	}
+	ghc.issues[key] = issue
+	return issue, nil
+}
+
+func (ghc *GitHubContext) LabeledBy(label string) (*LabelEvent, error) {
+	if ghc.labelEvents == nil {
+		labelEvents := make(map[string]*LabelEvent)
+
+		opt := &github.ListOptions{PerPage: 100}
+		for {
+			events, res, err := ghc.client.Issues.ListIssueEvents(ghc.ctx, ghc.owner, ghc.repo, ghc.number, opt)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list pull request events")
+			}
+
+			// events are returned in chronological order
+			for _, e := range events {
+				name := e.GetLabel().GetName()
+				switch e.GetEvent() {
+				case "labeled":
+					labelEvents[name] = &LabelEvent{
+						Label:     name,
+						Actor:     e.GetActor().GetLogin(),
+						CreatedAt: e.GetCreatedAt(),
+					}
+				case "unlabeled":
+					delete(labelEvents, name)
+				}
+			}
+
+			if res.NextPage == 0 {
+				break
+			}
+			opt.Page = res.NextPage
+		}
+		ghc.labelEvents = labelEvents
+	}
+	return ghc.labelEvents[label], nil
+}
+
+func (ghc *GitHubContext) LastAuthor(path string) (string, error) {
+	if author, ok := ghc.lastAuthors[path]; ok {
+		return author, nil
+	}
+
+	opt := &github.CommitsListOptions{
+		SHA:         ghc.pr.BaseRefName,
+		Path:        path,
+		ListOptions: github.ListOptions{PerPage: 1},
+	}
+	commits, _, err := ghc.client.Repositories.ListCommits(ghc.ctx, ghc.owner, ghc.repo, opt)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to list commits for %s", path)
+	}
+
+	var author string
+	if len(commits) > 0 {
+		author = commits[0].GetAuthor().GetLogin()
+	}
+
+	if ghc.lastAuthors == nil {
+		ghc.lastAuthors = make(map[string]string)
+	}
+	ghc.lastAuthors[path] = author
+	return author, nil
+}
+
+func (ghc *GitHubContext) CompareFiles(base, head string) ([]*File, error) {
+	key := base + "..." + head
+	if files, ok := ghc.comparisons[key]; ok {
+		return files, nil
+	}
+
+	comparison, _, err := ghc.client.Repositories.CompareCommits(ghc.ctx, ghc.owner, ghc.repo, base, head)
+	if err != nil {
+		if isNotFound(err) {
+			// commits replaced by a force-push may be garbage collected
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to compare commits %s and %s", base, head)
+	}
+
+	// the comparison omits files past the limit, so a full list means some
+	// changes may be missing and the files cannot be compared reliably
+	var files []*File
+	if len(comparison.Files) < MaxCompareFiles {
+		files = make([]*File, len(comparison.Files))
+		for i, f := range comparison.Files {
+			files[i] = toFile(&f)
+		}
+	}
+
+	if ghc.comparisons == nil {
+		ghc.comparisons = make(map[string][]*File)
+	}
+	ghc.comparisons[key] = files
+	return files, nil
+}
+
+func (ghc *GitHubContext) RecentApprovals(lookback time.Duration) ([]*PullApprovals, error) {
+	if approvals, ok := ghc.approvals[lookback]; ok {
+		return approvals, nil
+	}
+
+	var q struct {
+		Repository struct {
+			PullRequests struct {
+				PageInfo v4PageInfo
+				Nodes    []struct {
+					Number    int
+					Author    v4Actor
+					UpdatedAt time.Time
+					Reviews   struct {
+						Nodes []struct {
+							Author v4Actor
+						}
+					} `graphql:"reviews(first: 100, states: [APPROVED])"`
+				}
+			} `graphql:"pullRequests(first: 50, after: $cursor, orderBy: {field: UPDATED_AT, direction: DESC})"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	qvars := map[string]interface{}{
+		"owner":  githubv4.String(ghc.owner),
+		"name":   githubv4.String(ghc.repo),
+		"cursor": (*githubv4.String)(nil),
+	}
+
+	since := time.Now().Add(-lookback)
+	approvals := []*PullApprovals{}
+
+Pages:
+	for {
+		if err := ghc.v4client.Query(ghc.ctx, &q, qvars); err != nil {
+			return nil, errors.Wrap(err, "failed to load recent approvals")
+		}
+		for _, pr := range q.Repository.PullRequests.Nodes {
+			if pr.UpdatedAt.Before(since) {
+				break Pages
+			}
+			if pr.Number == ghc.number {
+				continue
+			}
+
+			a := &PullApprovals{
+				Number:    pr.Number,
+				Author:    pr.Author.GetV3Login(),
+				UpdatedAt: pr.UpdatedAt,
+			}
+			for _, r := range pr.Reviews.Nodes {
+				a.Approvers = append(a.Approvers, r.Author.GetV3Login())
+			}
+			approvals = append(approvals, a)
+		}
+		if !q.Repository.PullRequests.PageInfo.UpdateCursor(qvars, "cursor") {
+			break
+		}
+	}
+
+	if ghc.approvals == nil {
+		ghc.approvals = make(map[time.Duration][]*PullApprovals)
+	}
+	ghc.approvals[lookback] = approvals
+	return approvals, nil
+}
+
 func (ghc *GitHubContext) loadPagedData() error {
 	// this is a minor optimization: make max(c,r) requests instead of c+r
 	var q struct {
@@ -449,6 +1295,37 @@ func (ghc *GitHubContext) loadPushedAt(commits []*Commit) error {
 	return nil
 }
 
+// ancestorCommits returns the commits that are the commit with the given SHA
+// or one of its ancestors, preserving order
+func ancestorCommits(commits []*Commit, sha string) []*Commit {
+	commitsBySHA := make(map[string]*Commit, len(commits))
+	for _, c := range commits {
+		commitsBySHA[c.SHA] = c
+	}
+
+	ancestors := make(map[string]bool)
+	pending := []string{sha}
+	for len(pending) > 0 {
+		next := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		c, ok := commitsBySHA[next]
+		if !ok || ancestors[next] {
+			continue
+		}
+		ancestors[next] = true
+		pending = append(pending, c.Parents...)
+	}
+
+	var filtered []*Commit
+	for _, c := range commits {
+		if ancestors[c.SHA] {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
 func backfillPushedAt(commits []*Commit, headSHA string) {
 	commitsBySHA := make(map[string]*Commit, len(commits))
 	for _, c := range commits {
@@ -478,7 +1355,8 @@ func backfillPushedAt(commits []*Commit, headSHA string) {
 
 // if adding new fields to this struct, modify Locator#toV4() as well
 type v4PullRequest struct {
-	Author v4Actor
+	Author    v4Actor
+	CreatedAt time.Time
 
 	IsCrossRepository bool
 
@@ -490,6 +1368,9 @@ type v4PullRequest struct {
 	}
 
 	BaseRefName string
+
+	Title string
+	Body  string
 }
 
 type v4PageInfo struct {
@@ -518,6 +1399,9 @@ type v4PullRequestReview struct {
 	State       string
 	Body        string
 	SubmittedAt time.Time
+	Commit      struct {
+		OID string
+	}
 }
 
 func (r *v4PullRequestReview) ToReview() *Review {
@@ -526,6 +1410,7 @@ func (r *v4PullRequestReview) ToReview() *Review {
 		Author:    r.Author.GetV3Login(),
 		State:     ReviewState(strings.ToLower(r.State)),
 		Body:      r.Body,
+		SHA:       r.Commit.OID,
 	}
 }
 
@@ -553,6 +1438,7 @@ type v4Commit struct {
 	Committer       v4GitActor
 	CommittedViaWeb bool
 	PushedDate      *time.Time
+	Message         string
 	Parents         struct {
 		Nodes []struct {
 			OID string
@@ -572,7 +1458,9 @@ func (c *v4Commit) ToCommit() *Commit {
 		CommittedViaWeb: c.CommittedViaWeb,
 		Author:          c.Author.GetV3Login(),
 		Committer:       c.Committer.GetV3Login(),
+		AuthorEmail:     c.Author.Email,
 		PushedAt:        c.PushedDate,
+		Message:         c.Message,
 	}
 }
 
@@ -591,7 +1479,8 @@ func (a v4Actor) GetV3Login() string {
 }
 
 type v4GitActor struct {
-	User *v4Actor
+	Email string
+	User  *v4Actor
 }
 
 func (ga v4GitActor) GetV3Login() string {
@@ -602,8 +1491,20 @@ func (ga v4GitActor) GetV3Login() string {
 }
 
 func isNotFound(err error) bool {
+	return hasStatusCode(err, http.StatusNotFound)
+}
+
+func isForbidden(err error) bool {
+	return hasStatusCode(err, http.StatusForbidden)
+}
+
+func isGone(err error) bool {
+	return hasStatusCode(err, http.StatusGone)
+}
+
+func hasStatusCode(err error, code int) bool {
 	if rerr, ok := err.(*github.ErrorResponse); ok {
-		return rerr.Response.StatusCode == http.StatusNotFound
+		return rerr.Response.StatusCode == code
 	}
 	return false
 }
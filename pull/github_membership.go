@@ -26,16 +26,20 @@ type GitHubMembershipContext struct {
 	ctx    context.Context
 	client *github.Client
 
-	teamIDs    map[string]int64
-	membership map[string]bool
+	teamIDs     map[string]int64
+	teamParents map[string]string
+	orgTeams    map[string][]string
+	membership  map[string]bool
 }
 
 func NewGitHubMembershipContext(ctx context.Context, client *github.Client) *GitHubMembershipContext {
 	return &GitHubMembershipContext{
-		ctx:        ctx,
-		client:     client,
-		teamIDs:    make(map[string]int64),
-		membership: make(map[string]bool),
+		ctx:         ctx,
+		client:      client,
+		teamIDs:     make(map[string]int64),
+		teamParents: make(map[string]string),
+		orgTeams:    make(map[string][]string),
+		membership:  make(map[string]bool),
 	}
 }
 
@@ -77,6 +81,7 @@ func (mc *GitHubMembershipContext) IsTeamMember(team, user string) (bool, error)
 
 func (mc *GitHubMembershipContext) cacheTeamIDs(org string) error {
 	var opt github.ListOptions
+	var orgTeams []string
 	for {
 		teams, res, err := mc.client.Teams.ListTeams(mc.ctx, org, &opt)
 		if err != nil {
@@ -86,6 +91,10 @@ func (mc *GitHubMembershipContext) cacheTeamIDs(org string) error {
 		for _, t := range teams {
 			key := org + "/" + t.GetSlug()
 			mc.teamIDs[key] = t.GetID()
+			if t.Parent != nil {
+				mc.teamParents[key] = org + "/" + t.Parent.GetSlug()
+			}
+			orgTeams = append(orgTeams, key)
 		}
 
 		if res.NextPage == 0 {
@@ -93,9 +102,32 @@ func (mc *GitHubMembershipContext) cacheTeamIDs(org string) error {
 		}
 		opt.Page = res.NextPage
 	}
+
+	mc.orgTeams[org] = orgTeams
 	return nil
 }
 
+func (mc *GitHubMembershipContext) Teams(org string) ([]string, error) {
+	teams, ok := mc.orgTeams[org]
+	if !ok {
+		if err := mc.cacheTeamIDs(org); err != nil {
+			return nil, err
+		}
+		teams = mc.orgTeams[org]
+	}
+	return teams, nil
+}
+
+func (mc *GitHubMembershipContext) TeamParent(team string) (string, error) {
+	org := strings.Split(team, "/")[0]
+	if _, ok := mc.orgTeams[org]; !ok {
+		if err := mc.cacheTeamIDs(org); err != nil {
+			return "", err
+		}
+	}
+	return mc.teamParents[team], nil
+}
+
 func (mc *GitHubMembershipContext) IsOrgMember(org, user string) (bool, error) {
 	key := membershipKey(org, user)
 
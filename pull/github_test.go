@@ -18,6 +18,7 @@ import (
 	"context"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +32,8 @@ func init() {
 	// adjust attempts/delays for faster tests
 	commitLoadMaxAttempts = 2
 	commitLoadBaseDelay = time.Millisecond
+	mergeableLoadMaxAttempts = 2
+	mergeableLoadBaseDelay = time.Millisecond
 }
 
 func TestChangedFiles(t *testing.T) {
@@ -56,6 +59,7 @@ func TestChangedFiles(t *testing.T) {
 
 	assert.Equal(t, "README.md", files[2].Filename)
 	assert.Equal(t, FileModified, files[2].Status)
+	assert.Equal(t, "@@ -1,3 +1,4 @@\n # README\n+new line\n", files[2].Patch)
 
 	// verify that the file list is cached
 	files, err = ctx.ChangedFiles()
@@ -109,11 +113,14 @@ func TestCommits(t *testing.T) {
 	assert.Equal(t, "a6f3f69b64eaafece5a0d854eb4af11c0d64394c", commits[0].SHA)
 	assert.Equal(t, "mhaypenny", commits[0].Author)
 	assert.Equal(t, "mhaypenny", commits[0].Committer)
+	assert.Equal(t, "mhaypenny@example.com", commits[0].AuthorEmail)
 	assert.Equal(t, newTime(expectedTime), commits[0].PushedAt)
+	assert.Equal(t, "Add foo\n\nFixes #12", commits[0].Message)
 
 	assert.Equal(t, "1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9", commits[1].SHA)
 	assert.Equal(t, "mhaypenny", commits[1].Author)
 	assert.Equal(t, "mhaypenny", commits[1].Committer)
+	assert.Equal(t, "", commits[1].AuthorEmail)
 	assert.Equal(t, newTime(expectedTime), commits[1].PushedAt)
 
 	assert.Equal(t, "e05fcae367230ee709313dd2720da527d178ce43", commits[2].SHA)
@@ -163,6 +170,423 @@ func TestCommitsRetry(t *testing.T) {
 	assert.Equal(t, newTime(expectedTime.Add(48*time.Hour)), commits[2].PushedAt)
 }
 
+func TestPinnedSHA(t *testing.T) {
+	rp := &ResponsePlayer{}
+	rp.AddRule(
+		GraphQLNodePrefixMatcher("repository.pullRequest.commits"),
+		"testdata/responses/pull_commits.yml",
+	)
+	compareRule := rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo/compare/develop...1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9"),
+		"testdata/responses/repo_compare.yml",
+	)
+
+	ctx := makePinnedContext(t, rp, "1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9")
+	assert.Equal(t, "1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9", ctx.HeadSHA())
+
+	commits, err := ctx.Commits()
+	require.NoError(t, err)
+
+	require.Len(t, commits, 2, "incorrect number of commits")
+	assert.Equal(t, "a6f3f69b64eaafece5a0d854eb4af11c0d64394c", commits[0].SHA)
+	assert.Equal(t, "1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9", commits[1].SHA)
+
+	files, err := ctx.ChangedFiles()
+	require.NoError(t, err)
+
+	require.Len(t, files, 1, "incorrect number of files")
+	assert.Equal(t, 1, compareRule.Count, "no http request was made")
+	assert.Equal(t, "path/foo.txt", files[0].Filename)
+	assert.Equal(t, FileAdded, files[0].Status)
+
+	mergeable, err := ctx.Mergeable()
+	require.NoError(t, err)
+	assert.Nil(t, mergeable, "mergeability of the current head was used")
+}
+
+func TestPinnedSHATooManyFiles(t *testing.T) {
+	rp := &ResponsePlayer{}
+	rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo/compare/develop...1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9"),
+		"testdata/responses/repo_compare_truncated.yml",
+	)
+
+	ctx := makePinnedContext(t, rp, "1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9")
+
+	_, err := ctx.ChangedFiles()
+	assert.Error(t, err, "truncated comparison was not rejected")
+}
+
+func TestPinnedSHAMissing(t *testing.T) {
+	rp := &ResponsePlayer{}
+	rp.AddRule(
+		GraphQLNodePrefixMatcher("repository.pullRequest.commits"),
+		"testdata/responses/pull_commits.yml",
+	)
+
+	ctx := makePinnedContext(t, rp, "0492883704e64bb53834c7c5fbd5fc22d44dedda")
+
+	_, err := ctx.Commits()
+	assert.Error(t, err, "commit outside of the pull request was not rejected")
+}
+
+func TestLastAuthor(t *testing.T) {
+	rp := &ResponsePlayer{}
+	commitsRule := rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo/commits"),
+		"testdata/responses/repo_commits_path.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	author, err := ctx.LastAuthor("README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "ttest", author)
+	assert.Equal(t, 1, commitsRule.Count, "no http request was made")
+
+	// verify that the author is cached
+	author, err = ctx.LastAuthor("README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "ttest", author)
+	assert.Equal(t, 1, commitsRule.Count, "cached author was not used")
+}
+
+func TestDefaultBranch(t *testing.T) {
+	rp := &ResponsePlayer{}
+	repoRule := rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo"),
+		"testdata/responses/repo.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	branch, err := ctx.DefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "trunk", branch)
+
+	// verify that the branch is cached
+	_, err = ctx.DefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, 1, repoRule.Count, "cached branch was not used")
+
+	// verify that the branch in the pull request is used if it exists
+	pr := defaultTestPR()
+	pr.Base.Repo.DefaultBranch = github.String("develop")
+
+	ctx = makeContext(t, rp, pr)
+
+	branch, err = ctx.DefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "develop", branch)
+	assert.Equal(t, 1, repoRule.Count, "unnecessary http request was made")
+}
+
+func TestMergeMethods(t *testing.T) {
+	rp := &ResponsePlayer{}
+	repoRule := rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo"),
+		"testdata/responses/repo.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	methods, err := ctx.MergeMethods()
+	require.NoError(t, err)
+	assert.Equal(t, &MergeMethods{Squash: true, Rebase: true}, methods)
+
+	// verify that the repository is cached
+	_, err = ctx.DefaultBranch()
+	require.NoError(t, err)
+	assert.Equal(t, 1, repoRule.Count, "cached repository was not used")
+}
+
+func TestRequestedReviewers(t *testing.T) {
+	rp := &ResponsePlayer{}
+	reviewersRule := rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo/pulls/123/requested_reviewers"),
+		"testdata/responses/pull_requested_reviewers.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	requests, err := ctx.RequestedReviewers()
+	require.NoError(t, err)
+	assert.Equal(t, &ReviewRequests{
+		Users: []string{"ttest"},
+		Teams: []string{"testorg/yes-team"},
+	}, requests)
+
+	// verify that the requests are cached
+	_, err = ctx.RequestedReviewers()
+	require.NoError(t, err)
+	assert.Equal(t, 1, reviewersRule.Count, "cached review requests were not used")
+}
+
+func TestReviewRequestHistory(t *testing.T) {
+	rp := &ResponsePlayer{}
+	dataRule := rp.AddRule(
+		GraphQLNodePrefixMatcher("repository.pullRequest.timelineItems.nodes.ReviewRequestedEvent"),
+		"testdata/responses/pull_review_request_history.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	history, err := ctx.ReviewRequestHistory()
+	require.NoError(t, err)
+	assert.Equal(t, &ReviewRequests{
+		Users: []string{"ttest", "mhaypenny"},
+		Teams: []string{"testorg/yes-team"},
+	}, history)
+
+	// verify that the history is cached
+	_, err = ctx.ReviewRequestHistory()
+	require.NoError(t, err)
+	assert.Equal(t, 2, dataRule.Count, "cached review request history was not used")
+}
+
+func TestForcePushes(t *testing.T) {
+	rp := &ResponsePlayer{}
+	dataRule := rp.AddRule(
+		GraphQLNodePrefixMatcher("repository.pullRequest.timelineItems.nodes.HeadRefForcePushedEvent"),
+		"testdata/responses/pull_force_pushes.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	pushes, err := ctx.ForcePushes()
+	require.NoError(t, err)
+
+	expectedTime1, err := time.Parse(time.RFC3339, "2018-12-04T12:38:56Z")
+	require.NoError(t, err)
+	expectedTime2, err := time.Parse(time.RFC3339, "2018-12-05T09:12:30Z")
+	require.NoError(t, err)
+
+	assert.Equal(t, []*ForcePush{
+		{
+			CreatedAt: expectedTime1,
+			Actor:     "mhaypenny",
+			AfterSHA:  "f9d4a3f4ec5d4c3e5c8b0b0b1d1ad8f6e3b3c6a1",
+		},
+		{
+			CreatedAt: expectedTime2,
+			Actor:     "ttest",
+			BeforeSHA: "f9d4a3f4ec5d4c3e5c8b0b0b1d1ad8f6e3b3c6a1",
+			AfterSHA:  "e05fcae367230ee709313dd2720da527d178ce43",
+		},
+	}, pushes)
+
+	// verify that the force-pushes are cached
+	_, err = ctx.ForcePushes()
+	require.NoError(t, err)
+	assert.Equal(t, 2, dataRule.Count, "cached force-pushes were not used")
+}
+
+func TestLabeledBy(t *testing.T) {
+	rp := &ResponsePlayer{}
+	eventsRule := rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo/issues/123/events"),
+		"testdata/responses/issue_events.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	event, err := ctx.LabeledBy("emergency")
+	require.NoError(t, err)
+
+	expectedTime, err := time.Parse(time.RFC3339, "2018-12-04T12:38:56Z")
+	require.NoError(t, err)
+
+	if assert.NotNil(t, event, "label event was not found") {
+		assert.Equal(t, "bkeyes", event.Actor)
+		assert.Equal(t, expectedTime, event.CreatedAt)
+	}
+
+	event, err = ctx.LabeledBy("hotfix")
+	require.NoError(t, err)
+	if assert.NotNil(t, event, "label event was not found") {
+		assert.Equal(t, "ttest", event.Actor)
+	}
+
+	event, err = ctx.LabeledBy("missing")
+	require.NoError(t, err)
+	assert.Nil(t, event, "missing label has an event")
+
+	assert.Equal(t, 1, eventsRule.Count, "cached events were not used")
+}
+
+func TestCompareFiles(t *testing.T) {
+	rp := &ResponsePlayer{}
+	compareRule := rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo/compare/a6f3f69b64eaafece5a0d854eb4af11c0d64394c...1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9"),
+		"testdata/responses/repo_compare.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	files, err := ctx.CompareFiles("a6f3f69b64eaafece5a0d854eb4af11c0d64394c", "1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9")
+	require.NoError(t, err)
+
+	require.Len(t, files, 1, "incorrect number of files")
+	assert.Equal(t, "path/foo.txt", files[0].Filename)
+	assert.Equal(t, FileAdded, files[0].Status)
+
+	// verify that the comparison is cached
+	_, err = ctx.CompareFiles("a6f3f69b64eaafece5a0d854eb4af11c0d64394c", "1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9")
+	require.NoError(t, err)
+	assert.Equal(t, 1, compareRule.Count, "cached comparison was not used")
+
+	// verify that a truncated comparison returns no files
+	rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo/compare/develop...1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9"),
+		"testdata/responses/repo_compare_truncated.yml",
+	)
+
+	files, err = ctx.CompareFiles("develop", "1fc89f1cedf8e3f3ce516ab75b5952295c8ea5e9")
+	require.NoError(t, err)
+	assert.Nil(t, files, "truncated comparison returned files")
+}
+
+func TestPullRequestTemplate(t *testing.T) {
+	rp := &ResponsePlayer{}
+	templateRule := rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo/contents/.github/PULL_REQUEST_TEMPLATE.md"),
+		"testdata/responses/repo_pull_request_template.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	template, err := ctx.PullRequestTemplate()
+	require.NoError(t, err)
+	assert.Equal(t, "## Summary\n\n<!-- What does this change? -->\n\n## Testing\n", template)
+
+	// verify that the template is cached
+	_, err = ctx.PullRequestTemplate()
+	require.NoError(t, err)
+	assert.Equal(t, 1, templateRule.Count, "cached template was not used")
+
+	// verify that a missing template is not an error
+	template, err = makeContext(t, &ResponsePlayer{}, nil).PullRequestTemplate()
+	require.NoError(t, err)
+	assert.Equal(t, "", template)
+}
+
+func TestMergedPullCount(t *testing.T) {
+	rp := &ResponsePlayer{}
+	searchRule := rp.AddRule(
+		ExactPathMatcher("/search/issues"),
+		"testdata/responses/search_merged_pulls.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	count, err := ctx.MergedPullCount("contributor")
+	require.NoError(t, err)
+	assert.Equal(t, 14, count)
+
+	// verify that the count is cached, even by other contexts
+	count, err = makeContext(t, rp, nil).MergedPullCount("contributor")
+	require.NoError(t, err)
+	assert.Equal(t, 14, count)
+	assert.Equal(t, 1, searchRule.Count, "cached count was not used")
+	assert.Equal(t, []string{"testorg/testrepo/contributor"}, MergedPullCountKeys())
+
+	// verify that cleared counts are loaded again
+	cleared := ClearMergedPullCounts(func(key string) bool { return strings.HasPrefix(key, "testorg/testrepo/") })
+	assert.Equal(t, 1, cleared)
+	assert.Empty(t, MergedPullCountKeys())
+
+	_, err = ctx.MergedPullCount("contributor")
+	require.NoError(t, err)
+	assert.Equal(t, 2, searchRule.Count, "cleared count was used")
+}
+
+func TestRecentApprovals(t *testing.T) {
+	rp := &ResponsePlayer{}
+	dataRule := rp.AddRule(
+		GraphQLNodePrefixMatcher("repository.pullRequests"),
+		"testdata/responses/repo_recent_approvals.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	// include pull requests updated after the start of June 2018
+	lookback := time.Since(time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	approvals, err := ctx.RecentApprovals(lookback)
+	require.NoError(t, err)
+
+	require.Len(t, approvals, 2, "incorrect number of pull requests")
+	assert.Equal(t, 122, approvals[0].Number)
+	assert.Equal(t, "ttest", approvals[0].Author)
+	assert.Equal(t, []string{"mhaypenny"}, approvals[0].Approvers)
+	assert.Equal(t, 120, approvals[1].Number)
+	assert.Equal(t, "mhaypenny", approvals[1].Author)
+	assert.Equal(t, []string{"ttest", "policy-bot[bot]"}, approvals[1].Approvers)
+
+	// verify that the approvals are cached
+	_, err = ctx.RecentApprovals(lookback)
+	require.NoError(t, err)
+	assert.Equal(t, 2, dataRule.Count, "cached approvals were not used")
+}
+
+func TestReviewDismissals(t *testing.T) {
+	rp := &ResponsePlayer{}
+	dataRule := rp.AddRule(
+		GraphQLNodePrefixMatcher("repository.pullRequest.timelineItems"),
+		"testdata/responses/pull_review_dismissals.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	dismissals, err := ctx.ReviewDismissals()
+	require.NoError(t, err)
+
+	require.Len(t, dismissals, 2, "incorrect number of dismissals")
+	assert.Equal(t, "mhaypenny", dismissals[0].Actor)
+	assert.Equal(t, "mhaypenny", dismissals[0].Reviewer)
+	assert.Equal(t, time.Date(2018, 6, 27, 20, 33, 26, 0, time.UTC), dismissals[0].CreatedAt)
+	assert.Equal(t, "ttest", dismissals[1].Actor)
+	assert.Equal(t, "policy-bot[bot]", dismissals[1].Reviewer)
+
+	// verify that the dismissals are cached
+	_, err = ctx.ReviewDismissals()
+	require.NoError(t, err)
+	assert.Equal(t, 2, dataRule.Count, "cached dismissals were not used")
+}
+
+func TestWorkflowRuns(t *testing.T) {
+	rp := &ResponsePlayer{}
+	dataRule := rp.AddRule(
+		GraphQLNodePrefixMatcher("repository.object.Commit.checkSuites"),
+		"testdata/responses/pull_workflow_runs.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	runs, err := ctx.WorkflowRuns()
+	require.NoError(t, err)
+
+	require.Len(t, runs, 3, "incorrect number of workflow runs")
+	assert.Equal(t, &WorkflowRun{
+		Workflow:   "test",
+		CreatedAt:  time.Date(2018, 6, 27, 20, 33, 26, 0, time.UTC),
+		Status:     "completed",
+		Conclusion: "failure",
+	}, runs[0])
+	assert.Equal(t, &WorkflowRun{
+		Workflow:  "build",
+		CreatedAt: time.Date(2018, 6, 27, 20, 35, 0, 0, time.UTC),
+		Status:    "in_progress",
+	}, runs[1])
+	assert.Equal(t, "test", runs[2].Workflow)
+	assert.Equal(t, "success", runs[2].Conclusion)
+
+	// verify that the runs are cached
+	_, err = ctx.WorkflowRuns()
+	require.NoError(t, err)
+	assert.Equal(t, 2, dataRule.Count, "cached workflow runs were not used")
+}
+
 func TestReviews(t *testing.T) {
 	rp := &ResponsePlayer{}
 	dataRule := rp.AddRule(
@@ -190,6 +614,7 @@ func TestReviews(t *testing.T) {
 	assert.Equal(t, expectedTime.Add(time.Second), reviews[1].CreatedAt)
 	assert.Equal(t, ReviewApproved, reviews[1].State)
 	assert.Equal(t, "the body", reviews[1].Body)
+	assert.Equal(t, "e05fcae367230ee709313dd2720da527d178ce43", reviews[1].SHA)
 
 	// verify that the review list is cached
 	reviews, err = ctx.Reviews()
@@ -199,6 +624,97 @@ func TestReviews(t *testing.T) {
 	assert.Equal(t, 2, dataRule.Count, "cached reviews were not used")
 }
 
+func TestCommentedReviews(t *testing.T) {
+	rp := &ResponsePlayer{}
+	dataRule := rp.AddRule(
+		GraphQLNodePrefixMatcher("repository.pullRequest.reviews"),
+		"testdata/responses/pull_commented_reviews.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	reviews, err := ctx.CommentedReviews()
+	require.NoError(t, err)
+
+	require.Len(t, reviews, 2, "incorrect number of reviews")
+
+	expectedTime, err := time.Parse(time.RFC3339, "2018-06-27T20:33:26Z")
+	require.NoError(t, err)
+
+	assert.Equal(t, "mhaypenny", reviews[0].Author)
+	assert.Equal(t, expectedTime, reviews[0].CreatedAt)
+	assert.Equal(t, ReviewCommented, reviews[0].State)
+	assert.Equal(t, "LGTM", reviews[0].Body)
+	assert.Equal(t, "e05fcae367230ee709313dd2720da527d178ce43", reviews[0].SHA)
+
+	assert.Equal(t, "bkeyes", reviews[1].Author)
+	assert.Equal(t, ReviewCommented, reviews[1].State)
+
+	// verify that the review list is cached
+	_, err = ctx.CommentedReviews()
+	require.NoError(t, err)
+	assert.Equal(t, 2, dataRule.Count, "cached commented reviews were not used")
+}
+
+func TestReviewComments(t *testing.T) {
+	rp := &ResponsePlayer{}
+	commentsRule := rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo/pulls/123/comments"),
+		"testdata/responses/pull_review_comments.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	comments, err := ctx.ReviewComments()
+	require.NoError(t, err)
+
+	require.Len(t, comments, 2, "incorrect number of review comments")
+	assert.Equal(t, 2, commentsRule.Count, "no http request was made")
+
+	expectedTime, err := time.Parse(time.RFC3339, "2018-06-27T20:33:26Z")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "bkeyes", comments[0].Author)
+	assert.Equal(t, expectedTime, comments[0].CreatedAt)
+	assert.Equal(t, "path/foo.txt", comments[0].Path)
+	assert.False(t, comments[0].Outdated)
+
+	assert.Equal(t, "mhaypenny", comments[1].Author)
+	assert.Equal(t, expectedTime.Add(time.Second), comments[1].CreatedAt)
+	assert.Equal(t, "README.md", comments[1].Path)
+	assert.True(t, comments[1].Outdated)
+
+	// verify that the comment list is cached
+	comments, err = ctx.ReviewComments()
+	require.NoError(t, err)
+
+	require.Len(t, comments, 2, "incorrect number of review comments")
+	assert.Equal(t, 2, commentsRule.Count, "cached review comments were not used")
+}
+
+func TestMergeableRetry(t *testing.T) {
+	rp := &ResponsePlayer{}
+	prRule := rp.AddRule(
+		ExactPathMatcher("/repos/testorg/testrepo/pulls/123"),
+		"testdata/responses/pull_mergeable_retry.yml",
+	)
+
+	ctx := makeContext(t, rp, nil)
+
+	mergeable, err := ctx.Mergeable()
+	require.NoError(t, err)
+
+	if assert.NotNil(t, mergeable, "mergeable state was not loaded") {
+		assert.False(t, *mergeable)
+	}
+	assert.Equal(t, 2, prRule.Count, "incorrect number of http requests")
+
+	// verify that the mergeable state is cached
+	_, err = ctx.Mergeable()
+	require.NoError(t, err)
+	assert.Equal(t, 2, prRule.Count, "cached mergeable state was not used")
+}
+
 func TestNoReviews(t *testing.T) {
 	rp := &ResponsePlayer{}
 	dataRule := rp.AddRule(
@@ -409,7 +925,7 @@ func makeContext(t *testing.T, rp *ResponsePlayer, pr *github.PullRequest) Conte
 		pr = defaultTestPR()
 	}
 
-	prctx, err := NewGitHubContext(ctx, mbrCtx, client, v4client, Locator{
+	prctx, err := NewGitHubContext(ctx, mbrCtx, nil, client, v4client, Locator{
 		Owner:  pr.GetBase().GetRepo().GetOwner().GetLogin(),
 		Repo:   pr.GetBase().GetRepo().GetName(),
 		Number: pr.GetNumber(),
@@ -420,6 +936,26 @@ func makeContext(t *testing.T, rp *ResponsePlayer, pr *github.PullRequest) Conte
 	return prctx
 }
 
+func makePinnedContext(t *testing.T, rp *ResponsePlayer, sha string) Context {
+	ctx := context.Background()
+	client := github.NewClient(&http.Client{Transport: rp})
+	v4client := githubv4.NewClient(&http.Client{Transport: rp})
+
+	base, _ := url.Parse("http://github.localhost/")
+	client.BaseURL = base
+
+	prctx, err := NewGitHubContext(ctx, NewGitHubMembershipContext(ctx, client), nil, client, v4client, Locator{
+		Owner:  "testorg",
+		Repo:   "testrepo",
+		Number: 123,
+		Value:  defaultTestPR(),
+		SHA:    sha,
+	})
+	require.NoError(t, err, "failed to create github context")
+
+	return prctx
+}
+
 func defaultTestPR() *github.PullRequest {
 	return &github.PullRequest{
 		Number: github.Int(123),
@@ -12,9 +12,16 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Package pulltest provides a configurable implementation of pull.Context for
+// use in tests. Fields may be set directly or with the builder methods.
 package pulltest
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/palantir/policy-bot/pull"
 )
 
@@ -23,15 +30,49 @@ type Context struct {
 	RepoValue   string
 	NumberValue int
 
-	AuthorValue  string
-	HeadSHAValue string
+	AuthorValue    string
+	CreatedAtValue time.Time
+	HeadSHAValue   string
+	TitleValue     string
+	BodyValue      string
+
+	AssigneesValue []string
+	AssigneesError error
+
+	RequestedReviewersValue *pull.ReviewRequests
+	RequestedReviewersError error
+
+	ReviewRequestHistoryValue *pull.ReviewRequests
+	ReviewRequestHistoryError error
 
 	BranchBaseName string
 	BranchHeadName string
 
+	DefaultBranchValue string
+	DefaultBranchError error
+
+	MergeMethodsValue *pull.MergeMethods
+	MergeMethodsError error
+
+	// LabelEventsValue maps label names to the event that applied them
+	LabelEventsValue map[string]*pull.LabelEvent
+	LabelEventError  error
+
+	BehindByValue int
+	BehindByError error
+
+	MergeableValue *bool
+	MergeableError error
+
 	ChangedFilesValue []*pull.File
 	ChangedFilesError error
 
+	GitAttributesValue string
+	GitAttributesError error
+
+	PullRequestTemplateValue string
+	PullRequestTemplateError error
+
 	CommitsValue []*pull.Commit
 	CommitsError error
 
@@ -41,14 +82,68 @@ type Context struct {
 	ReviewsValue []*pull.Review
 	ReviewsError error
 
+	CommentedReviewsValue []*pull.Review
+	CommentedReviewsError error
+
+	ReviewDismissalsValue []*pull.ReviewDismissal
+	ReviewDismissalsError error
+
+	ForcePushesValue []*pull.ForcePush
+	ForcePushesError error
+
+	ReviewCommentsValue []*pull.ReviewComment
+	ReviewCommentsError error
+
+	// TeamMemberships maps users to the teams they are direct members of.
+	// Users are also members of the ancestors of these teams in
+	// TeamParentsValue.
 	TeamMemberships     map[string][]string
 	TeamMembershipError error
 
+	// TeamParentsValue maps teams to their parent team. Top-level teams map to
+	// an empty string. Only teams listed here are returned by Teams.
+	TeamParentsValue map[string]string
+	TeamParentsError error
+
 	OrgMemberships     map[string][]string
 	OrgMembershipError error
 
 	CollaboratorMemberships     map[string][]string
 	CollaboratorMembershipError error
+
+	StatusesValue map[string]string
+	StatusesError error
+
+	WorkflowRunsValue []*pull.WorkflowRun
+	WorkflowRunsError error
+
+	UserCreatedAtValues map[string]time.Time
+	UserCreatedAtError  error
+
+	MergedPullCountValues map[string]int
+	MergedPullCountError  error
+
+	// IssuesValue contains issues keyed by "owner/repo#number"
+	IssuesValue map[string]*pull.Issue
+	IssueError  error
+
+	// LastAuthorsValue maps file paths to the author of their last commit
+	LastAuthorsValue map[string]string
+	LastAuthorError  error
+
+	// CompareFilesValue maps "base...head" to the files changed between the
+	// two commits
+	CompareFilesValue map[string][]*pull.File
+	CompareFilesError error
+
+	RecentApprovalsValue []*pull.PullApprovals
+	RecentApprovalsError error
+
+	ExternalChecksPassed map[string][]string
+	ExternalCheckError   error
+
+	ApproverListsValue map[string][]string
+	ApproverListError  error
 }
 
 func (c *Context) RepositoryOwner() string {
@@ -76,18 +171,70 @@ func (c *Context) Author() string {
 	return c.AuthorValue
 }
 
+func (c *Context) CreatedAt() time.Time {
+	return c.CreatedAtValue
+}
+
 func (c *Context) HeadSHA() string {
 	return c.HeadSHAValue
 }
 
+func (c *Context) Title() string {
+	return c.TitleValue
+}
+
+func (c *Context) Body() string {
+	return c.BodyValue
+}
+
+func (c *Context) Assignees() ([]string, error) {
+	return c.AssigneesValue, c.AssigneesError
+}
+
+func (c *Context) RequestedReviewers() (*pull.ReviewRequests, error) {
+	return c.RequestedReviewersValue, c.RequestedReviewersError
+}
+
+func (c *Context) ReviewRequestHistory() (*pull.ReviewRequests, error) {
+	return c.ReviewRequestHistoryValue, c.ReviewRequestHistoryError
+}
+
 func (c *Context) Branches() (base string, head string) {
 	return c.BranchBaseName, c.BranchHeadName
 }
 
+func (c *Context) DefaultBranch() (string, error) {
+	return c.DefaultBranchValue, c.DefaultBranchError
+}
+
+func (c *Context) MergeMethods() (*pull.MergeMethods, error) {
+	return c.MergeMethodsValue, c.MergeMethodsError
+}
+
+func (c *Context) LabeledBy(label string) (*pull.LabelEvent, error) {
+	return c.LabelEventsValue[label], c.LabelEventError
+}
+
+func (c *Context) BehindBy() (int, error) {
+	return c.BehindByValue, c.BehindByError
+}
+
+func (c *Context) Mergeable() (*bool, error) {
+	return c.MergeableValue, c.MergeableError
+}
+
 func (c *Context) ChangedFiles() ([]*pull.File, error) {
 	return c.ChangedFilesValue, c.ChangedFilesError
 }
 
+func (c *Context) GitAttributes() (string, error) {
+	return c.GitAttributesValue, c.GitAttributesError
+}
+
+func (c *Context) PullRequestTemplate() (string, error) {
+	return c.PullRequestTemplateValue, c.PullRequestTemplateError
+}
+
 func (c *Context) Commits() ([]*pull.Commit, error) {
 	return c.CommitsValue, c.CommitsError
 }
@@ -98,13 +245,45 @@ func (c *Context) IsTeamMember(team, user string) (bool, error) {
 	}
 
 	for _, t := range c.TeamMemberships[user] {
-		if t == team {
-			return true, nil
+		for depth := 0; t != "" && depth <= len(c.TeamParentsValue); depth++ {
+			if t == team {
+				return true, nil
+			}
+			t = c.TeamParentsValue[t]
 		}
 	}
 	return false, nil
 }
 
+func (c *Context) Teams(org string) ([]string, error) {
+	if c.TeamParentsError != nil {
+		return nil, c.TeamParentsError
+	}
+
+	seen := make(map[string]bool)
+	for child, parent := range c.TeamParentsValue {
+		for _, t := range []string{child, parent} {
+			if strings.HasPrefix(t, org+"/") {
+				seen[t] = true
+			}
+		}
+	}
+
+	var teams []string
+	for t := range seen {
+		teams = append(teams, t)
+	}
+	sort.Strings(teams)
+	return teams, nil
+}
+
+func (c *Context) TeamParent(team string) (string, error) {
+	if c.TeamParentsError != nil {
+		return "", c.TeamParentsError
+	}
+	return c.TeamParentsValue[team], nil
+}
+
 func (c *Context) IsOrgMember(org, user string) (bool, error) {
 	if c.OrgMembershipError != nil {
 		return false, c.OrgMembershipError
@@ -139,5 +318,118 @@ func (c *Context) Reviews() ([]*pull.Review, error) {
 	return c.ReviewsValue, c.ReviewsError
 }
 
+func (c *Context) CommentedReviews() ([]*pull.Review, error) {
+	return c.CommentedReviewsValue, c.CommentedReviewsError
+}
+
+func (c *Context) ReviewDismissals() ([]*pull.ReviewDismissal, error) {
+	return c.ReviewDismissalsValue, c.ReviewDismissalsError
+}
+
+func (c *Context) ForcePushes() ([]*pull.ForcePush, error) {
+	return c.ForcePushesValue, c.ForcePushesError
+}
+
+func (c *Context) ReviewComments() ([]*pull.ReviewComment, error) {
+	return c.ReviewCommentsValue, c.ReviewCommentsError
+}
+
+func (c *Context) ExternalCheck(name, user string) (bool, error) {
+	if c.ExternalCheckError != nil {
+		return false, c.ExternalCheckError
+	}
+
+	for _, n := range c.ExternalChecksPassed[user] {
+		if n == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *Context) ApproverList(name string) ([]string, error) {
+	return c.ApproverListsValue[name], c.ApproverListError
+}
+
+func (c *Context) Statuses() (map[string]string, error) {
+	return c.StatusesValue, c.StatusesError
+}
+
+func (c *Context) WorkflowRuns() ([]*pull.WorkflowRun, error) {
+	return c.WorkflowRunsValue, c.WorkflowRunsError
+}
+
+func (c *Context) Issue(owner, repo string, number int) (*pull.Issue, error) {
+	if c.IssueError != nil {
+		return nil, c.IssueError
+	}
+	return c.IssuesValue[fmt.Sprintf("%s/%s#%d", owner, repo, number)], nil
+}
+
+func (c *Context) UserCreatedAt(user string) (time.Time, error) {
+	return c.UserCreatedAtValues[user], c.UserCreatedAtError
+}
+
+func (c *Context) MergedPullCount(user string) (int, error) {
+	return c.MergedPullCountValues[user], c.MergedPullCountError
+}
+
+func (c *Context) LastAuthor(path string) (string, error) {
+	return c.LastAuthorsValue[path], c.LastAuthorError
+}
+
+func (c *Context) CompareFiles(base, head string) ([]*pull.File, error) {
+	return c.CompareFilesValue[base+"..."+head], c.CompareFilesError
+}
+
+func (c *Context) RecentApprovals(lookback time.Duration) ([]*pull.PullApprovals, error) {
+	return c.RecentApprovalsValue, c.RecentApprovalsError
+}
+
+// AddTeamMember makes user a member of team, specified as "org-name/team-name".
+func (c *Context) AddTeamMember(team, user string) *Context {
+	if c.TeamMemberships == nil {
+		c.TeamMemberships = make(map[string][]string)
+	}
+	c.TeamMemberships[user] = append(c.TeamMemberships[user], team)
+	return c
+}
+
+// AddOrgMember makes user a member of org.
+func (c *Context) AddOrgMember(org, user string) *Context {
+	if c.OrgMemberships == nil {
+		c.OrgMemberships = make(map[string][]string)
+	}
+	c.OrgMemberships[user] = append(c.OrgMemberships[user], org)
+	return c
+}
+
+// AddCollaborator gives user the permission perm on the repository.
+func (c *Context) AddCollaborator(user, perm string) *Context {
+	if c.CollaboratorMemberships == nil {
+		c.CollaboratorMemberships = make(map[string][]string)
+	}
+	c.CollaboratorMemberships[user] = append(c.CollaboratorMemberships[user], perm)
+	return c
+}
+
+// AddCommits appends commits to the pull request.
+func (c *Context) AddCommits(commits ...*pull.Commit) *Context {
+	c.CommitsValue = append(c.CommitsValue, commits...)
+	return c
+}
+
+// AddComments appends comments to the pull request.
+func (c *Context) AddComments(comments ...*pull.Comment) *Context {
+	c.CommentsValue = append(c.CommentsValue, comments...)
+	return c
+}
+
+// AddReviews appends reviews to the pull request.
+func (c *Context) AddReviews(reviews ...*pull.Review) *Context {
+	c.ReviewsValue = append(c.ReviewsValue, reviews...)
+	return c
+}
+
 // assert that the test object implements the full interface
 var _ pull.Context = &Context{}
@@ -58,6 +58,10 @@ func ParseConfig(bytes []byte) (*Config, error) {
 		return nil, errors.Wrapf(err, "failed unmarshalling yaml")
 	}
 
+	if v, ok := os.LookupEnv("POLICYBOT_OPTIONS_STATUS_CHECK_CONTEXT"); ok {
+		c.Options.StatusCheckContext = v
+	}
+
 	c.Options.FillDefaults()
 	c.Github.SetValuesFromEnv("")
 
@@ -0,0 +1,154 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/alexedwards/scs"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"goji.io/pat"
+
+	"github.com/palantir/policy-bot/pull"
+)
+
+// Names of the caches managed by the admin API
+const (
+	CacheApproverLists    = "approver_lists"
+	CacheMergedPullCounts = "merged_pull_counts"
+)
+
+// AdminCaches lists and clears the caches shared by all evaluations. It
+// requires a logged in user who is one of the configured admin users. Every
+// request is logged with the audit key.
+type AdminCaches struct {
+	Base
+	Sessions *scs.Manager
+}
+
+type cacheStatsJSON struct {
+	Entries int      `json:"entries"`
+	Keys    []string `json:"keys"`
+}
+
+type clearCacheResponse struct {
+	Cache   string `json:"cache"`
+	Cleared int    `json:"cleared"`
+}
+
+func (h *AdminCaches) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	logger := zerolog.Ctx(ctx)
+
+	user, err := sessionUser(w, r, h.Sessions)
+	if err != nil {
+		return err
+	}
+	if user == "" {
+		return nil
+	}
+	if !h.isAdmin(user) {
+		logger.Warn().Str(LogKeyAudit, "cache_denied").Msgf("Rejected cache request from non-admin user %s", user)
+		http.Error(w, "admin access required", http.StatusForbidden)
+		return nil
+	}
+
+	if r.Method == http.MethodGet {
+		logger.Info().Str(LogKeyAudit, "cache_inspect").Msgf("Listing caches at the request of %s", user)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		return json.NewEncoder(w).Encode(h.cacheStats())
+	}
+
+	// session cookies are sent with cross-site requests, so reject requests
+	// that browsers identify as coming from another origin
+	if !h.isSameOrigin(r) {
+		http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+		return nil
+	}
+
+	name := pat.Param(r, "cache")
+	match, err := cacheMatcher(name, r.URL.Query().Get("key"), r.URL.Query().Get("repo"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	var cleared int
+	switch name {
+	case CacheApproverLists:
+		cleared = h.ApproverLists.Clear(match)
+	case CacheMergedPullCounts:
+		cleared = pull.ClearMergedPullCounts(match)
+	}
+
+	logger.Warn().Str(LogKeyAudit, "cache_clear").Msgf("Cleared %d entries from cache %q at the request of %s (%s)", cleared, name, user, r.URL.RawQuery)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(clearCacheResponse{Cache: name, Cleared: cleared})
+}
+
+func (h *AdminCaches) isAdmin(user string) bool {
+	for _, admin := range h.PullOpts.AdminUsers {
+		if strings.EqualFold(admin, user) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *AdminCaches) cacheStats() map[string]cacheStatsJSON {
+	approverLists := h.ApproverLists.Keys()
+	mergedPullCounts := pull.MergedPullCountKeys()
+
+	return map[string]cacheStatsJSON{
+		CacheApproverLists:    {Entries: len(approverLists), Keys: approverLists},
+		CacheMergedPullCounts: {Entries: len(mergedPullCounts), Keys: mergedPullCounts},
+	}
+}
+
+// cacheMatcher returns a function that matches the entries of the named cache
+// with the key, or with keys for the repository, or all entries if both are
+// empty. Only merged pull request counts are associated with repositories.
+func cacheMatcher(name, key, repo string) (func(string) bool, error) {
+	switch name {
+	case CacheApproverLists, CacheMergedPullCounts:
+	default:
+		return nil, errors.Errorf("unknown cache %q", name)
+	}
+
+	switch {
+	case key != "" && repo != "":
+		return nil, errors.New("only one of key or repo may be set")
+	case key != "":
+		return func(k string) bool { return k == key }, nil
+	case repo != "":
+		if name != CacheMergedPullCounts {
+			return nil, errors.Errorf("cache %q is not associated with repositories", name)
+		}
+		if strings.Count(repo, "/") != 1 {
+			return nil, errors.Errorf("invalid repository %q, expected owner/repo", repo)
+		}
+		prefix := fmt.Sprintf("%s/", repo)
+		return func(k string) bool { return strings.HasPrefix(k, prefix) }, nil
+	}
+	return func(string) bool { return true }, nil
+}
@@ -0,0 +1,231 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs"
+	"github.com/palantir/go-baseapp/baseapp"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"goji.io"
+	"goji.io/pat"
+)
+
+func TestAdminCaches(t *testing.T) {
+	sessions := scs.NewCookieManager("0123456789abcdef0123456789abcdef")
+
+	h := &AdminCaches{
+		Base: Base{
+			PullOpts:      &PullEvaluationOptions{AdminUsers: []string{"Admin"}},
+			BaseConfig:    &baseapp.HTTPConfig{PublicURL: "https://policy-bot.example.com"},
+			ApproverLists: NewApproverLists(http.DefaultClient, nil),
+		},
+		Sessions: sessions,
+	}
+
+	mux := goji.NewMux()
+	mux.HandleFunc(pat.Get("/api/admin/caches"), func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, h.ServeHTTP(w, r))
+	})
+	mux.HandleFunc(pat.Delete("/api/admin/caches/:cache"), func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, h.ServeHTTP(w, r))
+	})
+
+	// do makes a request as the user, or without a session if the user is
+	// empty, and returns the response and the logs of the request
+	do := func(method, target, user string, header http.Header) (*httptest.ResponseRecorder, string) {
+		r := httptest.NewRequest(method, target, nil)
+		for k, v := range header {
+			r.Header[k] = v
+		}
+
+		if user != "" {
+			login := httptest.NewRecorder()
+			require.NoError(t, sessions.Load(r).PutString(login, SessionKeyUsername, user))
+			for _, c := range login.Result().Cookies() {
+				r.AddCookie(c)
+			}
+		}
+
+		var logs bytes.Buffer
+		logger := zerolog.New(&logs)
+		r = r.WithContext(logger.WithContext(r.Context()))
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w, logs.String()
+	}
+
+	resetLists := func() {
+		h.ApproverLists.mu.Lock()
+		defer h.ApproverLists.mu.Unlock()
+		h.ApproverLists.cache = map[string]*cachedApproverList{
+			"oncall":   {users: []string{"mhaypenny"}, loadedAt: time.Now()},
+			"security": {users: []string{"bkeyes"}, loadedAt: time.Now()},
+		}
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		w, _ := do(http.MethodGet, "/api/admin/caches", "", nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("notAdmin", func(t *testing.T) {
+		w, logs := do(http.MethodGet, "/api/admin/caches", "mhaypenny", nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, logs, `"audit":"cache_denied"`)
+	})
+
+	t.Run("inspect", func(t *testing.T) {
+		resetLists()
+
+		w, logs := do(http.MethodGet, "/api/admin/caches", "admin", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, logs, `"audit":"cache_inspect"`)
+
+		var stats map[string]cacheStatsJSON
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+		assert.Equal(t, cacheStatsJSON{Entries: 2, Keys: []string{"oncall", "security"}}, stats[CacheApproverLists])
+	})
+
+	t.Run("clearKey", func(t *testing.T) {
+		resetLists()
+
+		w, logs := do(http.MethodDelete, "/api/admin/caches/approver_lists?key=oncall", "admin", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, logs, `"audit":"cache_clear"`)
+		assert.Contains(t, logs, "at the request of admin")
+
+		var res clearCacheResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Equal(t, clearCacheResponse{Cache: CacheApproverLists, Cleared: 1}, res)
+		assert.Equal(t, []string{"security"}, h.ApproverLists.Keys())
+	})
+
+	t.Run("sameOrigin", func(t *testing.T) {
+		resetLists()
+
+		w, _ := do(http.MethodDelete, "/api/admin/caches/approver_lists", "admin", http.Header{
+			"Origin": {"https://policy-bot.example.com"},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, h.ApproverLists.Keys())
+	})
+
+	t.Run("crossOrigin", func(t *testing.T) {
+		resetLists()
+
+		w, logs := do(http.MethodDelete, "/api/admin/caches/approver_lists", "admin", http.Header{
+			"Origin": {"https://attacker.example.com"},
+		})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.NotContains(t, logs, `"audit":"cache_clear"`)
+		assert.Len(t, h.ApproverLists.Keys(), 2, "cross-origin request cleared the cache")
+	})
+
+	t.Run("invalidRequest", func(t *testing.T) {
+		resetLists()
+
+		w, _ := do(http.MethodDelete, "/api/admin/caches/approver_lists?repo=testorg/testrepo", "admin", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Len(t, h.ApproverLists.Keys(), 2, "invalid request cleared the cache")
+	})
+}
+
+func TestIsAdmin(t *testing.T) {
+	h := &AdminCaches{
+		Base: Base{PullOpts: &PullEvaluationOptions{AdminUsers: []string{"Admin", "ops"}}},
+	}
+
+	assert.True(t, h.isAdmin("Admin"))
+	assert.True(t, h.isAdmin("admin"), "logins are not case-insensitive")
+	assert.True(t, h.isAdmin("OPS"), "logins are not case-insensitive")
+	assert.False(t, h.isAdmin("mhaypenny"))
+	assert.False(t, h.isAdmin(""))
+}
+
+func TestCacheMatcher(t *testing.T) {
+	tests := map[string]struct {
+		Cache   string
+		Key     string
+		Repo    string
+		Matches []string
+		Misses  []string
+		Err     bool
+	}{
+		"all": {
+			Cache:   CacheApproverLists,
+			Matches: []string{"oncall", "security"},
+		},
+		"key": {
+			Cache:   CacheApproverLists,
+			Key:     "oncall",
+			Matches: []string{"oncall"},
+			Misses:  []string{"security", "oncall2"},
+		},
+		"repo": {
+			Cache:   CacheMergedPullCounts,
+			Repo:    "testorg/testrepo",
+			Matches: []string{"testorg/testrepo/mhaypenny"},
+			Misses:  []string{"testorg/testrepo2/mhaypenny", "testorg/other/mhaypenny"},
+		},
+		"repoForApproverLists": {
+			Cache: CacheApproverLists,
+			Repo:  "testorg/testrepo",
+			Err:   true,
+		},
+		"invalidRepo": {
+			Cache: CacheMergedPullCounts,
+			Repo:  "testorg",
+			Err:   true,
+		},
+		"keyAndRepo": {
+			Cache: CacheMergedPullCounts,
+			Key:   "testorg/testrepo/mhaypenny",
+			Repo:  "testorg/testrepo",
+			Err:   true,
+		},
+		"unknownCache": {
+			Cache: "teams",
+			Err:   true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			match, err := cacheMatcher(test.Cache, test.Key, test.Repo)
+			if test.Err {
+				assert.Error(t, err, "invalid matcher did not return an error")
+				return
+			}
+			require.NoError(t, err)
+
+			for _, k := range test.Matches {
+				assert.True(t, match(k), "key %q did not match", k)
+			}
+			for _, k := range test.Misses {
+				assert.False(t, match(k), "key %q matched", k)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/pull"
+)
+
+const (
+	DefaultApprovalWebhookTimeout = 10 * time.Second
+
+	// ApprovalWebhookSignatureHeader contains the hex-encoded HMAC-SHA256 of
+	// the request body, prefixed with "sha256=", if a secret is configured
+	ApprovalWebhookSignatureHeader = "X-Policy-Bot-Signature"
+)
+
+// ApprovalWebhookConfig defines an HTTP endpoint that receives a request when
+// a pull request becomes approved.
+type ApprovalWebhookConfig struct {
+	URL     string        `yaml:"url"`
+	Secret  string        `yaml:"secret"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type approvalWebhookPayload struct {
+	Owner  string      `json:"owner"`
+	Repo   string      `json:"repo"`
+	Number int         `json:"number"`
+	SHA    string      `json:"sha"`
+	Result *resultJSON `json:"result"`
+}
+
+type resultJSON struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Status      string        `json:"status"`
+	Reason      string        `json:"reason,omitempty"`
+	Overdue     bool          `json:"overdue,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	Children    []*resultJSON `json:"children,omitempty"`
+}
+
+func newResultJSON(r *common.Result) *resultJSON {
+	rj := &resultJSON{
+		Name:        r.Name,
+		Description: r.Description,
+		Status:      r.Status.String(),
+		Reason:      string(r.Reason),
+		Overdue:     r.Overdue,
+	}
+	if r.Error != nil {
+		rj.Error = r.Error.Error()
+	}
+	for _, c := range r.Children {
+		rj.Children = append(rj.Children, newResultJSON(c))
+	}
+	return rj
+}
+
+// hasStatus returns true if the head commit of the pull request already has
+// a status with the given context and state.
+func (b *Base) hasStatus(ctx context.Context, prctx pull.Context, client *github.Client, statusContext, state string) (bool, error) {
+	statuses, err := b.commitStatuses(ctx, prctx, client)
+	if err != nil {
+		return false, err
+	}
+	return statuses[statusContext] == state, nil
+}
+
+// SendApprovalWebhook sends the result of an evaluation that approved the
+// pull request to the configured approval webhook.
+func (b *Base) SendApprovalWebhook(ctx context.Context, prctx pull.Context, result *common.Result) error {
+	logger := zerolog.Ctx(ctx)
+	webhook := b.PullOpts.ApprovalWebhook
+
+	body, err := json.Marshal(approvalWebhookPayload{
+		Owner:  prctx.RepositoryOwner(),
+		Repo:   prctx.RepositoryName(),
+		Number: prctx.Number(),
+		SHA:    prctx.HeadSHA(),
+		Result: newResultJSON(result),
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal approval webhook payload")
+	}
+
+	timeout := webhook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultApprovalWebhookTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create approval webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if webhook.Secret != "" {
+		req.Header.Set(ApprovalWebhookSignatureHeader, "sha256="+signPayload(webhook.Secret, body))
+	}
+
+	logger.Debug().Msgf("Sending approval webhook to %s", webhook.URL)
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "failed to send approval webhook")
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode >= 300 {
+		return errors.Errorf("approval webhook returned status %d", res.StatusCode)
+	}
+	return nil
+}
+
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,221 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	DefaultApproverListTTL     = 5 * time.Minute
+	DefaultApproverListTimeout = 5 * time.Second
+
+	approverListMaxBody = 1 << 20
+)
+
+// ApproverListConfig defines an HTTP endpoint that returns a JSON array of
+// the logins of users who may approve rules that reference the list.
+type ApproverListConfig struct {
+	URL string `yaml:"url"`
+
+	// Headers are added to each request, for example to set Authorization.
+	Headers map[string]string `yaml:"headers"`
+
+	// TTL is how long a list is used before it is loaded again.
+	TTL time.Duration `yaml:"ttl"`
+
+	// Timeout is the maximum duration of each request.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// ApproverLists loads approver lists from HTTP endpoints and caches them for
+// all evaluations. If a list cannot be loaded, the last list that was loaded
+// successfully is used. Concurrent requests for a list that must be loaded
+// share a single request to the endpoint. It is safe for concurrent use.
+type ApproverLists struct {
+	client *http.Client
+	lists  map[string]ApproverListConfig
+
+	mu    sync.Mutex
+	cache map[string]*cachedApproverList
+	loads map[string]*approverListLoad
+}
+
+type cachedApproverList struct {
+	users    []string
+	loadedAt time.Time
+}
+
+// approverListLoad is a request for a list that is in progress. The users and
+// error are set before done is closed.
+type approverListLoad struct {
+	done  chan struct{}
+	users []string
+	err   error
+}
+
+func NewApproverLists(client *http.Client, lists map[string]ApproverListConfig) *ApproverLists {
+	return &ApproverLists{
+		client: client,
+		lists:  lists,
+		cache:  make(map[string]*cachedApproverList),
+		loads:  make(map[string]*approverListLoad),
+	}
+}
+
+// Get returns the users in the named list.
+func (l *ApproverLists) Get(ctx context.Context, name string) ([]string, error) {
+	logger := zerolog.Ctx(ctx)
+
+	list, ok := l.lists[name]
+	if !ok {
+		return nil, errors.Errorf("approver list %q is not configured", name)
+	}
+
+	ttl := list.TTL
+	if ttl <= 0 {
+		ttl = DefaultApproverListTTL
+	}
+
+	l.mu.Lock()
+	cached := l.cache[name]
+	if cached != nil && time.Since(cached.loadedAt) < ttl {
+		l.mu.Unlock()
+		return cached.users, nil
+	}
+
+	load, loading := l.loads[name]
+	if !loading {
+		load = &approverListLoad{done: make(chan struct{})}
+		l.loads[name] = load
+	}
+	l.mu.Unlock()
+
+	if !loading {
+		// load without the request context, so that canceling the request
+		// does not fail other callers waiting for the same list
+		go l.finishLoad(logger.WithContext(context.Background()), name, list, load)
+	}
+
+	var users []string
+	var err error
+	select {
+	case <-load.done:
+		users, err = load.users, load.err
+	case <-ctx.Done():
+		err = errors.Wrap(ctx.Err(), "canceled while waiting for approver list")
+	}
+
+	if err != nil {
+		if cached == nil {
+			return nil, errors.WithMessage(err, "failed to load approver list "+name)
+		}
+		logger.Warn().Err(err).Msgf("Failed to load approver list %q, using list loaded at %s", name, cached.loadedAt.Format(time.RFC3339))
+		return cached.users, nil
+	}
+	return users, nil
+}
+
+// Keys returns the names of the lists in the cache, including lists that are
+// older than their TTL and will be loaded again before they are used.
+func (l *ApproverLists) Keys() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	keys := make([]string, 0, len(l.cache))
+	for name := range l.cache {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Clear removes the cached lists with names that match and returns the
+// number of lists removed. Removed lists are loaded again when they are next
+// used, without a fallback if the load fails.
+func (l *ApproverLists) Clear(match func(name string) bool) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cleared := 0
+	for name := range l.cache {
+		if match(name) {
+			delete(l.cache, name)
+			cleared++
+		}
+	}
+	return cleared
+}
+
+// finishLoad loads the list, caches it if successful, and notifies the
+// callers waiting for the load.
+func (l *ApproverLists) finishLoad(ctx context.Context, name string, list ApproverListConfig, load *approverListLoad) {
+	users, err := l.load(ctx, list)
+
+	l.mu.Lock()
+	if err == nil {
+		l.cache[name] = &cachedApproverList{users: users, loadedAt: time.Now()}
+	}
+	delete(l.loads, name)
+	l.mu.Unlock()
+
+	load.users, load.err = users, err
+	close(load.done)
+}
+
+func (l *ApproverLists) load(ctx context.Context, list ApproverListConfig) ([]string, error) {
+	timeout := list.Timeout
+	if timeout <= 0 {
+		timeout = DefaultApproverListTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodGet, list.URL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create approver list request")
+	}
+	for k, v := range list.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := l.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "approver list request failed")
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("approver list returned status %d", res.StatusCode)
+	}
+
+	var users []string
+	if err := json.NewDecoder(io.LimitReader(res.Body, approverListMaxBody)).Decode(&users); err != nil {
+		return nil, errors.Wrap(err, "failed to decode approver list")
+	}
+	return users, nil
+}
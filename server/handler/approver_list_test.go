@@ -0,0 +1,230 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApproverLists(t *testing.T) {
+	ctx := context.Background()
+
+	var requests int32
+	var failing atomic.Value
+	failing.Store(false)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		if failing.Load().(bool) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		assert.Equal(t, "token", r.Header.Get("Authorization"))
+		fmt.Fprintf(w, `["user%d"]`, n)
+	}))
+	defer srv.Close()
+
+	newLists := func() *ApproverLists {
+		atomic.StoreInt32(&requests, 0)
+		failing.Store(false)
+		return NewApproverLists(srv.Client(), map[string]ApproverListConfig{
+			"oncall": {
+				URL:     srv.URL,
+				Headers: map[string]string{"Authorization": "token"},
+				TTL:     time.Minute,
+			},
+		})
+	}
+
+	// expire makes the cached list older than its TTL
+	expire := func(l *ApproverLists, name string) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.cache[name].loadedAt = time.Now().Add(-time.Hour)
+	}
+
+	t.Run("cachedWithinTTL", func(t *testing.T) {
+		l := newLists()
+
+		users, err := l.Get(ctx, "oncall")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user1"}, users)
+
+		users, err = l.Get(ctx, "oncall")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user1"}, users)
+		assert.EqualValues(t, 1, atomic.LoadInt32(&requests), "cached list was not used")
+		assert.Equal(t, []string{"oncall"}, l.Keys())
+	})
+
+	t.Run("reloadedAfterTTL", func(t *testing.T) {
+		l := newLists()
+
+		_, err := l.Get(ctx, "oncall")
+		require.NoError(t, err)
+		expire(l, "oncall")
+
+		users, err := l.Get(ctx, "oncall")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user2"}, users, "expired list was used")
+	})
+
+	t.Run("lastGoodFallback", func(t *testing.T) {
+		l := newLists()
+
+		_, err := l.Get(ctx, "oncall")
+		require.NoError(t, err)
+		expire(l, "oncall")
+		failing.Store(true)
+
+		users, err := l.Get(ctx, "oncall")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user1"}, users, "last good list was not used")
+
+		// the failed load does not refresh the list, so the next use tries again
+		users, err = l.Get(ctx, "oncall")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user1"}, users)
+		assert.EqualValues(t, 3, atomic.LoadInt32(&requests), "failed load was cached")
+
+		failing.Store(false)
+		users, err = l.Get(ctx, "oncall")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user4"}, users, "recovered list was not loaded")
+	})
+
+	t.Run("noFallback", func(t *testing.T) {
+		l := newLists()
+		failing.Store(true)
+
+		_, err := l.Get(ctx, "oncall")
+		assert.Error(t, err, "failed load without a previous list did not return an error")
+	})
+
+	t.Run("cleared", func(t *testing.T) {
+		l := newLists()
+
+		_, err := l.Get(ctx, "oncall")
+		require.NoError(t, err)
+		assert.Equal(t, 1, l.Clear(func(name string) bool { return name == "oncall" }))
+		assert.Empty(t, l.Keys())
+
+		// cleared lists have no fallback
+		failing.Store(true)
+		_, err = l.Get(ctx, "oncall")
+		assert.Error(t, err, "cleared list was used as a fallback")
+	})
+
+	t.Run("notConfigured", func(t *testing.T) {
+		_, err := newLists().Get(ctx, "missing")
+		assert.Error(t, err, "missing list did not return an error")
+	})
+}
+
+func TestApproverListsConcurrentLoad(t *testing.T) {
+	ctx := context.Background()
+
+	var requests int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		fmt.Fprint(w, `["mhaypenny"]`)
+	}))
+	defer srv.Close()
+
+	l := NewApproverLists(srv.Client(), map[string]ApproverListConfig{
+		"oncall": {URL: srv.URL},
+	})
+
+	const callers = 10
+
+	var wg sync.WaitGroup
+	results := make([][]string, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = l.Get(ctx, "oncall")
+		}(i)
+	}
+
+	// give the callers time to find the load in progress before finishing
+	// it; callers that start later use the cached list
+	for atomic.LoadInt32(&requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i := 0; i < callers; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, []string{"mhaypenny"}, results[i])
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests), "concurrent loads were not coalesced")
+}
+
+func TestApproverListsCanceledLoad(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		fmt.Fprint(w, `["mhaypenny"]`)
+	}))
+	defer srv.Close()
+
+	l := NewApproverLists(srv.Client(), map[string]ApproverListConfig{
+		"oncall": {URL: srv.URL},
+	})
+
+	// the first caller starts the load and gives up before it finishes
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := l.Get(canceledCtx, "oncall")
+		canceled <- err
+	}()
+	<-started
+	cancel()
+	assert.Error(t, <-canceled)
+
+	// a later caller waits for the same load, which is not canceled
+	done := make(chan struct{})
+	var users []string
+	var err error
+	go func() {
+		defer close(done)
+		users, err = l.Get(context.Background(), "oncall")
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	<-done
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mhaypenny"}, users)
+}
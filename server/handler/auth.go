@@ -0,0 +1,70 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/alexedwards/scs"
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+)
+
+// sessionUser returns the login of the user of the request's session. If no
+// user is logged in, it responds with an error and returns an empty string.
+func sessionUser(w http.ResponseWriter, r *http.Request, sessions *scs.Manager) (string, error) {
+	user, err := sessions.Load(r).GetString(SessionKeyUsername)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read sessions")
+	}
+	if user == "" {
+		http.Error(w, "authentication required", http.StatusUnauthorized)
+	}
+	return user, nil
+}
+
+// canRead returns true if the user can read the repository. It returns false
+// if the repository does not exist or the client cannot see it.
+func canRead(ctx context.Context, client *github.Client, owner, repo, user string) (bool, error) {
+	level, _, err := client.Repositories.GetPermissionLevel(ctx, owner, repo, user)
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "failed to get user permission level")
+	}
+	return level.GetPermission() != "none", nil
+}
+
+// isSameOrigin returns false if browsers identify the request as coming from
+// an origin other than the public URL of the server.
+func (b *Base) isSameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	o, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	p, err := url.Parse(b.BaseConfig.PublicURL)
+	if err != nil {
+		return false
+	}
+	return o.Scheme == p.Scheme && o.Host == p.Host
+}
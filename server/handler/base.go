@@ -17,6 +17,7 @@ package handler
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/google/go-github/github"
@@ -35,6 +36,10 @@ const (
 	DefaultStatusCheckContext = "policy-bot"
 	DefaultAppName            = "policy-bot"
 
+	// MaxStatusDescriptionLength is the maximum length of a commit status
+	// description accepted by GitHub
+	MaxStatusDescriptionLength = 140
+
 	LogKeyGitHubSHA = "github_sha"
 )
 
@@ -45,25 +50,85 @@ type Base struct {
 	PullOpts      *PullEvaluationOptions
 	ConfigFetcher *ConfigFetcher
 	BaseConfig    *baseapp.HTTPConfig
+	ApproverLists *ApproverLists
 }
 
 type PullEvaluationOptions struct {
 	AppName    string `yaml:"app_name"`
 	PolicyPath string `yaml:"policy_path"`
 
+	// PolicyPaths is an ordered list of locations to search for the policy
+	// file. The first path that exists in the repository is used. If empty,
+	// only PolicyPath is searched.
+	PolicyPaths []string `yaml:"policy_paths"`
+
+	// PostMissingPolicyStatus enables posting an error status when none of
+	// the policy paths exist. This is off by default so that the bot can be
+	// safely enabled on all repositories in an organization.
+	PostMissingPolicyStatus bool `yaml:"post_missing_policy_status"`
+
+	// DefaultPolicy is the location of a policy used for repositories that do
+	// not define their own policy. The repository must be in the same
+	// organization as the repository being evaluated.
+	DefaultPolicy *DefaultPolicyConfig `yaml:"default_policy"`
+
+	// PostPendingComment enables a comment on pull requests that lists the
+	// pending rules and who can approve them. The bot creates at most one
+	// comment per pull request and updates it on future evaluations.
+	PostPendingComment bool `yaml:"post_pending_comment"`
+
+	// ExternalChecks are HTTP endpoints that policies can require the pull
+	// request author to pass, keyed by the name used in policies.
+	ExternalChecks map[string]ExternalCheckConfig `yaml:"external_checks"`
+
+	// ApproverLists are HTTP endpoints that return users who may approve
+	// rules, keyed by the name used in policies.
+	ApproverLists map[string]ApproverListConfig `yaml:"approver_lists"`
+
+	// ApprovalWebhook is an HTTP endpoint that receives a request when a pull
+	// request becomes approved. It is not sent again unless the pull request
+	// stops being approved or a new commit is pushed and approved.
+	ApprovalWebhook *ApprovalWebhookConfig `yaml:"approval_webhook"`
+
+	// InstallationConfigs are partial policies, keyed by the organization or
+	// user that owns an installation, that are merged beneath the policy of
+	// each repository in the installation.
+	InstallationConfigs map[string]interface{} `yaml:"installation_configs"`
+
 	// StatusCheckContext will be used to create the status context. It will be used in the following
 	// pattern: <StatusCheckContext>: <Base Branch Name>
 	StatusCheckContext string `yaml:"status_check_context"`
 
+	// InstallationStatusCheckContexts replace StatusCheckContext for the
+	// installations owned by each organization or user in the map.
+	InstallationStatusCheckContexts map[string]string `yaml:"installation_status_check_contexts"`
+
+	// PreviousStatusCheckContexts are status contexts used before a change to
+	// the configured context. If the head commit already has a status with
+	// one of these contexts, it is updated along with the current context so
+	// that it does not remain in a stale state.
+	PreviousStatusCheckContexts []string `yaml:"previous_status_check_contexts"`
+
 	// PostInsecureStatusChecks enables the sending of a second status using just StatusCheckContext as the context,
 	// no templating. This is turned off by default. This is to support legacy workflows that depend on the original
 	// context behaviour, and will be removed in 2.0
 	PostInsecureStatusChecks bool `yaml:"post_insecure_status_checks"`
+
+	// AdminUsers are the GitHub logins of users who may inspect and clear
+	// the server caches with the admin API. If empty, the API is disabled.
+	AdminUsers []string `yaml:"admin_users"`
 }
 
 func (p *PullEvaluationOptions) FillDefaults() {
 	if p.PolicyPath == "" {
 		p.PolicyPath = DefaultPolicyPath
+		if len(p.PolicyPaths) > 0 {
+			p.PolicyPath = p.PolicyPaths[0]
+		}
+	}
+
+	if len(p.PolicyPaths) == 0 {
+		p.PolicyPaths = []string{p.PolicyPath}
 	}
 
 	if p.StatusCheckContext == "" {
@@ -75,20 +140,29 @@ func (p *PullEvaluationOptions) FillDefaults() {
 	}
 }
 
+// StatusCheckContextFor returns the status context used for repositories owned
+// by the given organization or user.
+func (p *PullEvaluationOptions) StatusCheckContextFor(owner string) string {
+	if c, ok := p.InstallationStatusCheckContexts[owner]; ok && c != "" {
+		return c
+	}
+	return p.StatusCheckContext
+}
+
 func (b *Base) PostStatus(ctx context.Context, prctx pull.Context, client *github.Client, state, message string) error {
 	owner := prctx.RepositoryOwner()
 	repo := prctx.RepositoryName()
 	sha := prctx.HeadSHA()
-	base, _ := prctx.Branches()
 
 	publicURL := strings.TrimSuffix(b.BaseConfig.PublicURL, "/")
 	detailsURL := fmt.Sprintf("%s/details/%s/%s/%d", publicURL, owner, repo, prctx.Number())
 
-	contextWithBranch := fmt.Sprintf("%s: %s", b.PullOpts.StatusCheckContext, base)
+	contextWithBranch := b.statusContext(prctx)
+	description := truncateStatusDescription(message)
 	status := &github.RepoStatus{
 		Context:     &contextWithBranch,
 		State:       &state,
-		Description: &message,
+		Description: &description,
 		TargetURL:   &detailsURL,
 	}
 
@@ -97,15 +171,75 @@ func (b *Base) PostStatus(ctx context.Context, prctx pull.Context, client *githu
 	}
 
 	if b.PullOpts.PostInsecureStatusChecks {
-		status.Context = &b.PullOpts.StatusCheckContext
+		insecureContext := b.PullOpts.StatusCheckContextFor(owner)
+		status.Context = &insecureContext
 		if err := b.postGitHubRepoStatus(ctx, client, owner, repo, sha, status); err != nil {
 			return err
 		}
 	}
 
+	if len(b.PullOpts.PreviousStatusCheckContexts) > 0 {
+		existing, err := b.commitStatuses(ctx, prctx, client)
+		if err != nil {
+			return err
+		}
+
+		base, _ := prctx.Branches()
+		for _, previous := range b.PullOpts.PreviousStatusCheckContexts {
+			for _, c := range []string{fmt.Sprintf("%s: %s", previous, base), previous} {
+				if _, ok := existing[c]; !ok || c == contextWithBranch {
+					continue
+				}
+
+				previousContext := c
+				status.Context = &previousContext
+				if err := b.postGitHubRepoStatus(ctx, client, owner, repo, sha, status); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
+// commitStatuses returns the latest state of each status context on the head
+// commit of the pull request.
+func (b *Base) commitStatuses(ctx context.Context, prctx pull.Context, client *github.Client) (map[string]string, error) {
+	statuses := make(map[string]string)
+
+	opt := &github.ListOptions{PerPage: 100}
+	for {
+		combined, res, err := client.Repositories.GetCombinedStatus(ctx, prctx.RepositoryOwner(), prctx.RepositoryName(), prctx.HeadSHA(), opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get commit statuses")
+		}
+		for _, s := range combined.Statuses {
+			statuses[s.GetContext()] = s.GetState()
+		}
+		if res.NextPage == 0 {
+			break
+		}
+		opt.Page = res.NextPage
+	}
+	return statuses, nil
+}
+
+// truncateStatusDescription shortens a description to the maximum length
+// accepted by GitHub for commit statuses without splitting characters.
+func truncateStatusDescription(description string) string {
+	runes := []rune(description)
+	if len(runes) <= MaxStatusDescriptionLength {
+		return description
+	}
+	return string(runes[:MaxStatusDescriptionLength-3]) + "..."
+}
+
+func (b *Base) statusContext(prctx pull.Context) string {
+	base, _ := prctx.Branches()
+	return fmt.Sprintf("%s: %s", b.PullOpts.StatusCheckContextFor(prctx.RepositoryOwner()), base)
+}
+
 func (b *Base) postGitHubRepoStatus(ctx context.Context, client *github.Client, owner, repo, ref string, status *github.RepoStatus) error {
 	logger := zerolog.Ctx(ctx)
 	logger.Info().Msgf("Setting %q status on %s to %s: %s", status.GetContext(), ref, status.GetState(), status.GetDescription())
@@ -122,7 +256,9 @@ func (b *Base) PreparePRContext(ctx context.Context, installationID int64, pr *g
 	return ctx, logger
 }
 
-func (b *Base) Evaluate(ctx context.Context, installationID int64, loc pull.Locator) error {
+// Evaluate evaluates the pull request for an event of the given type, unless
+// the policy disables evaluation for the event.
+func (b *Base) Evaluate(ctx context.Context, installationID int64, eventType string, loc pull.Locator) error {
 	client, err := b.NewInstallationClient(installationID)
 	if err != nil {
 		return err
@@ -134,7 +270,8 @@ func (b *Base) Evaluate(ctx context.Context, installationID int64, loc pull.Loca
 	}
 
 	mbrCtx := NewCrossOrgMembershipContext(ctx, client, loc.Owner, b.Installations, b.ClientCreator)
-	prctx, err := pull.NewGitHubContext(ctx, mbrCtx, client, v4client, loc)
+	extCtx := NewHTTPExternalContext(ctx, http.DefaultClient, b.PullOpts.ExternalChecks, b.ApproverLists)
+	prctx, err := pull.NewGitHubContext(ctx, mbrCtx, extCtx, client, v4client, loc)
 	if err != nil {
 		return err
 	}
@@ -144,29 +281,146 @@ func (b *Base) Evaluate(ctx context.Context, installationID int64, loc pull.Loca
 		return errors.WithMessage(err, fmt.Sprintf("failed to fetch policy: %s", fetchedConfig))
 	}
 
+	if b.ignoresEvent(ctx, fetchedConfig, eventType) {
+		return nil
+	}
+
 	return b.EvaluateFetchedConfig(ctx, prctx, client, fetchedConfig)
 }
 
+// EvaluateCommit evaluates the open pull requests in the repository that have
+// the commit as their head for an event of the given type. Events like
+// statuses are associated with commits instead of pull requests.
+func (b *Base) EvaluateCommit(ctx context.Context, installationID int64, eventType string, repo *github.Repository, sha string) error {
+	client, err := b.NewInstallationClient(installationID)
+	if err != nil {
+		return err
+	}
+
+	prs, err := openPullRequestsForCommit(ctx, client, repo.GetOwner().GetLogin(), repo.GetName(), sha)
+	if err != nil {
+		return err
+	}
+	return b.evaluatePullRequests(ctx, installationID, eventType, repo, sha, prs)
+}
+
+// evaluatePullRequests evaluates each pull request in the repository for an
+// event of the given type about the commit. Pull requests may be incomplete,
+// like those in the payloads of check events.
+func (b *Base) evaluatePullRequests(ctx context.Context, installationID int64, eventType string, repo *github.Repository, sha string, prs []*github.PullRequest) error {
+	failed := 0
+	for _, pr := range prs {
+		prCtx, logger := githubapp.PreparePRContext(ctx, installationID, repo, pr.GetNumber())
+		err := b.Evaluate(prCtx, installationID, eventType, pull.Locator{
+			Owner:  repo.GetOwner().GetLogin(),
+			Repo:   repo.GetName(),
+			Number: pr.GetNumber(),
+			Value:  pr,
+		})
+		if err != nil {
+			logger.Error().Err(err).Msgf("Failed to evaluate pull request for %s event on %.10s", eventType, sha)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("failed to evaluate %d of %d pull requests with head %.10s", failed, len(prs), sha)
+	}
+	return nil
+}
+
+// openPullRequestsForCommit returns the open pull requests in the repository
+// that have the commit as their head.
+func openPullRequestsForCommit(ctx context.Context, client *github.Client, owner, repo, sha string) ([]*github.PullRequest, error) {
+	req, err := client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/commits/%s/pulls?per_page=100", owner, repo, sha), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create pull requests request")
+	}
+
+	// the client does not support listing the pull requests of a commit,
+	// which requires a preview media type
+	req.Header.Set("Accept", "application/vnd.github.groot-preview+json")
+
+	var prs []*github.PullRequest
+	if _, err := client.Do(ctx, req, &prs); err != nil {
+		return nil, errors.Wrapf(err, "failed to list pull requests for commit %.10s", sha)
+	}
+
+	var open []*github.PullRequest
+	for _, pr := range prs {
+		if pr.GetState() == "open" && pr.GetHead().GetSHA() == sha {
+			open = append(open, pr)
+		}
+	}
+	return open, nil
+}
+
+// ignoresEvent returns true if a valid policy disables evaluation for events
+// of the given type or cannot be affected by them. Missing and invalid
+// policies are evaluated to report errors, except for commit events, which
+// cannot change the error.
+func (b *Base) ignoresEvent(ctx context.Context, fetchedConfig FetchedConfig, eventType string) bool {
+	logger := zerolog.Ctx(ctx)
+
+	switch {
+	case !fetchedConfig.Valid():
+		if policy.IsCommitEvent(eventType) {
+			logger.Debug().Msgf("Skipping evaluation for %s event without a valid policy: %s", eventType, fetchedConfig)
+			return true
+		}
+		return false
+	case !fetchedConfig.Config.Options.EvaluatesOn(eventType):
+		logger.Debug().Msgf("Skipping evaluation for %s event disabled by policy: %s", eventType, fetchedConfig)
+		return true
+	case !fetchedConfig.Config.DependsOn(eventType):
+		logger.Debug().Msgf("Skipping evaluation for %s event that cannot affect policy: %s", eventType, fetchedConfig)
+		return true
+	}
+	return false
+}
+
+// findForcePushedRules returns the rules in the result that are pending
+// because approvals were invalidated by a force-push.
+func findForcePushedRules(result *common.Result) []*common.Result {
+	if result.Reason == common.ReasonForcePushed {
+		return []*common.Result{result}
+	}
+	var rules []*common.Result
+	for _, c := range result.Children {
+		rules = append(rules, findForcePushedRules(c)...)
+	}
+	return rules
+}
+
 func (b *Base) EvaluateFetchedConfig(ctx context.Context, prctx pull.Context, client *github.Client, fetchedConfig FetchedConfig) error {
+	_, err := b.EvaluateFetchedConfigResult(ctx, prctx, client, fetchedConfig)
+	return err
+}
+
+// EvaluateFetchedConfigResult is like EvaluateFetchedConfig, but also returns
+// the result of the evaluation. The result is nil if the policy is missing or
+// could not be parsed.
+func (b *Base) EvaluateFetchedConfigResult(ctx context.Context, prctx pull.Context, client *github.Client, fetchedConfig FetchedConfig) (*common.Result, error) {
 	logger := zerolog.Ctx(ctx)
 
 	if fetchedConfig.Missing() {
 		logger.Debug().Msgf("policy does not exist: %s", fetchedConfig)
-		return nil
+		if b.PullOpts.PostMissingPolicyStatus {
+			return nil, b.PostStatus(ctx, prctx, client, "error", fetchedConfig.Description())
+		}
+		return nil, nil
 	}
 
 	if fetchedConfig.Invalid() {
 		logger.Warn().Err(fetchedConfig.Error).Msgf("invalid policy: %s", fetchedConfig)
 		err := b.PostStatus(ctx, prctx, client, "error", fetchedConfig.Description())
-		return err
+		return nil, err
 	}
 
 	evaluator, err := policy.ParsePolicy(fetchedConfig.Config)
 	if err != nil {
-		statusMessage := fmt.Sprintf("Invalid policy defined by %s", fetchedConfig)
-		logger.Debug().Err(err).Msg(statusMessage)
-		err := b.PostStatus(ctx, prctx, client, "error", statusMessage)
-		return err
+		logger.Warn().Err(err).Msgf("invalid policy: %s", fetchedConfig)
+		err := b.PostStatus(ctx, prctx, client, "error", fetchedConfig.InvalidDescription(err))
+		return nil, err
 	}
 
 	result := evaluator.Evaluate(ctx, prctx)
@@ -174,7 +428,14 @@ func (b *Base) EvaluateFetchedConfig(ctx context.Context, prctx pull.Context, cl
 		statusMessage := fmt.Sprintf("Error evaluating policy defined by %s", fetchedConfig)
 		logger.Warn().Err(result.Error).Msg(statusMessage)
 		err := b.PostStatus(ctx, prctx, client, "error", statusMessage)
-		return err
+		return &result, err
+	}
+
+	if result.Reason == common.ReasonBypassed {
+		logger.Warn().Str(LogKeyAudit, "bypass").Msg(result.Description)
+	}
+	for _, r := range findForcePushedRules(&result) {
+		logger.Warn().Str(LogKeyAudit, "force_push").Msgf("Approval rule %q: %s", r.Name, r.Description)
 	}
 
 	statusDescription := result.Description
@@ -190,8 +451,54 @@ func (b *Base) EvaluateFetchedConfig(ctx context.Context, prctx pull.Context, cl
 		statusState = "error"
 		statusDescription = "All rules were skipped. At least one rule must match."
 	default:
-		return errors.Errorf("evaluation resulted in unexpected state: %s", result.Status)
+		return &result, errors.Errorf("evaluation resulted in unexpected state: %s", result.Status)
+	}
+
+	// check the existing status before posting so the webhook is only sent
+	// when the pull request transitions to approved
+	sendWebhook := false
+	if b.PullOpts.ApprovalWebhook != nil && result.Status == common.StatusApproved {
+		approved, err := b.hasStatus(ctx, prctx, client, b.statusContext(prctx), statusState)
+		if err != nil {
+			return &result, err
+		}
+		sendWebhook = !approved
+	}
+
+	// explain the only pending rule if it has a description
+	if result.Status == common.StatusPending {
+		if pending := findPendingRules(&result, nil); len(pending) == 1 && pending[0].RuleDescription != "" {
+			statusDescription = fmt.Sprintf("%s: %s", statusDescription, pending[0].RuleDescription)
+		}
+	}
+
+	if templates := fetchedConfig.Config.Options.StatusTemplates; templates != nil {
+		desc, err := templates.RenderDescription(newStatusData(prctx, &result, statusDescription))
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to render status description, using the default")
+		} else {
+			statusDescription = desc
+		}
 	}
 
-	return b.PostStatus(ctx, prctx, client, statusState, statusDescription)
+	if err := b.PostStatus(ctx, prctx, client, statusState, statusDescription); err != nil {
+		return &result, err
+	}
+
+	// a failed webhook should not leave the pending comment out of date, so
+	// update the comment before returning the webhook error
+	var webhookErr error
+	if sendWebhook {
+		webhookErr = b.SendApprovalWebhook(ctx, prctx, &result)
+	}
+
+	if b.PullOpts.PostPendingComment {
+		if err := b.UpdatePendingComment(ctx, prctx, client, fetchedConfig.Config, &result); err != nil {
+			if webhookErr != nil {
+				logger.Error().Err(webhookErr).Msg("Failed to send approval webhook")
+			}
+			return &result, err
+		}
+	}
+	return &result, webhookErr
 }
@@ -0,0 +1,54 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenPullRequestsForCommit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/testorg/testrepo/commits/aaaa/pulls", r.URL.Path)
+		assert.Contains(t, r.Header.Get("Accept"), "groot-preview")
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{"number": 1, "state": "open", "head": {"sha": "aaaa"}},
+			{"number": 2, "state": "open", "head": {"sha": "bbbb"}},
+			{"number": 3, "state": "closed", "head": {"sha": "aaaa"}},
+			{"number": 4, "state": "open", "head": {"sha": "aaaa"}}
+		]`)
+	}))
+	defer srv.Close()
+
+	client := github.NewClient(srv.Client())
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	prs, err := openPullRequestsForCommit(context.Background(), client, "testorg", "testrepo", "aaaa")
+	require.NoError(t, err)
+
+	require.Len(t, prs, 2, "incorrect number of pull requests")
+	assert.Equal(t, 1, prs[0].GetNumber())
+	assert.Equal(t, 4, prs[1].GetNumber())
+}
@@ -0,0 +1,140 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/go-github/github"
+	"github.com/palantir/go-githubapp/githubapp"
+	"github.com/pkg/errors"
+)
+
+// Checks evaluates the pull requests for a commit when CI on the commit
+// completes, since workflow, status, and check run requirements depend on the
+// results.
+type Checks struct {
+	Base
+}
+
+func (h *Checks) Handles() []string { return []string{"check_run", "workflow_run"} }
+
+// actionsAppSlug identifies check runs created by GitHub Actions jobs
+const actionsAppSlug = "github-actions"
+
+// checkRunEvent is the payload of check_run events, including the app slug
+// that the GitHub client does not define
+type checkRunEvent struct {
+	Action   string `json:"action"`
+	CheckRun struct {
+		Name    string `json:"name"`
+		HeadSHA string `json:"head_sha"`
+		App     struct {
+			Slug string `json:"slug"`
+		} `json:"app"`
+		PullRequests []*github.PullRequest `json:"pull_requests"`
+	} `json:"check_run"`
+	Repo         *github.Repository   `json:"repository"`
+	Installation *github.Installation `json:"installation"`
+}
+
+func (e *checkRunEvent) GetInstallation() *github.Installation {
+	return e.Installation
+}
+
+// workflowRunEvent is the payload of workflow_run events, which the GitHub
+// client does not define
+type workflowRunEvent struct {
+	Action      string `json:"action"`
+	WorkflowRun struct {
+		Name         string                `json:"name"`
+		HeadSHA      string                `json:"head_sha"`
+		PullRequests []*github.PullRequest `json:"pull_requests"`
+	} `json:"workflow_run"`
+	Repo         *github.Repository   `json:"repository"`
+	Installation *github.Installation `json:"installation"`
+}
+
+func (e *workflowRunEvent) GetInstallation() *github.Installation {
+	return e.Installation
+}
+
+func (h *Checks) Handle(ctx context.Context, eventType, deliveryID string, payload []byte) error {
+	if eventType == "check_run" {
+		return h.handleCheckRun(ctx, eventType, payload)
+	}
+	return h.handleWorkflowRun(ctx, eventType, payload)
+}
+
+// Handle check_run
+// https://developer.github.com/v3/activity/events/types/#checkrunevent
+func (h *Checks) handleCheckRun(ctx context.Context, eventType string, payload []byte) error {
+	var event checkRunEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return errors.Wrap(err, "failed to parse check run event payload")
+	}
+
+	installationID := githubapp.GetInstallationIDFromEvent(&event)
+	ctx, logger := githubapp.PrepareRepoContext(ctx, installationID, event.Repo)
+
+	if event.Action != "completed" {
+		return nil
+	}
+
+	// jobs of a workflow are check runs, so evaluate once when the workflow
+	// run completes instead of after every job
+	if event.CheckRun.App.Slug == actionsAppSlug {
+		logger.Debug().Msgf("Ignoring check run '%s' from a workflow, waiting for the workflow run", event.CheckRun.Name)
+		return nil
+	}
+
+	logger.Debug().Msgf("Evaluating pull requests for completed check run '%s'", event.CheckRun.Name)
+	prs := pullRequestsInRepo(event.Repo, event.CheckRun.PullRequests)
+	return h.evaluatePullRequests(ctx, installationID, eventType, event.Repo, event.CheckRun.HeadSHA, prs)
+}
+
+// Handle workflow_run
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#workflow_run
+func (h *Checks) handleWorkflowRun(ctx context.Context, eventType string, payload []byte) error {
+	var event workflowRunEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return errors.Wrap(err, "failed to parse workflow run event payload")
+	}
+
+	installationID := githubapp.GetInstallationIDFromEvent(&event)
+	ctx, logger := githubapp.PrepareRepoContext(ctx, installationID, event.Repo)
+
+	if event.Action != "completed" {
+		return nil
+	}
+
+	logger.Debug().Msgf("Evaluating pull requests for completed workflow '%s'", event.WorkflowRun.Name)
+	prs := pullRequestsInRepo(event.Repo, event.WorkflowRun.PullRequests)
+	return h.evaluatePullRequests(ctx, installationID, eventType, event.Repo, event.WorkflowRun.HeadSHA, prs)
+}
+
+// pullRequestsInRepo returns the pull requests from a check event payload
+// that are opened against the repository. Payloads also list pull requests
+// in other repositories that have the same head branch.
+func pullRequestsInRepo(repo *github.Repository, prs []*github.PullRequest) []*github.PullRequest {
+	var inRepo []*github.PullRequest
+	for _, pr := range prs {
+		if pr.GetBase().GetRepo().GetID() == repo.GetID() {
+			inRepo = append(inRepo, pr)
+		}
+	}
+	return inRepo
+}
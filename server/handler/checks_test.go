@@ -0,0 +1,68 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecksIgnoredEvents(t *testing.T) {
+	// the handler has no client, so evaluating a pull request panics
+	h := &Checks{}
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		EventType string
+		Payload   string
+	}{
+		"checkRunCreated": {
+			"check_run",
+			`{"action": "created", "check_run": {"name": "lint", "head_sha": "aaaa", "app": {"slug": "circleci"}}}`,
+		},
+		"actionsCheckRun": {
+			"check_run",
+			`{"action": "completed", "check_run": {"name": "build", "head_sha": "aaaa", "app": {"slug": "github-actions"}}}`,
+		},
+		"workflowRunRequested": {
+			"workflow_run",
+			`{"action": "requested", "workflow_run": {"name": "build", "head_sha": "aaaa"}}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, h.Handle(ctx, test.EventType, "1", []byte(test.Payload)))
+		})
+	}
+}
+
+func TestPullRequestsInRepo(t *testing.T) {
+	repo := &github.Repository{ID: github.Int64(1)}
+	prs := []*github.PullRequest{
+		{Number: github.Int(1), Base: &github.PullRequestBranch{Repo: &github.Repository{ID: github.Int64(1)}}},
+		{Number: github.Int(2), Base: &github.PullRequestBranch{Repo: &github.Repository{ID: github.Int64(2)}}},
+		{Number: github.Int(3), Base: &github.PullRequestBranch{Repo: &github.Repository{ID: github.Int64(1)}}},
+	}
+
+	inRepo := pullRequestsInRepo(repo, prs)
+	if assert.Len(t, inRepo, 2) {
+		assert.Equal(t, 1, inRepo[0].GetNumber())
+		assert.Equal(t, 3, inRepo[1].GetNumber())
+	}
+}
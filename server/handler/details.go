@@ -25,6 +25,7 @@ import (
 	"github.com/bluekeyes/templatetree"
 	"github.com/google/go-github/github"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 	"goji.io/pat"
 
 	"github.com/palantir/policy-bot/policy"
@@ -98,7 +99,8 @@ func (h *Details) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
 	ctx, _ = h.PreparePRContext(ctx, installation.ID, pr)
 
 	mbrCtx := NewCrossOrgMembershipContext(ctx, client, owner, h.Installations, h.ClientCreator)
-	prctx, err := pull.NewGitHubContext(ctx, mbrCtx, client, v4client, pull.Locator{
+	extCtx := NewHTTPExternalContext(ctx, http.DefaultClient, h.PullOpts.ExternalChecks, h.ApproverLists)
+	prctx, err := pull.NewGitHubContext(ctx, mbrCtx, extCtx, client, v4client, pull.Locator{
 		Owner:  owner,
 		Repo:   repo,
 		Number: number,
@@ -110,17 +112,39 @@ func (h *Details) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
 
 	var data struct {
 		Error       error
+		Invalid     bool
 		Result      *common.Result
+		Summary     string
 		PullRequest *github.PullRequest
 		User        string
 		PolicyURL   string
+		PolicyPath  string
+
+		// DefaultPolicy is set if the organization default policy is in effect
+		DefaultPolicy string
+
+		// Fork is the full name of the head repository if it is a fork
+		Fork string
+
+		// Bot is the login of the author if they are a GitHub App
+		Bot string
 	}
 
 	data.PullRequest = pr
+	if head := pr.GetHead().GetRepo().GetFullName(); head != pr.GetBase().GetRepo().GetFullName() {
+		data.Fork = head
+	}
+	if author := pr.GetUser().GetLogin(); pull.IsBot(author) {
+		data.Bot = author
+	}
 	data.User = user
 
 	config, err := h.ConfigFetcher.ConfigForPR(ctx, prctx, client)
 	data.PolicyURL = getPolicyURL(pr, config)
+	data.PolicyPath = config.Path
+	if config.Default != nil {
+		data.DefaultPolicy = fmt.Sprintf("%s/%s", owner, config.Default.Repository)
+	}
 
 	if err != nil {
 		data.Error = errors.WithMessage(err, fmt.Sprintf("Failed to fetch configuration at ref=%s", config.Ref))
@@ -133,19 +157,30 @@ func (h *Details) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
 	}
 
 	if config.Invalid() {
-		data.Error = errors.WithMessage(config.Error, config.Description())
+		data.Error = errors.New(config.Description())
+		data.Invalid = true
 		return h.render(w, data)
 	}
 
 	evaluator, err := policy.ParsePolicy(config.Config)
 	if err != nil {
-		data.Error = errors.WithMessage(err, fmt.Sprintf("invalid policy at ref \"%s\"", config.Ref))
+		data.Error = errors.New(config.InvalidDescription(err))
+		data.Invalid = true
 		return h.render(w, data)
 	}
 
 	result := evaluator.Evaluate(ctx, prctx)
 	data.Result = &result
 
+	if templates := config.Config.Options.StatusTemplates; templates != nil {
+		summary, err := templates.RenderSummary(newStatusData(prctx, &result, result.Description))
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("Failed to render details summary, using the default")
+		} else {
+			data.Summary = summary
+		}
+	}
+
 	return h.render(w, data)
 }
 
@@ -158,6 +193,14 @@ func (h *Details) render(w http.ResponseWriter, data interface{}) error {
 func getPolicyURL(pr *github.PullRequest, config FetchedConfig) string {
 	base := pr.GetBase().GetRepo().GetHTMLURL()
 	if u, _ := url.Parse(base); u != nil {
+		if d := config.Default; d != nil {
+			ref := d.Ref
+			if ref == "" {
+				ref = "HEAD"
+			}
+			u.Path = path.Join(path.Dir(u.Path), d.Repository, "blob", ref, config.Path)
+			return u.String()
+		}
 		u.Path = path.Join(u.Path, "blob", pr.GetBase().GetRef(), config.Path)
 		return u.String()
 	}
@@ -0,0 +1,225 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	DefaultExternalCheckTimeout     = 5 * time.Second
+	DefaultExternalCheckMaxAttempts = 3
+
+	externalCheckMaxBody = 1 << 20
+)
+
+var (
+	// exponential 500ms delay between attempts
+	externalCheckBaseDelay = 500 * time.Millisecond
+
+	// passedBodyPatterns caches compiled PassedBody patterns by expression
+	passedBodyPatterns sync.Map
+)
+
+// ExternalCheckConfig defines an HTTP endpoint that reports if a user passes a
+// check, like signing a contributor license agreement.
+type ExternalCheckConfig struct {
+	// URL is the location of the check. The string "{user}" is replaced with
+	// the escaped login name of the user being checked.
+	URL string `yaml:"url"`
+
+	// Headers are added to each request, for example to set Authorization.
+	Headers map[string]string `yaml:"headers"`
+
+	// PassedStatus is the response status code that indicates the user
+	// passed the check. Any other status below 500 indicates the user did
+	// not pass. Defaults to 200.
+	PassedStatus int `yaml:"passed_status"`
+
+	// PassedBody, if set, is a regular expression that must match the
+	// response body for the user to pass the check.
+	PassedBody string `yaml:"passed_body"`
+
+	// Timeout is the maximum duration of each request.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// MaxAttempts is the maximum number of requests made for a check if
+	// requests fail or return a server error.
+	MaxAttempts int `yaml:"max_attempts"`
+}
+
+// HTTPExternalContext is a pull.ExternalContext that performs checks by making
+// requests to configured HTTP endpoints. A new instance should be created for
+// each request, as results are cached for the lifetime of the context.
+type HTTPExternalContext struct {
+	ctx    context.Context
+	client *http.Client
+	checks map[string]ExternalCheckConfig
+	lists  *ApproverLists
+
+	results map[string]bool
+}
+
+func NewHTTPExternalContext(ctx context.Context, client *http.Client, checks map[string]ExternalCheckConfig, lists *ApproverLists) *HTTPExternalContext {
+	return &HTTPExternalContext{
+		ctx:     ctx,
+		client:  client,
+		checks:  checks,
+		lists:   lists,
+		results: make(map[string]bool),
+	}
+}
+
+func (c *HTTPExternalContext) ApproverList(name string) ([]string, error) {
+	if c.lists == nil {
+		return nil, errors.Errorf("approver list %q is not configured", name)
+	}
+	return c.lists.Get(c.ctx, name)
+}
+
+func (c *HTTPExternalContext) ExternalCheck(name, user string) (bool, error) {
+	key := name + ":" + user
+	if passed, ok := c.results[key]; ok {
+		return passed, nil
+	}
+
+	check, ok := c.checks[name]
+	if !ok {
+		return false, errors.Errorf("external check %q is not configured", name)
+	}
+
+	passed, err := c.runCheck(name, check, user)
+	if err != nil {
+		return false, err
+	}
+
+	c.results[key] = passed
+	return passed, nil
+}
+
+func (c *HTTPExternalContext) runCheck(name string, check ExternalCheckConfig, user string) (bool, error) {
+	logger := zerolog.Ctx(c.ctx)
+
+	maxAttempts := check.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultExternalCheckMaxAttempts
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var passed, retry bool
+		passed, retry, err = c.doCheck(check, user)
+		if !retry {
+			return passed, err
+		}
+
+		if attempt < maxAttempts {
+			delay := time.Duration(1<<uint(attempt-1)) * externalCheckBaseDelay
+			logger.Debug().Err(err).Msgf("external check %q failed on attempt %d, sleeping %s and trying again", name, attempt, delay)
+
+			select {
+			case <-c.ctx.Done():
+				return false, errors.Wrapf(c.ctx.Err(), "external check %q canceled after %d attempts", name, attempt)
+			case <-time.After(delay):
+			}
+		}
+	}
+	return false, errors.WithMessage(err, fmt.Sprintf("external check %q failed after %d attempts", name, maxAttempts))
+}
+
+// doCheck makes a single request for the check and returns true if the user
+// passed. It also returns true if the request failed and should be retried.
+func (c *HTTPExternalContext) doCheck(check ExternalCheckConfig, user string) (passed bool, retry bool, err error) {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultExternalCheckTimeout
+	}
+
+	var pattern *regexp.Regexp
+	if check.PassedBody != "" {
+		if pattern, err = passedBodyPattern(check.PassedBody); err != nil {
+			return false, false, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+
+	u := strings.Replace(check.URL, "{user}", url.PathEscape(user), -1)
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return false, false, errors.Wrap(err, "failed to create external check request")
+	}
+	for k, v := range check.Headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return false, true, errors.Wrap(err, "external check request failed")
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	if res.StatusCode >= 500 {
+		return false, true, errors.Errorf("external check returned status %d", res.StatusCode)
+	}
+
+	passedStatus := check.PassedStatus
+	if passedStatus == 0 {
+		passedStatus = http.StatusOK
+	}
+	if res.StatusCode != passedStatus {
+		return false, false, nil
+	}
+
+	if pattern == nil {
+		return true, false, nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, externalCheckMaxBody))
+	if err != nil {
+		return false, true, errors.Wrap(err, "failed to read external check response")
+	}
+	return pattern.Match(body), false, nil
+}
+
+// passedBodyPattern returns the compiled PassedBody pattern, compiling each
+// expression only once.
+func passedBodyPattern(expr string) (*regexp.Regexp, error) {
+	if pattern, ok := passedBodyPatterns.Load(expr); ok {
+		return pattern.(*regexp.Regexp), nil
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compile external check body pattern")
+	}
+	passedBodyPatterns.Store(expr, pattern)
+	return pattern, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalCheck(t *testing.T) {
+	defer setExternalCheckBaseDelay(time.Millisecond)()
+
+	var requests int
+	var failures int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		switch r.URL.Path {
+		case "/users/signed":
+			fmt.Fprint(w, `{"signed": true}`)
+		case "/users/unsigned":
+			fmt.Fprint(w, `{"signed": false}`)
+		case "/users/a/b":
+			assert.Equal(t, "/users/a%2Fb", r.URL.RawPath, "user was not escaped")
+			assert.Equal(t, "token", r.Header.Get("Authorization"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	checks := map[string]ExternalCheckConfig{
+		"status": {
+			URL:          srv.URL + "/users/{user}",
+			Headers:      map[string]string{"Authorization": "token"},
+			PassedStatus: http.StatusNoContent,
+		},
+		"body": {
+			URL:        srv.URL + "/users/{user}",
+			PassedBody: `"signed": true`,
+		},
+		"invalid": {
+			URL:        srv.URL + "/users/{user}",
+			PassedBody: `(`,
+		},
+	}
+
+	newContext := func() *HTTPExternalContext {
+		requests = 0
+		return NewHTTPExternalContext(context.Background(), srv.Client(), checks, nil)
+	}
+
+	t.Run("passedStatus", func(t *testing.T) {
+		passed, err := newContext().ExternalCheck("status", "a/b")
+		require.NoError(t, err)
+		assert.True(t, passed, "user did not pass the check")
+	})
+
+	t.Run("otherStatus", func(t *testing.T) {
+		passed, err := newContext().ExternalCheck("status", "unknown")
+		require.NoError(t, err)
+		assert.False(t, passed, "user passed the check")
+		assert.Equal(t, 1, requests, "client error was retried")
+	})
+
+	t.Run("passedBody", func(t *testing.T) {
+		ctx := newContext()
+
+		passed, err := ctx.ExternalCheck("body", "signed")
+		require.NoError(t, err)
+		assert.True(t, passed, "user did not pass the check")
+
+		passed, err = ctx.ExternalCheck("body", "unsigned")
+		require.NoError(t, err)
+		assert.False(t, passed, "user passed the check")
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		ctx := newContext()
+
+		_, err := ctx.ExternalCheck("body", "signed")
+		require.NoError(t, err)
+		_, err = ctx.ExternalCheck("body", "signed")
+		require.NoError(t, err)
+		assert.Equal(t, 1, requests, "cached result was not used")
+	})
+
+	t.Run("retry", func(t *testing.T) {
+		failures = 2
+		passed, err := newContext().ExternalCheck("body", "signed")
+		require.NoError(t, err)
+		assert.True(t, passed, "user did not pass the check")
+		assert.Equal(t, 3, requests, "incorrect number of requests")
+	})
+
+	t.Run("maxAttempts", func(t *testing.T) {
+		failures = 3
+		_, err := newContext().ExternalCheck("body", "signed")
+		assert.Error(t, err, "failed check did not return an error")
+		assert.Equal(t, DefaultExternalCheckMaxAttempts, requests, "incorrect number of requests")
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		defer setExternalCheckBaseDelay(time.Hour)()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+
+		failures = 1
+		requests = 0
+		start := time.Now()
+		_, err := NewHTTPExternalContext(ctx, srv.Client(), checks, nil).ExternalCheck("body", "signed")
+		assert.Error(t, err, "canceled check did not return an error")
+		assert.True(t, time.Since(start) < time.Minute, "retry delay ignored the context")
+		assert.Equal(t, 1, requests, "incorrect number of requests")
+	})
+
+	t.Run("invalidPattern", func(t *testing.T) {
+		_, err := newContext().ExternalCheck("invalid", "signed")
+		assert.Error(t, err, "invalid pattern did not return an error")
+		assert.Equal(t, 0, requests, "request was made with an invalid pattern")
+	})
+
+	t.Run("notConfigured", func(t *testing.T) {
+		_, err := newContext().ExternalCheck("missing", "signed")
+		assert.Error(t, err, "missing check did not return an error")
+	})
+}
+
+func setExternalCheckBaseDelay(d time.Duration) func() {
+	prev := externalCheckBaseDelay
+	externalCheckBaseDelay = d
+	return func() { externalCheckBaseDelay = prev }
+}
@@ -37,6 +37,27 @@ type FetchedConfig struct {
 	Path   string
 	Config *policy.Config
 	Error  error
+
+	// SearchedPaths contains the paths checked for a policy, in order
+	SearchedPaths []string
+
+	// Default is set if the repository does not have a policy and the
+	// organization default policy was used instead
+	Default *DefaultPolicyConfig
+
+	// Content is the policy after merging installation settings
+	Content []byte
+
+	// Source is the location of the file that contains the policy, which is
+	// in another repository for remote and default policies
+	Source string
+
+	// RuleSources maps the name of each approval rule to the location it was
+	// defined in, either Source or the installation settings
+	RuleSources map[string]string
+
+	// location is the file that contains the policy
+	location policyLocation
 }
 
 func (fc FetchedConfig) Missing() bool {
@@ -52,21 +73,56 @@ func (fc FetchedConfig) Invalid() bool {
 }
 
 func (fc FetchedConfig) String() string {
+	if fc.Default != nil {
+		return fmt.Sprintf("%s/%s ref=%s (default policy from %s/%s)", fc.Owner, fc.Repo, fc.Ref, fc.Owner, fc.Default.Repository)
+	}
 	return fmt.Sprintf("%s/%s ref=%s", fc.Owner, fc.Repo, fc.Ref)
 }
 
 func (fc FetchedConfig) Description() string {
 	switch {
 	case fc.Missing():
+		if len(fc.SearchedPaths) > 1 {
+			return fmt.Sprintf("No policy found at ref=%s in any of: %s", fc.Ref, strings.Join(fc.SearchedPaths, ", "))
+		}
 		return fmt.Sprintf("No policy found at ref=%s", fc.Ref)
 	case fc.Invalid():
-		return fmt.Sprintf("Invalid configuration defined by ref=%s", fc.Ref)
+		return fc.InvalidDescription(fc.Error)
 	}
 	return fmt.Sprintf("Valid policy found for ref=%s", fc.Ref)
 }
 
+// InvalidDescription describes a policy that failed to parse or validate with
+// the given error. It includes the error so that authors can find the problem
+// from the status without access to the server logs.
+func (fc FetchedConfig) InvalidDescription(err error) string {
+	return fmt.Sprintf("Invalid policy in %s at ref=%s: %v", fc.Path, fc.Ref, err)
+}
+
+// DefaultPolicyConfig locates a policy that applies to all repositories in an
+// organization that do not define their own policy. The repository is always
+// in the same organization as the pull request.
+type DefaultPolicyConfig struct {
+	Repository string `yaml:"repository"`
+	Path       string `yaml:"path"`
+	Ref        string `yaml:"ref"`
+}
+
 type ConfigFetcher struct {
+	// PolicyPath is the default path used for remote policy references
 	PolicyPath string
+
+	// PolicyPaths is the ordered list of paths searched for a policy. If
+	// empty, only PolicyPath is searched.
+	PolicyPaths []string
+
+	// DefaultPolicy is used when none of the policy paths exist in a
+	// repository. If nil, repositories without a policy are not evaluated.
+	DefaultPolicy *DefaultPolicyConfig
+
+	// InstallationConfigs are partial policies merged beneath the policies of
+	// all repositories owned by the key, which is an organization or user.
+	InstallationConfigs map[string]interface{}
 }
 
 // ConfigForPR fetches the policy configuration for a PR. It returns an error
@@ -75,22 +131,82 @@ type ConfigFetcher struct {
 // fields are set on the FetchedConfig.
 func (cf *ConfigFetcher) ConfigForPR(ctx context.Context, prctx pull.Context, client *github.Client) (FetchedConfig, error) {
 	base, _ := prctx.Branches()
+	return cf.ConfigForRef(ctx, client, prctx.RepositoryOwner(), prctx.RepositoryName(), base)
+}
+
+// ConfigForRef fetches the policy configuration that applies to pull requests
+// targeting ref in a repository. Errors are returned like ConfigForPR.
+func (cf *ConfigFetcher) ConfigForRef(ctx context.Context, client *github.Client, owner, repo, ref string) (FetchedConfig, error) {
 	fc := FetchedConfig{
-		Owner: prctx.RepositoryOwner(),
-		Repo:  prctx.RepositoryName(),
-		Ref:   base,
-		Path:  cf.PolicyPath,
+		Owner:         owner,
+		Repo:          repo,
+		Ref:           ref,
+		Path:          cf.PolicyPath,
+		SearchedPaths: cf.policyPaths(),
 	}
 
-	configBytes, err := cf.fetchConfig(ctx, client, fc.Owner, fc.Repo, fc.Ref)
+	configBytes, path, location, err := cf.fetchConfig(ctx, client, fc.Owner, fc.Repo, fc.Ref, fc.SearchedPaths)
 	if err != nil {
 		return fc, err
 	}
 
+	if configBytes == nil && cf.DefaultPolicy != nil {
+		configBytes, path, location, err = cf.fetchDefaultConfig(ctx, client, fc.Owner, fc.Repo)
+		if err != nil {
+			return fc, err
+		}
+		if configBytes != nil {
+			fc.Default = cf.DefaultPolicy
+		}
+	}
+
 	if configBytes == nil {
 		return fc, nil
 	}
 
+	fc.Path = path
+	fc.Source = location.String()
+	fc.location = location
+
+	// included files are in the same repository and ref as the policy, which
+	// is the remote repository for remote and default policies
+	var fetchErr error
+	configBytes, includeSources, err := policy.ResolveIncludes(configBytes, location.Path, func(p string) ([]byte, error) {
+		b, err := cf.fetchConfigContents(ctx, client, location.Owner, location.Repo, location.Ref, p)
+		if err != nil {
+			fetchErr = err
+		}
+		return b, err
+	})
+	if fetchErr != nil {
+		return fc, fetchErr
+	}
+	if err != nil {
+		fc.Error = err
+		return fc, nil
+	}
+
+	installation, ok := cf.InstallationConfigs[fc.Owner]
+	fc.RuleSources = ruleSources(installation, configBytes, fc.Source, "installation settings for "+fc.Owner)
+	for name, p := range includeSources {
+		fc.RuleSources[name] = configLocation(location.Owner, location.Repo, location.Ref, p)
+	}
+
+	if ok {
+		configBytes, err = mergeInstallationConfig(installation, configBytes)
+		if err != nil {
+			fc.Error = err
+			return fc, nil
+		}
+	}
+
+	configBytes, err = policy.ApplyRuleDefaults(configBytes)
+	if err != nil {
+		fc.Error = err
+		return fc, nil
+	}
+	fc.Content = configBytes
+
 	config, err := cf.unmarshalConfig(configBytes)
 	if err != nil {
 		fc.Error = err
@@ -101,26 +217,71 @@ func (cf *ConfigFetcher) ConfigForPR(ctx context.Context, prctx pull.Context, cl
 	return fc, nil
 }
 
-func (cf *ConfigFetcher) fetchConfig(ctx context.Context, client *github.Client, owner, repo, ref string) ([]byte, error) {
+func (cf *ConfigFetcher) policyPaths() []string {
+	if len(cf.PolicyPaths) > 0 {
+		return cf.PolicyPaths
+	}
+	return []string{cf.PolicyPath}
+}
+
+// fetchDefaultConfig returns the contents of the organization default policy,
+// its path, and the location of the contents. It returns a nil slice if the default policy
+// does not exist or if the repository being evaluated is the one that contains
+// the default.
+func (cf *ConfigFetcher) fetchDefaultConfig(ctx context.Context, client *github.Client, owner, repo string) ([]byte, string, policyLocation, error) {
 	logger := zerolog.Ctx(ctx)
 
-	configBytes, err := cf.fetchConfigContents(ctx, client, owner, repo, ref, cf.PolicyPath)
-	if err != nil {
-		return nil, err
+	// the default repository uses its own policy, not the default, which
+	// prevents the default policy from referencing itself
+	if cf.DefaultPolicy.Repository == repo {
+		logger.Debug().Msgf("Not using default policy for %s/%s because it defines the default policy", owner, repo)
+		return nil, "", policyLocation{}, nil
+	}
+
+	path := cf.DefaultPolicy.Path
+	if path == "" {
+		path = cf.PolicyPath
+	}
+
+	logger.Debug().Msgf("No policy found in %s/%s, trying default policy in %s/%s", owner, repo, owner, cf.DefaultPolicy.Repository)
+	return cf.fetchConfig(ctx, client, owner, cf.DefaultPolicy.Repository, cf.DefaultPolicy.Ref, []string{path})
+}
+
+// fetchConfig returns the contents of the policy, the local path where the
+// policy or the reference to a remote policy was found, and the location of
+// the contents. It returns a nil slice if none of the paths exist.
+func (cf *ConfigFetcher) fetchConfig(ctx context.Context, client *github.Client, owner, repo, ref string, paths []string) ([]byte, string, policyLocation, error) {
+	logger := zerolog.Ctx(ctx)
+
+	var path string
+	var configBytes []byte
+	for _, p := range paths {
+		b, err := cf.fetchConfigContents(ctx, client, owner, repo, ref, p)
+		if err != nil {
+			return nil, "", policyLocation{}, err
+		}
+		if b != nil {
+			path, configBytes = p, b
+			break
+		}
+	}
+
+	if configBytes == nil {
+		return nil, "", policyLocation{}, nil
 	}
 
 	var rawConfig map[string]interface{}
 	_ = yaml.Unmarshal(configBytes, &rawConfig)
 
 	if _, isRemote := rawConfig["remote"]; !isRemote {
-		logger.Debug().Msgf("Found local policy config in %s/%s@%s", owner, repo, ref)
-		return configBytes, nil
+		logger.Debug().Msgf("Found local policy config in %s/%s@%s/%s", owner, repo, ref, path)
+		return configBytes, path, policyLocation{Owner: owner, Repo: repo, Ref: ref, Path: path}, nil
 	}
-	logger.Debug().Msgf("Found reference to remote policy in %s/%s@%s", owner, repo, ref)
+	logger.Debug().Msgf("Found reference to remote policy in %s/%s@%s/%s", owner, repo, ref, path)
 
 	var remoteConfig policy.RemoteConfig
 	if err := yaml.UnmarshalStrict(configBytes, &remoteConfig); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal reference to remote policy")
+		return nil, "", policyLocation{}, errors.Wrap(err, "failed to unmarshal reference to remote policy")
 	}
 
 	if remoteConfig.Path == "" {
@@ -129,17 +290,38 @@ func (cf *ConfigFetcher) fetchConfig(ctx context.Context, client *github.Client,
 
 	remoteParts := strings.Split(remoteConfig.Remote, "/")
 	if len(remoteParts) != 2 {
-		return nil, errors.Errorf("failed to parse remote config location from %q", remoteConfig.Remote)
+		return nil, "", policyLocation{}, errors.Errorf("failed to parse remote config location from %q", remoteConfig.Remote)
 	}
 
 	remoteOwner, remoteRepo := remoteParts[0], remoteParts[1]
 
 	remotePolicyBytes, err := cf.fetchConfigContents(ctx, client, remoteOwner, remoteRepo, remoteConfig.Ref, remoteConfig.Path)
 	if err != nil {
-		return nil, err
+		return nil, "", policyLocation{}, err
 	}
 
-	return remotePolicyBytes, nil
+	return remotePolicyBytes, path, policyLocation{Owner: remoteOwner, Repo: remoteRepo, Ref: remoteConfig.Ref, Path: remoteConfig.Path}, nil
+}
+
+// policyLocation identifies the file that contains the contents of a policy
+type policyLocation struct {
+	Owner string
+	Repo  string
+	Ref   string
+	Path  string
+}
+
+func (l policyLocation) String() string {
+	return configLocation(l.Owner, l.Repo, l.Ref, l.Path)
+}
+
+// configLocation describes the location of a file. An empty ref is the
+// default branch of the repository.
+func configLocation(owner, repo, ref, path string) string {
+	if ref == "" {
+		return fmt.Sprintf("%s/%s/%s", owner, repo, path)
+	}
+	return fmt.Sprintf("%s/%s@%s/%s", owner, repo, ref, path)
 }
 
 // fetchConfigContents returns a nil slice if there is no policy
@@ -0,0 +1,247 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPolicy = `
+policy:
+  approval:
+  - review
+approval_rules:
+- name: review
+`
+
+// contentServer serves the contents API for files keyed by
+// "owner/repo@ref/path" and records the keys of all requests
+type contentServer struct {
+	files    map[string]string
+	failing  map[string]bool
+	requests []string
+}
+
+func (s *contentServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// /repos/<owner>/<repo>/contents/<path>
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/repos/"), "/", 4)
+	if len(parts) != 4 || parts[2] != "contents" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	key := parts[0] + "/" + parts[1] + "@" + r.URL.Query().Get("ref") + "/" + parts[3]
+	s.requests = append(s.requests, key)
+
+	if s.failing[key] {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	content, ok := s.files[key]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"type":     "file",
+		"encoding": "base64",
+		"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+	})
+}
+
+func newContentClient(t *testing.T, s *contentServer) *github.Client {
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	client := github.NewClient(srv.Client())
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+	return client
+}
+
+func TestConfigForRefPolicyPaths(t *testing.T) {
+	ctx := context.Background()
+
+	cf := &ConfigFetcher{
+		PolicyPath:  ".policy.yml",
+		PolicyPaths: []string{".policy.yml", ".github/policy.yml"},
+	}
+
+	t.Run("firstPath", func(t *testing.T) {
+		s := &contentServer{files: map[string]string{
+			"testorg/testrepo@develop/.policy.yml":        testPolicy,
+			"testorg/testrepo@develop/.github/policy.yml": testPolicy,
+		}}
+
+		fc, err := cf.ConfigForRef(ctx, newContentClient(t, s), "testorg", "testrepo", "develop")
+		require.NoError(t, err)
+
+		assert.True(t, fc.Valid(), "policy is not valid: %v", fc.Error)
+		assert.Equal(t, ".policy.yml", fc.Path)
+		assert.Equal(t, "testorg/testrepo@develop/.policy.yml", fc.Source)
+		assert.Equal(t, []string{"testorg/testrepo@develop/.policy.yml"}, s.requests, "later paths were searched")
+	})
+
+	t.Run("partialMiss", func(t *testing.T) {
+		s := &contentServer{files: map[string]string{
+			"testorg/testrepo@develop/.github/policy.yml": testPolicy,
+		}}
+
+		fc, err := cf.ConfigForRef(ctx, newContentClient(t, s), "testorg", "testrepo", "develop")
+		require.NoError(t, err)
+
+		assert.True(t, fc.Valid(), "policy is not valid: %v", fc.Error)
+		assert.Equal(t, ".github/policy.yml", fc.Path)
+		assert.Equal(t, "testorg/testrepo@develop/.github/policy.yml", fc.Source)
+		assert.Equal(t, []string{
+			"testorg/testrepo@develop/.policy.yml",
+			"testorg/testrepo@develop/.github/policy.yml",
+		}, s.requests, "paths were not searched in order")
+	})
+
+	t.Run("allMissing", func(t *testing.T) {
+		s := &contentServer{}
+
+		fc, err := cf.ConfigForRef(ctx, newContentClient(t, s), "testorg", "testrepo", "develop")
+		require.NoError(t, err)
+
+		assert.True(t, fc.Missing(), "missing policy was found")
+		assert.Equal(t, []string{".policy.yml", ".github/policy.yml"}, fc.SearchedPaths)
+		assert.Equal(t, "No policy found at ref=develop in any of: .policy.yml, .github/policy.yml", fc.Description())
+	})
+
+	t.Run("errorStopsSearch", func(t *testing.T) {
+		s := &contentServer{
+			files: map[string]string{
+				"testorg/testrepo@develop/.github/policy.yml": testPolicy,
+			},
+			failing: map[string]bool{
+				"testorg/testrepo@develop/.policy.yml": true,
+			},
+		}
+
+		_, err := cf.ConfigForRef(ctx, newContentClient(t, s), "testorg", "testrepo", "develop")
+		assert.Error(t, err, "failed request fell back to a later path")
+	})
+
+	t.Run("singlePath", func(t *testing.T) {
+		s := &contentServer{}
+
+		fc, err := (&ConfigFetcher{PolicyPath: ".policy.yml"}).ConfigForRef(ctx, newContentClient(t, s), "testorg", "testrepo", "develop")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{".policy.yml"}, fc.SearchedPaths)
+		assert.Equal(t, "No policy found at ref=develop", fc.Description())
+	})
+}
+
+func TestConfigForRefDefaultPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	cf := &ConfigFetcher{
+		PolicyPath:  ".policy.yml",
+		PolicyPaths: []string{".policy.yml", ".github/policy.yml"},
+		DefaultPolicy: &DefaultPolicyConfig{
+			Repository: "policies",
+			Path:       "default.yml",
+		},
+	}
+
+	t.Run("repoPolicyFirst", func(t *testing.T) {
+		s := &contentServer{files: map[string]string{
+			"testorg/testrepo@develop/.github/policy.yml": testPolicy,
+			"testorg/policies@/default.yml":               testPolicy,
+		}}
+
+		fc, err := cf.ConfigForRef(ctx, newContentClient(t, s), "testorg", "testrepo", "develop")
+		require.NoError(t, err)
+
+		assert.Nil(t, fc.Default, "default policy was used")
+		assert.Equal(t, "testorg/testrepo@develop/.github/policy.yml", fc.Source)
+		assert.NotContains(t, s.requests, "testorg/policies@/default.yml")
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		s := &contentServer{files: map[string]string{
+			"testorg/policies@/default.yml": testPolicy,
+		}}
+
+		fc, err := cf.ConfigForRef(ctx, newContentClient(t, s), "testorg", "testrepo", "develop")
+		require.NoError(t, err)
+
+		assert.True(t, fc.Valid(), "policy is not valid: %v", fc.Error)
+		assert.Equal(t, cf.DefaultPolicy, fc.Default)
+		assert.Equal(t, "default.yml", fc.Path)
+		assert.Equal(t, "testorg/policies/default.yml", fc.Source)
+		assert.Equal(t, "testorg/testrepo ref=develop (default policy from testorg/policies)", fc.String())
+		assert.Equal(t, []string{
+			"testorg/testrepo@develop/.policy.yml",
+			"testorg/testrepo@develop/.github/policy.yml",
+			"testorg/policies@/default.yml",
+		}, s.requests)
+	})
+
+	t.Run("defaultPath", func(t *testing.T) {
+		s := &contentServer{files: map[string]string{
+			"testorg/policies@main/.policy.yml": testPolicy,
+		}}
+
+		cf := &ConfigFetcher{
+			PolicyPath:    ".policy.yml",
+			DefaultPolicy: &DefaultPolicyConfig{Repository: "policies", Ref: "main"},
+		}
+
+		fc, err := cf.ConfigForRef(ctx, newContentClient(t, s), "testorg", "testrepo", "develop")
+		require.NoError(t, err)
+
+		assert.True(t, fc.Valid(), "policy is not valid: %v", fc.Error)
+		assert.Equal(t, "testorg/policies@main/.policy.yml", fc.Source)
+	})
+
+	t.Run("defaultMissing", func(t *testing.T) {
+		s := &contentServer{}
+
+		fc, err := cf.ConfigForRef(ctx, newContentClient(t, s), "testorg", "testrepo", "develop")
+		require.NoError(t, err)
+
+		assert.True(t, fc.Missing(), "missing policy was found")
+		assert.Nil(t, fc.Default, "missing default policy was used")
+	})
+
+	t.Run("selfReference", func(t *testing.T) {
+		s := &contentServer{files: map[string]string{
+			"testorg/policies@/default.yml": testPolicy,
+		}}
+
+		fc, err := cf.ConfigForRef(ctx, newContentClient(t, s), "testorg", "policies", "develop")
+		require.NoError(t, err)
+
+		assert.True(t, fc.Missing(), "default policy was used for its own repository")
+		assert.NotContains(t, s.requests, "testorg/policies@/default.yml")
+	})
+}
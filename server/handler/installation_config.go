@@ -0,0 +1,122 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// mergeInstallationConfig merges a repository policy on top of the policy
+// settings defined for its installation and returns the merged policy.
+//
+// Maps are merged recursively. For scalars and lists, the repository value
+// replaces the installation value. The exception is "approval_rules", which
+// are combined by name: a repository rule replaces an installation rule with
+// the same name and all other rules from both are kept.
+func mergeInstallationConfig(installation interface{}, repoBytes []byte) ([]byte, error) {
+	var repo interface{}
+	if err := yaml.Unmarshal(repoBytes, &repo); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal policy")
+	}
+
+	merged, err := yaml.Marshal(mergeValues(installation, repo, ""))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal merged policy")
+	}
+	return merged, nil
+}
+
+func mergeValues(base, override interface{}, key string) interface{} {
+	if override == nil {
+		return base
+	}
+
+	switch o := override.(type) {
+	case map[interface{}]interface{}:
+		b, ok := base.(map[interface{}]interface{})
+		if !ok {
+			return override
+		}
+		merged := make(map[interface{}]interface{}, len(b)+len(o))
+		for k, v := range b {
+			merged[k] = v
+		}
+		for k, v := range o {
+			ks, _ := k.(string)
+			merged[k] = mergeValues(b[k], v, ks)
+		}
+		return merged
+
+	case []interface{}:
+		if b, ok := base.([]interface{}); ok && key == "approval_rules" {
+			return mergeRules(b, o)
+		}
+	}
+	return override
+}
+
+func mergeRules(base, override []interface{}) []interface{} {
+	overridden := make(map[interface{}]bool)
+	for _, r := range override {
+		if name := ruleName(r); name != nil {
+			overridden[name] = true
+		}
+	}
+
+	var merged []interface{}
+	for _, r := range base {
+		if name := ruleName(r); name == nil || !overridden[name] {
+			merged = append(merged, r)
+		}
+	}
+	return append(merged, override...)
+}
+
+func ruleName(rule interface{}) interface{} {
+	if m, ok := rule.(map[interface{}]interface{}); ok {
+		return m["name"]
+	}
+	return nil
+}
+
+// ruleSources returns the source of each approval rule in the policy created
+// by merging the repository policy on top of the installation settings. Rules
+// from the repository policy have the repository source, even if they replace
+// an installation rule.
+func ruleSources(installation interface{}, repoBytes []byte, repoSource, installationSource string) map[string]string {
+	sources := make(map[string]string)
+
+	if m, ok := installation.(map[interface{}]interface{}); ok {
+		if rules, ok := m["approval_rules"].([]interface{}); ok {
+			for _, r := range rules {
+				if name, ok := ruleName(r).(string); ok {
+					sources[name] = installationSource
+				}
+			}
+		}
+	}
+
+	var repo struct {
+		ApprovalRules []struct {
+			Name string `yaml:"name"`
+		} `yaml:"approval_rules"`
+	}
+	_ = yaml.Unmarshal(repoBytes, &repo)
+	for _, r := range repo.ApprovalRules {
+		sources[r.Name] = repoSource
+	}
+	return sources
+}
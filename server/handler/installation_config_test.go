@@ -0,0 +1,121 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestMergeInstallationConfig(t *testing.T) {
+	tests := map[string]struct {
+		Installation string
+		Repo         string
+		Expected     string
+	}{
+		"noInstallationSettings": {
+			Installation: ``,
+			Repo:         `policy: {approval: [a]}`,
+			Expected:     `policy: {approval: [a]}`,
+		},
+		"emptyRepoPolicy": {
+			Installation: `options: {invalidate_on_push: true}`,
+			Repo:         ``,
+			Expected:     `options: {invalidate_on_push: true}`,
+		},
+		"scalarsFromRepo": {
+			Installation: `options: {invalidate_on_push: true, request_review: {enabled: true}}`,
+			Repo:         `options: {invalidate_on_push: false}`,
+			Expected:     `options: {invalidate_on_push: false, request_review: {enabled: true}}`,
+		},
+		"mapsMergedRecursively": {
+			Installation: `options: {request_review: {enabled: true, mode: teams}}`,
+			Repo:         `options: {request_review: {mode: random-users}}`,
+			Expected:     `options: {request_review: {enabled: true, mode: random-users}}`,
+		},
+		"listsFromRepo": {
+			Installation: `policy: {approval: [a, b]}`,
+			Repo:         `policy: {approval: [c]}`,
+			Expected:     `policy: {approval: [c]}`,
+		},
+		"nullKeepsInstallation": {
+			Installation: `options: {invalidate_on_push: true}`,
+			Repo:         `options: {invalidate_on_push: null}`,
+			Expected:     `options: {invalidate_on_push: true}`,
+		},
+		"typeConflictFromRepo": {
+			Installation: `options: {request_review: {enabled: true}}`,
+			Repo:         `options: {request_review: false}`,
+			Expected:     `options: {request_review: false}`,
+		},
+		"rulesCombined": {
+			Installation: `approval_rules: [{name: security, requires: {count: 1}}]`,
+			Repo:         `approval_rules: [{name: review, requires: {count: 2}}]`,
+			Expected:     `approval_rules: [{name: security, requires: {count: 1}}, {name: review, requires: {count: 2}}]`,
+		},
+		"ruleReplacedByName": {
+			Installation: `approval_rules: [{name: security, requires: {count: 1}}, {name: legal}]`,
+			Repo:         `approval_rules: [{name: security, requires: {count: 3}}]`,
+			Expected:     `approval_rules: [{name: legal}, {name: security, requires: {count: 3}}]`,
+		},
+		"ruleNotMergedWithReplacement": {
+			Installation: `approval_rules: [{name: security, requires: {count: 1, teams: [org/security]}}]`,
+			Repo:         `approval_rules: [{name: security, requires: {count: 2}}]`,
+			Expected:     `approval_rules: [{name: security, requires: {count: 2}}]`,
+		},
+		"nestedListsNotCombined": {
+			Installation: `approval_rules: [{name: security, requires: {teams: [org/security]}}]`,
+			Repo:         `options: {approval_rules: [extra]}`,
+			Expected:     `{approval_rules: [{name: security, requires: {teams: [org/security]}}], options: {approval_rules: [extra]}}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var installation interface{}
+			require.NoError(t, yaml.Unmarshal([]byte(test.Installation), &installation))
+
+			merged, err := mergeInstallationConfig(installation, []byte(test.Repo))
+			require.NoError(t, err)
+
+			var actual, expected interface{}
+			require.NoError(t, yaml.Unmarshal(merged, &actual))
+			require.NoError(t, yaml.Unmarshal([]byte(test.Expected), &expected))
+			assert.Equal(t, expected, actual)
+		})
+	}
+
+	t.Run("invalidRepoPolicy", func(t *testing.T) {
+		_, err := mergeInstallationConfig(nil, []byte("policy: [}"))
+		assert.Error(t, err, "invalid policy did not return an error")
+	})
+}
+
+func TestRuleSources(t *testing.T) {
+	var installation interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(`approval_rules: [{name: security}, {name: legal}]`), &installation))
+
+	repo := []byte(`approval_rules: [{name: security}, {name: review}]`)
+
+	sources := ruleSources(installation, repo, "repo", "installation")
+	assert.Equal(t, map[string]string{
+		"security": "repo",
+		"legal":    "installation",
+		"review":   "repo",
+	}, sources)
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/google/go-github/github"
 	"github.com/palantir/go-githubapp/githubapp"
@@ -71,7 +72,8 @@ func (h *IssueComment) Handle(ctx context.Context, eventType, deliveryID string,
 	ctx, logger := h.PreparePRContext(ctx, installationID, pr)
 
 	mbrCtx := NewCrossOrgMembershipContext(ctx, client, owner, h.Installations, h.ClientCreator)
-	prctx, err := pull.NewGitHubContext(ctx, mbrCtx, client, v4client, pull.Locator{
+	extCtx := NewHTTPExternalContext(ctx, http.DefaultClient, h.PullOpts.ExternalChecks, h.ApproverLists)
+	prctx, err := pull.NewGitHubContext(ctx, mbrCtx, extCtx, client, v4client, pull.Locator{
 		Owner:  owner,
 		Repo:   repo.GetName(),
 		Number: number,
@@ -97,6 +99,10 @@ func (h *IssueComment) Handle(ctx context.Context, eventType, deliveryID string,
 		logger.Warn().Str(LogKeyAudit, "issue_comment").Msg("Skipped tampering check because the policy is not valid")
 	}
 
+	if h.ignoresEvent(ctx, fetchedConfig, eventType) {
+		return nil
+	}
+
 	return h.EvaluateFetchedConfig(ctx, prctx, client, fetchedConfig)
 }
 
@@ -121,7 +127,11 @@ func (h *IssueComment) detectAndLogTampering(ctx context.Context, prctx pull.Con
 		return false, nil
 	}
 
-	if h.affectsApproval(originalBody, config.ApprovalRules) {
+	affects, err := h.affectsApproval(ctx, prctx, commentAuthor, originalBody, config.ApprovalRules)
+	if err != nil {
+		return false, err
+	}
+	if affects {
 		msg := fmt.Sprintf("Entity %s edited approval comment by %s", eventAuthor, commentAuthor)
 		logger.Warn().Str(LogKeyAudit, "issue_comment").Msg(msg)
 
@@ -133,12 +143,16 @@ func (h *IssueComment) detectAndLogTampering(ctx context.Context, prctx pull.Con
 	return true, nil
 }
 
-func (h *IssueComment) affectsApproval(actualComment string, rules []*approval.Rule) bool {
+func (h *IssueComment) affectsApproval(ctx context.Context, prctx pull.Context, author, actualComment string, rules []*approval.Rule) (bool, error) {
 	for _, rule := range rules {
-		if rule.Options.GetMethods().CommentMatches(actualComment) {
-			return true
+		matches, err := rule.CommentMatches(ctx, prctx, author, actualComment)
+		if err != nil {
+			return false, err
+		}
+		if matches {
+			return true, nil
 		}
 	}
 
-	return false
+	return false, nil
 }
@@ -0,0 +1,169 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/palantir/policy-bot/policy"
+	"github.com/palantir/policy-bot/policy/approval"
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/pull"
+)
+
+// pendingCommentMarker identifies the comment created by the bot so that it
+// can be updated on future evaluations instead of creating a new comment
+const pendingCommentMarker = "<!-- policy-bot: pending approvals -->"
+
+// UpdatePendingComment creates or updates a single comment on the pull request
+// listing the rules that are pending and the users who may approve them. A new
+// comment is only created if rules are pending and an existing comment is only
+// edited if its content changes.
+func (b *Base) UpdatePendingComment(ctx context.Context, prctx pull.Context, client *github.Client, config *policy.Config, result *common.Result) error {
+	logger := zerolog.Ctx(ctx)
+
+	owner := prctx.RepositoryOwner()
+	repo := prctx.RepositoryName()
+
+	existing, err := b.findPendingComment(ctx, client, owner, repo, prctx.Number())
+	if err != nil {
+		return err
+	}
+
+	pending := findPendingRules(result, nil)
+	if len(pending) == 0 && existing == nil {
+		return nil
+	}
+
+	body := pendingCommentBody(pending, config.ApprovalRules)
+	if existing == nil {
+		logger.Debug().Msgf("Creating pending approval comment listing %d rules", len(pending))
+		_, _, err := client.Issues.CreateComment(ctx, owner, repo, prctx.Number(), &github.IssueComment{Body: &body})
+		return errors.Wrap(err, "failed to create pending approval comment")
+	}
+
+	if existing.GetBody() == body {
+		return nil
+	}
+
+	logger.Debug().Msgf("Updating pending approval comment listing %d rules", len(pending))
+	_, _, err = client.Issues.EditComment(ctx, owner, repo, existing.GetID(), &github.IssueComment{Body: &body})
+	return errors.Wrap(err, "failed to update pending approval comment")
+}
+
+func (b *Base) findPendingComment(ctx context.Context, client *github.Client, owner, repo string, number int) (*github.IssueComment, error) {
+	botName := b.PullOpts.AppName + "[bot]"
+
+	opt := &github.IssueListCommentsOptions{}
+	for {
+		comments, res, err := client.Issues.ListComments(ctx, owner, repo, number, opt)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list pull request comments")
+		}
+
+		for _, c := range comments {
+			if c.GetUser().GetLogin() == botName && strings.HasPrefix(c.GetBody(), pendingCommentMarker) {
+				return c, nil
+			}
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		opt.Page = res.NextPage
+	}
+	return nil, nil
+}
+
+// findPendingRules returns the pending results in the tree that correspond to
+// rules. Rules may have children for their components, so only results that
+// are waiting on other rules are searched.
+func findPendingRules(result *common.Result, pending []*common.Result) []*common.Result {
+	if result.Status != common.StatusPending {
+		return pending
+	}
+	if len(result.Children) == 0 || result.Reason != common.ReasonRulesPending {
+		return append(pending, result)
+	}
+	for _, c := range result.Children {
+		pending = findPendingRules(c, pending)
+	}
+	return pending
+}
+
+func pendingCommentBody(pending []*common.Result, rules []*approval.Rule) string {
+	var b strings.Builder
+	b.WriteString(pendingCommentMarker)
+	b.WriteString("\n")
+
+	if len(pending) == 0 {
+		b.WriteString("All approval rules are satisfied.\n")
+		return b.String()
+	}
+
+	rulesByName := make(map[string]*approval.Rule)
+	for _, r := range rules {
+		rulesByName[r.Name] = r
+	}
+
+	b.WriteString("The following rules are waiting for approval:\n\n")
+	for _, res := range pending {
+		fmt.Fprintf(&b, "- **%s**: %s", res.Name, res.Description)
+		if res.RuleDescription != "" {
+			fmt.Fprintf(&b, "\n  %s", res.RuleDescription)
+		}
+		if r, ok := rulesByName[res.Name]; ok {
+			if approvers := describeActors(&r.Requires.Actors); approvers != "" {
+				fmt.Fprintf(&b, "\n  Approvers: %s", approvers)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func describeActors(a *common.Actors) string {
+	var parts []string
+	if len(a.Users) > 0 {
+		parts = append(parts, "users "+quoteAll(a.Users))
+	}
+	if len(a.Teams) > 0 {
+		parts = append(parts, "teams "+quoteAll(a.Teams))
+	}
+	if len(a.Organizations) > 0 {
+		parts = append(parts, "members of "+quoteAll(a.Organizations))
+	}
+	if a.Admins {
+		parts = append(parts, "repository admins")
+	}
+	if a.WriteCollaborators {
+		parts = append(parts, "users with write access")
+	}
+	return strings.Join(parts, "; ")
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "`" + v + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
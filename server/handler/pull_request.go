@@ -43,8 +43,8 @@ func (h *PullRequest) Handle(ctx context.Context, eventType, deliveryID string,
 	ctx, _ = h.PreparePRContext(ctx, installationID, event.GetPullRequest())
 
 	switch event.GetAction() {
-	case "opened", "reopened", "synchronize", "edited":
-		return h.Evaluate(ctx, installationID, pull.Locator{
+	case "opened", "reopened", "synchronize", "edited", "assigned", "unassigned", "labeled", "unlabeled", "review_requested", "review_request_removed":
+		return h.Evaluate(ctx, installationID, eventType, pull.Locator{
 			Owner:  event.GetRepo().GetOwner().GetLogin(),
 			Repo:   event.GetRepo().GetName(),
 			Number: event.GetPullRequest().GetNumber(),
@@ -0,0 +1,170 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexedwards/scs"
+	"github.com/pkg/errors"
+	"goji.io/pat"
+
+	"github.com/palantir/policy-bot/policy"
+	"github.com/palantir/policy-bot/pull"
+)
+
+const (
+	DefaultReevaluateInterval = 10 * time.Second
+)
+
+// Reevaluate evaluates a pull request on request and updates its status. It
+// requires a logged in user who can read the repository. Requests for the
+// same pull request are limited to one per Interval.
+type Reevaluate struct {
+	Base
+	Sessions *scs.Manager
+	Interval time.Duration
+
+	throttle throttle
+}
+
+type reevaluateResponse struct {
+	Policy string      `json:"policy"`
+	Error  string      `json:"error,omitempty"`
+	Result *resultJSON `json:"result,omitempty"`
+}
+
+func (h *Reevaluate) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	owner := pat.Param(r, "owner")
+	repo := pat.Param(r, "repo")
+
+	number, err := strconv.Atoi(pat.Param(r, "number"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid pull request number: %v", err), http.StatusBadRequest)
+		return nil
+	}
+
+	user, err := sessionUser(w, r, h.Sessions)
+	if err != nil {
+		return err
+	}
+	if user == "" {
+		return nil
+	}
+
+	// session cookies are sent with cross-site requests, so reject requests
+	// that browsers identify as coming from another origin
+	if !h.isSameOrigin(r) {
+		http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+		return nil
+	}
+
+	installation, err := h.Installations.GetByOwner(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	client, err := h.ClientCreator.NewInstallationClient(installation.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to create github client")
+	}
+
+	v4client, err := h.ClientCreator.NewInstallationV4Client(installation.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to create github client")
+	}
+
+	readable, err := canRead(ctx, client, owner, repo, user)
+	if err != nil {
+		return err
+	}
+
+	// if the user does not have permission, pretend the repo/PR doesn't exist
+	if !readable {
+		http.Error(w, fmt.Sprintf("not found: %s/%s#%d", owner, repo, number), http.StatusNotFound)
+		return nil
+	}
+
+	if wait := h.throttle.reserve(fmt.Sprintf("%s/%s#%d", owner, repo, number), reevaluateInterval(h.Interval)); wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds()+1)))
+		http.Error(w, "pull request was evaluated recently, try again later", http.StatusTooManyRequests)
+		return nil
+	}
+
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		if isNotFound(err) {
+			http.Error(w, fmt.Sprintf("not found: %s/%s#%d", owner, repo, number), http.StatusNotFound)
+			return nil
+		}
+		return errors.Wrap(err, "failed to get pull request")
+	}
+
+	ctx, logger := h.PreparePRContext(ctx, installation.ID, pr)
+	logger.Info().Msgf("Re-evaluating pull request at the request of %s", user)
+
+	mbrCtx := NewCrossOrgMembershipContext(ctx, client, owner, h.Installations, h.ClientCreator)
+	extCtx := NewHTTPExternalContext(ctx, http.DefaultClient, h.PullOpts.ExternalChecks, h.ApproverLists)
+	prctx, err := pull.NewGitHubContext(ctx, mbrCtx, extCtx, client, v4client, pull.Locator{
+		Owner:  owner,
+		Repo:   repo,
+		Number: number,
+		Value:  pr,
+	})
+	if err != nil {
+		return err
+	}
+
+	config, err := h.ConfigFetcher.ConfigForPR(ctx, prctx, client)
+	if err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("failed to fetch policy: %s", config))
+	}
+
+	res := reevaluateResponse{Policy: config.String()}
+	if !config.Valid() {
+		res.Error = config.Description()
+	}
+
+	result, err := h.EvaluateFetchedConfigResult(ctx, prctx, client, config)
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		res.Result = newResultJSON(result)
+	} else if res.Error == "" {
+		if _, err := policy.ParsePolicy(config.Config); err != nil {
+			res.Error = config.InvalidDescription(err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(res)
+}
+
+// reevaluateInterval returns the interval, or the default interval if it is
+// not positive.
+func reevaluateInterval(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return DefaultReevaluateInterval
+	}
+	return interval
+}
@@ -0,0 +1,248 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/alexedwards/scs"
+	"github.com/google/go-github/github"
+	"github.com/pkg/errors"
+	"goji.io/pat"
+
+	"github.com/palantir/policy-bot/policy"
+)
+
+// maxRepoPolicySources is the number of commits for which RepoPolicy
+// remembers the location of the policy before forgetting all of them
+const maxRepoPolicySources = 1000
+
+// RepoPolicy returns the effective policy for pull requests that target a
+// branch of a repository, after resolving remote and default policies and
+// merging installation settings. It requires a logged in user who can read
+// the repository and, for remote and default policies, the repository that
+// contains the policy.
+type RepoPolicy struct {
+	Base
+	Sessions *scs.Manager
+
+	mu      sync.Mutex
+	sources map[string]policyLocation
+}
+
+type repoPolicyResponse struct {
+	Policy  string           `json:"policy"`
+	Ref     string           `json:"ref"`
+	Path    string           `json:"path,omitempty"`
+	Source  string           `json:"source,omitempty"`
+	Hash    string           `json:"hash,omitempty"`
+	Content string           `json:"content,omitempty"`
+	Rules   []ruleSourceJSON `json:"rules,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+type ruleSourceJSON struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+func (h *RepoPolicy) ServeHTTP(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+
+	owner := pat.Param(r, "owner")
+	repo := pat.Param(r, "repo")
+
+	user, err := sessionUser(w, r, h.Sessions)
+	if err != nil {
+		return err
+	}
+	if user == "" {
+		return nil
+	}
+
+	installation, err := h.Installations.GetByOwner(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	client, err := h.ClientCreator.NewInstallationClient(installation.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to create github client")
+	}
+
+	readable, err := canRead(ctx, client, owner, repo, user)
+	if err != nil {
+		return err
+	}
+
+	// if the user does not have permission, pretend the repo doesn't exist
+	if !readable {
+		http.Error(w, fmt.Sprintf("not found: %s/%s", owner, repo), http.StatusNotFound)
+		return nil
+	}
+
+	ref := r.URL.Query().Get("ref")
+	if ref == "" {
+		repository, _, err := client.Repositories.Get(ctx, owner, repo)
+		if err != nil {
+			return errors.Wrap(err, "failed to get repository")
+		}
+		ref = repository.GetDefaultBranch()
+	}
+
+	head, err := commitSHA(ctx, client, owner, repo, ref)
+	if err != nil {
+		if cause := errors.Cause(err); isNotFound(cause) || isUnprocessable(cause) {
+			http.Error(w, fmt.Sprintf("not found: %s/%s@%s", owner, repo, ref), http.StatusNotFound)
+			return nil
+		}
+		return err
+	}
+	key := fmt.Sprintf("%s/%s@%s", owner, repo, head)
+
+	// the policy only changes when the commit of the ref or of the repository
+	// that contains the policy changes, so answer conditional requests
+	// without fetching the policy again
+	if etag := r.Header.Get("If-None-Match"); etag != "" {
+		if source, ok := h.source(key); ok {
+			if readable, err := h.canReadSource(ctx, client, owner, repo, source, user); err != nil || !readable {
+				return h.denySource(w, err)
+			}
+			sourceHead, err := commitSHA(ctx, client, source.Owner, source.Repo, source.Ref)
+			if err == nil && etag == policyETag(head, source, sourceHead) {
+				w.Header().Set("ETag", etag)
+				w.WriteHeader(http.StatusNotModified)
+				return nil
+			}
+		}
+	}
+
+	// fetch the commit instead of the ref so the policy matches the ETag
+	config, err := h.ConfigFetcher.ConfigForRef(ctx, client, owner, repo, head)
+	if err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("failed to fetch policy: %s", config))
+	}
+
+	res := repoPolicyResponse{
+		Policy: config.String(),
+		Ref:    ref,
+	}
+
+	if config.Content != nil {
+		source := config.location
+		if readable, err := h.canReadSource(ctx, client, owner, repo, source, user); err != nil || !readable {
+			return h.denySource(w, err)
+		}
+
+		sourceHead := head
+		if source.Owner != owner || source.Repo != repo || source.Ref != head {
+			if sourceHead, err = commitSHA(ctx, client, source.Owner, source.Repo, source.Ref); err != nil {
+				return err
+			}
+		}
+		h.setSource(key, source)
+		w.Header().Set("ETag", policyETag(head, source, sourceHead))
+
+		sum := sha256.Sum256(config.Content)
+		res.Hash = hex.EncodeToString(sum[:])
+		res.Path = config.Path
+		res.Source = config.Source
+		res.Content = string(config.Content)
+		for name, source := range config.RuleSources {
+			res.Rules = append(res.Rules, ruleSourceJSON{Name: name, Source: source})
+		}
+		sort.Slice(res.Rules, func(i, j int) bool { return res.Rules[i].Name < res.Rules[j].Name })
+	}
+
+	if !config.Valid() {
+		res.Error = config.Description()
+	} else if _, err := policy.ParsePolicy(config.Config); err != nil {
+		res.Error = config.InvalidDescription(err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	return json.NewEncoder(w).Encode(res)
+}
+
+// canReadSource returns true if the user can read the repository that
+// contains the policy of owner/repo. Remote and default policies are in
+// other repositories.
+func (h *RepoPolicy) canReadSource(ctx context.Context, client *github.Client, owner, repo string, source policyLocation, user string) (bool, error) {
+	if source.Owner == owner && source.Repo == repo {
+		return true, nil
+	}
+	return canRead(ctx, client, source.Owner, source.Repo, user)
+}
+
+// denySource responds to a request from a user who cannot read the repository
+// that contains the policy, without revealing its location.
+func (h *RepoPolicy) denySource(w http.ResponseWriter, err error) error {
+	if err != nil {
+		return err
+	}
+	http.Error(w, "read access to the repository that contains the policy is required", http.StatusForbidden)
+	return nil
+}
+
+func (h *RepoPolicy) source(key string) (policyLocation, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	source, ok := h.sources[key]
+	return source, ok
+}
+
+func (h *RepoPolicy) setSource(key string, source policyLocation) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.sources == nil || len(h.sources) >= maxRepoPolicySources {
+		h.sources = make(map[string]policyLocation)
+	}
+	h.sources[key] = source
+}
+
+// commitSHA returns the SHA of the commit of a ref. An empty ref is the
+// default branch of the repository.
+func commitSHA(ctx context.Context, client *github.Client, owner, repo, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	sha, _, err := client.Repositories.GetCommitSHA1(ctx, owner, repo, ref, "")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get commit of %s/%s@%s", owner, repo, ref)
+	}
+	return sha, nil
+}
+
+// policyETag returns an ETag that changes when the commit of the repository
+// or of the repository that contains the policy changes.
+func policyETag(head string, source policyLocation, sourceHead string) string {
+	sum := sha256.Sum256([]byte(head + "\n" + source.String() + "\n" + sourceHead))
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func isUnprocessable(err error) bool {
+	rerr, ok := err.(*github.ErrorResponse)
+	return ok && rerr.Response.StatusCode == http.StatusUnprocessableEntity
+}
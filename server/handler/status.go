@@ -51,15 +51,16 @@ func (h *Status) Handle(ctx context.Context, eventType, deliveryID string, paylo
 
 	ctx, logger := githubapp.PrepareRepoContext(ctx, installationID, repo)
 
-	// ignore contexts that are not ours
-	if !strings.HasPrefix(event.GetContext(), h.PullOpts.StatusCheckContext) {
-		logger.Debug().Msgf("Ignoring context event for '%s'", event.GetContext())
-		return nil
-	}
-
 	sender := event.GetSender()
 	commitSHA := event.GetCommit().GetSHA()
 
+	// other contexts can satisfy status requirements and predicates, so
+	// evaluate the pull requests for the commit
+	if !h.isOwnedContext(ownerName, event.GetContext()) {
+		logger.Debug().Msgf("Evaluating pull requests for context event for '%s'", event.GetContext())
+		return h.EvaluateCommit(ctx, installationID, eventType, repo, commitSHA)
+	}
+
 	if sender.GetLogin() != h.PullOpts.AppName+"[bot]" {
 		logger.Warn().
 			Str(LogKeyAudit, eventType).
@@ -91,3 +92,25 @@ func (h *Status) Handle(ctx context.Context, eventType, deliveryID string, paylo
 
 	return nil
 }
+
+// isOwnedContext returns true if the status context is the current or a
+// previous context used by the bot for repositories owned by owner.
+func (h *Status) isOwnedContext(owner, statusContext string) bool {
+	if isContextFor(statusContext, h.PullOpts.StatusCheckContextFor(owner)) {
+		return true
+	}
+	for _, previous := range h.PullOpts.PreviousStatusCheckContexts {
+		if isContextFor(statusContext, previous) {
+			return true
+		}
+	}
+	return false
+}
+
+// isContextFor returns true if the status context is the configured context
+// or the configured context with a branch, like "policy-bot: main". Other
+// contexts that start with the configured context, like "policy-bot-staging",
+// belong to different deployments.
+func isContextFor(statusContext, configured string) bool {
+	return statusContext == configured || strings.HasPrefix(statusContext, configured+": ")
+}
@@ -0,0 +1,44 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"github.com/palantir/policy-bot/policy"
+	"github.com/palantir/policy-bot/policy/common"
+	"github.com/palantir/policy-bot/pull"
+)
+
+// newStatusData returns the data for status templates that describe the
+// result, using description as the default message
+func newStatusData(prctx pull.Context, result *common.Result, description string) *policy.StatusData {
+	base, head := prctx.Branches()
+
+	data := &policy.StatusData{
+		Status:      result.Status.String(),
+		Description: description,
+		Reason:      string(result.Reason),
+		Owner:       prctx.RepositoryOwner(),
+		Repo:        prctx.RepositoryName(),
+		Number:      prctx.Number(),
+		Author:      prctx.Author(),
+		BaseBranch:  base,
+		HeadBranch:  head,
+		AuthorIsBot: pull.IsBot(prctx.Author()),
+	}
+	for _, r := range findPendingRules(result, nil) {
+		data.PendingRules = append(data.PendingRules, r.Name)
+	}
+	return data
+}
@@ -0,0 +1,46 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsOwnedContext(t *testing.T) {
+	h := &Status{
+		Base: Base{
+			PullOpts: &PullEvaluationOptions{
+				StatusCheckContext: "policy-bot",
+				InstallationStatusCheckContexts: map[string]string{
+					"stagingorg": "policy-bot-staging",
+				},
+				PreviousStatusCheckContexts: []string{"approval"},
+			},
+		},
+	}
+
+	assert.True(t, h.isOwnedContext("testorg", "policy-bot"))
+	assert.True(t, h.isOwnedContext("testorg", "policy-bot: master"))
+	assert.False(t, h.isOwnedContext("testorg", "policy-bot-staging: master"))
+
+	assert.True(t, h.isOwnedContext("stagingorg", "policy-bot-staging: master"))
+	assert.False(t, h.isOwnedContext("stagingorg", "policy-bot: master"))
+
+	assert.True(t, h.isOwnedContext("testorg", "approval: master"))
+	assert.False(t, h.isOwnedContext("testorg", "approval-legacy: master"))
+	assert.False(t, h.isOwnedContext("testorg", "ci/build"))
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"sync"
+	"time"
+)
+
+// throttle limits requests with the same key to one per interval.
+type throttle struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// reserve records a request identified by key and returns zero, or returns
+// how long to wait if the last request was too recent.
+func (t *throttle) reserve(key string, interval time.Duration) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := t.last[key]; ok && now.Sub(last) < interval {
+		return interval - now.Sub(last)
+	}
+
+	if t.last == nil {
+		t.last = make(map[string]time.Time)
+	}
+	for k, l := range t.last {
+		if now.Sub(l) >= interval {
+			delete(t.last, k)
+		}
+	}
+	t.last[key] = now
+	return 0
+}
@@ -111,10 +111,14 @@ func New(c *Config) (*Server, error) {
 		ClientCreator: cc,
 		BaseConfig:    &c.Server,
 		Installations: githubapp.NewInstallationsService(appClient),
+		ApproverLists: handler.NewApproverLists(http.DefaultClient, c.Options.ApproverLists),
 
 		PullOpts: &c.Options,
 		ConfigFetcher: &handler.ConfigFetcher{
-			PolicyPath: c.Options.PolicyPath,
+			PolicyPath:          c.Options.PolicyPath,
+			PolicyPaths:         c.Options.PolicyPaths,
+			DefaultPolicy:       c.Options.DefaultPolicy,
+			InstallationConfigs: c.Options.InstallationConfigs,
 		},
 	}
 
@@ -123,6 +127,7 @@ func New(c *Config) (*Server, error) {
 		&handler.PullRequestReview{Base: basePolicyHandler},
 		&handler.IssueComment{Base: basePolicyHandler},
 		&handler.Status{Base: basePolicyHandler},
+		&handler.Checks{Base: basePolicyHandler},
 	)
 
 	templates, err := handler.LoadTemplates(&c.Files)
@@ -143,6 +148,26 @@ func New(c *Config) (*Server, error) {
 
 	// additional API routes
 	mux.Handle(pat.Get("/api/health"), handler.Health())
+	mux.Handle(pat.Post("/api/pr/:owner/:repo/:number/reevaluate"), hatpear.Try(&handler.Reevaluate{
+		Base:     basePolicyHandler,
+		Sessions: sessions,
+	}))
+	mux.Handle(pat.Get("/api/pr/:owner/:repo/:number/evaluation"), hatpear.Try(&handler.Evaluation{
+		Base:     basePolicyHandler,
+		Sessions: sessions,
+	}))
+	mux.Handle(pat.Get("/api/repo/:owner/:repo/policy"), hatpear.Try(&handler.RepoPolicy{
+		Base:     basePolicyHandler,
+		Sessions: sessions,
+	}))
+	if len(c.Options.AdminUsers) > 0 {
+		adminCaches := hatpear.Try(&handler.AdminCaches{
+			Base:     basePolicyHandler,
+			Sessions: sessions,
+		})
+		mux.Handle(pat.Get("/api/admin/caches"), adminCaches)
+		mux.Handle(pat.Delete("/api/admin/caches/:cache"), adminCaches)
+	}
 	mux.Handle(pat.Get(oauth2.DefaultRoute), oauth2.NewHandler(
 		oauth2.GetConfig(c.Github, nil),
 		oauth2.ForceTLS(forceTLS),